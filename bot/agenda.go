@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Периоды повестки
+const (
+	agendaToday = "today"
+	agendaWeek  = "week"
+	agendaMonth = "month"
+	agendaNext  = "next"
+)
+
+// agendaPeriods порядок кнопок переключения периодов
+var agendaPeriods = []string{agendaToday, agendaWeek, agendaMonth, agendaNext}
+
+// agendaDays число дней, которое охватывает период; -1 — без ограничения
+var agendaDays = map[string]int{
+	agendaToday: 0,
+	agendaWeek:  7,
+	agendaMonth: 30,
+	agendaNext:  -1,
+}
+
+// occurrence ближайшее наступление ежегодного события
+type occurrence struct {
+	event    *models.Event
+	date     time.Time
+	daysLeft int
+}
+
+// occurrenceRule возвращает правило повторения события
+func occurrenceRule(event *models.Event) utils.OccurrenceRule {
+	return utils.OccurrenceRule{Recurrence: event.Recurrence, LeapPolicy: event.LeapPolicy}
+}
+
+// nextOccurrence возвращает ближайшее наступление события, начиная с дня from
+func nextOccurrence(event *models.Event, from time.Time) (time.Time, error) {
+	return utils.NextOccurrence(event.EventDate, occurrenceRule(event), from)
+}
+
+// isLeapDay проверяет, приходится ли дата события (YYYY-MM-DD) на 29 февраля
+func isLeapDay(eventDate string) bool {
+	return strings.HasSuffix(eventDate, "-02-29")
+}
+
+// leapPolicyOrDefault возвращает правило для 29 февраля, по умолчанию 28 февраля
+func leapPolicyOrDefault(leapPolicy string) string {
+	if leapPolicy == "" {
+		return models.LeapPolicyFeb28
+	}
+	return leapPolicy
+}
+
+// upcomingOccurrences рассчитывает ближайшие наступления событий, начиная
+// с дня from, и сортирует их по дате
+func upcomingOccurrences(events []*models.Event, from time.Time) []occurrence {
+	today := utils.StartOfDay(from)
+
+	occurrences := make([]occurrence, 0, len(events))
+	for _, event := range events {
+		next, err := nextOccurrence(event, from)
+		if err != nil {
+			log.Printf("Ошибка при расчете даты события %d: %v", event.ID, err)
+			continue
+		}
+		occurrences = append(occurrences, occurrence{
+			event:    event,
+			date:     next,
+			daysLeft: int(next.Sub(today).Hours() / 24),
+		})
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].date.Before(occurrences[j].date)
+	})
+	return occurrences
+}
+
+// buildAgenda возвращает наступления событий пользователя за days дней,
+// начиная с дня from включительно. При days < 0 возвращается только ближайшее.
+func (b *Bot) buildAgenda(userID int64, from time.Time, days int) ([]occurrence, error) {
+	events, err := b.DB.GetEventsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := upcomingOccurrences(events, from)
+	if days < 0 {
+		if len(occurrences) > 1 {
+			occurrences = occurrences[:1]
+		}
+		return occurrences, nil
+	}
+
+	for i, occ := range occurrences {
+		if occ.daysLeft > days {
+			return occurrences[:i], nil
+		}
+	}
+	return occurrences, nil
+}
+
+// formatDaysLeft возвращает «сегодня» или «через N дней»
+func formatDaysLeft(lang string, daysLeft int) string {
+	if daysLeft == 0 {
+		return i18n.T(lang, "agenda.when_today")
+	}
+	return i18n.T(lang, "agenda.when_in_days", i18n.N(lang, daysLeft, "days"))
+}
+
+// showAgenda показывает события пользователя за период. Если messageID не
+// равен нулю, сообщение с повесткой редактируется на месте.
+func (b *Bot) showAgenda(chatID int64, messageID int, telegramID int64, period string, lang string) {
+	days, ok := agendaDays[period]
+	if !ok {
+		period, days = agendaWeek, agendaDays[agendaWeek]
+	}
+
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.list_events"))
+		return
+	}
+
+	occurrences, err := b.buildAgenda(user.ID, time.Now(), days)
+	if err != nil {
+		log.Printf("Ошибка при составлении повестки: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.list_events"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(lang, "agenda."+period+"_title"))
+	if len(occurrences) == 0 {
+		text.WriteString("\n\n" + i18n.T(lang, "agenda.empty"))
+	}
+
+	typeLabels := b.typeLabels(user.ID, lang)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, occ := range occurrences {
+		typeText, ok := typeLabels[occ.event.TypeID]
+		if !ok {
+			typeText = i18n.EventType(lang, occ.event.Type)
+		}
+
+		text.WriteString("\n\n" + i18n.T(lang, "agenda.line",
+			i18n.FormatDate(lang, occ.date.Format("2006-01-02")),
+			titleWithMilestone(lang, occ.event, occ.date),
+			typeText,
+			formatDaysLeft(lang, occ.daysLeft)))
+
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(occ.event.Title, actEvent, occ.event.ID),
+		))
+	}
+
+	// Переключение периодов редактирует это же сообщение
+	var periods []tgbotapi.InlineKeyboardButton
+	for _, p := range agendaPeriods {
+		label := i18n.T(lang, "agenda."+p)
+		if p == period {
+			label = "• " + label
+		}
+		periods = append(periods, b.button(label, actAgenda, p, 1))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard,
+		periods,
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "menu.list"), actListEvents),
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
+		),
+	)
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text.String(), keyboard)
+		b.API.Send(edit)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
@@ -0,0 +1,242 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// attachmentFromMessage возвращает фото или файл из сообщения пользователя
+// или nil, если вложения в сообщении нет
+func attachmentFromMessage(message *tgbotapi.Message) *models.Attachment {
+	if len(message.Photo) > 0 {
+		// Telegram присылает фото в нескольких размерах, последний самый большой
+		photo := message.Photo[len(message.Photo)-1]
+		return &models.Attachment{Kind: models.AttachmentPhoto, FileID: photo.FileID}
+	}
+	if message.Document != nil {
+		return &models.Attachment{Kind: models.AttachmentDocument, FileID: message.Document.FileID}
+	}
+	return nil
+}
+
+// attachmentErrorText возвращает сообщение об ошибке действия с вложением
+func attachmentErrorText(lang string, err error) string {
+	switch {
+	case errors.Is(err, db.ErrAttachmentLimit):
+		return i18n.T(lang, "attachments.limit", db.MaxAttachments)
+	case errors.Is(err, db.ErrEventNotFound) || errors.Is(err, db.ErrAccessDenied):
+		return i18n.T(lang, "attachments.not_found")
+	}
+	return i18n.T(lang, "error.save_changes")
+}
+
+// sendAttachments отправляет вложения события: одно — отдельным фото или
+// файлом, несколько — группой. Фото и файлы Telegram не смешивает в одной
+// группе, поэтому они отправляются двумя группами.
+func (b *Bot) sendAttachments(chatID int64, attachments []*models.Attachment) {
+	var photos, documents []*models.Attachment
+	for _, attachment := range attachments {
+		if attachment.Kind == models.AttachmentPhoto {
+			photos = append(photos, attachment)
+		} else {
+			documents = append(documents, attachment)
+		}
+	}
+
+	for _, group := range [][]*models.Attachment{photos, documents} {
+		var err error
+		switch {
+		case len(group) == 0:
+			continue
+		case len(group) == 1 && group[0].Kind == models.AttachmentPhoto:
+			_, err = b.API.Send(tgbotapi.NewPhoto(chatID, tgbotapi.FileID(group[0].FileID)))
+		case len(group) == 1:
+			_, err = b.API.Send(tgbotapi.NewDocument(chatID, tgbotapi.FileID(group[0].FileID)))
+		default:
+			media := make([]interface{}, 0, len(group))
+			for _, attachment := range group {
+				if attachment.Kind == models.AttachmentPhoto {
+					media = append(media, tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(attachment.FileID)))
+				} else {
+					media = append(media, tgbotapi.NewInputMediaDocument(tgbotapi.FileID(attachment.FileID)))
+				}
+			}
+			_, err = b.API.SendMediaGroup(tgbotapi.NewMediaGroup(chatID, media))
+		}
+		if err != nil {
+			log.Printf("Ошибка при отправке вложений события %d: %v", group[0].EventID, err)
+		}
+	}
+}
+
+// sendEventAttachments отправляет вложения события вместе с напоминанием
+func (b *Bot) sendEventAttachments(chatID int64, event *models.Event) {
+	attachments, err := b.DB.GetEventAttachments(event.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении вложений события %d: %v", event.ID, err)
+		return
+	}
+	b.sendAttachments(chatID, attachments)
+}
+
+// showAttachments отправляет вложения события и кнопки для их изменения
+func (b *Bot) showAttachments(chatID, telegramID, eventID int64, lang string) {
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionView, lang)
+	if event == nil {
+		return
+	}
+
+	attachments, err := b.DB.GetAttachmentsForUser(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении вложений события %d: %v", event.ID, err)
+		b.sendText(chatID, attachmentErrorText(lang, err))
+		return
+	}
+	b.sendAttachments(chatID, attachments)
+
+	// Подписчик чужого события только просматривает вложения
+	subscribed, err := b.DB.IsEventSubscriber(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при проверке подписки на событие %d: %v", event.ID, err)
+	}
+
+	text := i18n.T(lang, "attachments.title", event.Title, len(attachments))
+	if len(attachments) == 0 {
+		text += i18n.T(lang, "attachments.empty")
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	if !subscribed {
+		// Кнопки удаления нумеруются в порядке отправки вложений
+		var row []tgbotapi.InlineKeyboardButton
+		for i, attachment := range attachments {
+			row = append(row, b.button(fmt.Sprintf("🗑 %d", i+1), actDeleteAttachment, attachment.ID))
+			if len(row) == 5 {
+				keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+		}
+		if len(attachments) < db.MaxAttachments {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "attachments.add"), actAttach, event.ID),
+			))
+		}
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.back"), actEvent, event.ID),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// handleAttachmentCallback обрабатывает кнопки вложений события
+func (b *Bot) handleAttachmentCallback(chatID, telegramID int64, cb *Callback, lang string) {
+	id, err := cb.Int64(0)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID: %v", err)
+		return
+	}
+
+	switch cb.Action {
+	case actAttachments:
+		// Кнопка «Готово» завершает прием вложений
+		if b.GetUserState(telegramID).State == models.StateAddAttachment {
+			b.ResetUserState(telegramID)
+		}
+		b.showAttachments(chatID, telegramID, id, lang)
+
+	case actAttach:
+		_, event := b.loadEvent(chatID, telegramID, id, db.PermissionEdit, lang)
+		if event == nil {
+			return
+		}
+		b.ResetUserState(telegramID)
+		b.SetUserState(telegramID, models.StateAddAttachment)
+		b.SaveUserData(telegramID, "event_id", event.ID)
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "attachments.prompt", event.Title))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "attachments.done"), actAttachments, event.ID),
+		))
+		b.API.Send(msg)
+
+	case actDeleteAttachment:
+		user, err := b.DB.GetUserByTelegramID(telegramID)
+		if err == nil && user == nil {
+			err = db.ErrAccessDenied
+		}
+		var attachment *models.Attachment
+		if err == nil {
+			attachment, err = b.DB.DeleteAttachmentForUser(id, user.ID)
+		}
+		if err != nil {
+			log.Printf("Ошибка при удалении вложения %d: %v", id, err)
+			b.sendText(chatID, attachmentErrorText(lang, err))
+			return
+		}
+
+		b.sendText(chatID, i18n.T(lang, "attachments.deleted"))
+		b.showAttachments(chatID, telegramID, attachment.EventID, lang)
+	}
+}
+
+// handleAttachmentInput прикрепляет присланные фото или файл к событию.
+// Пользователь может прислать несколько вложений подряд и нажать «Готово».
+func (b *Bot) handleAttachmentInput(message *tgbotapi.Message, userState *models.UserState, lang string) {
+	telegramID := accountID(message.Chat, message.From)
+	chatID := message.Chat.ID
+
+	eventID, _ := userState.CurrentData["event_id"].(int64)
+	doneKeyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "attachments.done"), actAttachments, eventID),
+	))
+
+	attachment := attachmentFromMessage(message)
+	if attachment == nil {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.attachment"))
+		msg.ReplyMarkup = doneKeyboard
+		b.API.Send(msg)
+		return
+	}
+
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionEdit, lang)
+	if event == nil {
+		b.ResetUserState(telegramID)
+		return
+	}
+	attachment.EventID = event.ID
+
+	if _, err := b.DB.CreateAttachmentForUser(attachment, user.ID); err != nil {
+		log.Printf("Ошибка при добавлении вложения к событию %d: %v", event.ID, err)
+		b.sendText(chatID, attachmentErrorText(lang, err))
+		b.ResetUserState(telegramID)
+		return
+	}
+
+	count, err := b.DB.CountEventAttachments(event.ID)
+	if err != nil {
+		log.Printf("Ошибка при подсчете вложений события %d: %v", event.ID, err)
+	}
+	if count >= db.MaxAttachments {
+		// Больше прикрепить нельзя, сразу показываем вложения
+		b.ResetUserState(telegramID)
+		b.sendText(chatID, i18n.T(lang, "attachments.limit", db.MaxAttachments))
+		b.showAttachments(chatID, telegramID, event.ID, lang)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "attachments.added", count, db.MaxAttachments))
+	msg.ReplyMarkup = doneKeyboard
+	b.API.Send(msg)
+}
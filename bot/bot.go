@@ -1,14 +1,20 @@
 package bot
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/awhatson15/reminder-bot/config"
 	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
 	"github.com/awhatson15/reminder-bot/models"
 	"github.com/awhatson15/reminder-bot/utils"
 )
@@ -17,24 +23,134 @@ import (
 type Bot struct {
 	API         *tgbotapi.BotAPI
 	DB          *db.DB
+	Config      *config.Config
+	Callbacks   *CallbackCodec
 	UserStates  map[int64]*models.UserState
 	statesMutex sync.RWMutex
 }
 
 // NewBot создает нового бота
-func NewBot(token string, database *db.DB) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(token)
+func NewBot(cfg *config.Config, database *db.DB) (*Bot, error) {
+	api, err := tgbotapi.NewBotAPI(cfg.BotToken)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при создании бота: %w", err)
 	}
 
+	// Без явного секрета выводим его из токена бота, чтобы кнопки переживали перезапуск
+	secret := []byte(cfg.CallbackSecret)
+	if len(secret) == 0 {
+		log.Println("ВНИМАНИЕ: CALLBACK_SECRET не задан, секрет для подписи кнопок получен из токена бота")
+		sum := sha256.Sum256([]byte("callback:" + cfg.BotToken))
+		secret = sum[:]
+	}
+	ttl := time.Duration(cfg.CallbackTTLHours) * time.Hour
+
 	return &Bot{
 		API:        api,
 		DB:         database,
+		Config:     cfg,
+		Callbacks:  NewCallbackCodec(secret, ttl, database),
 		UserStates: make(map[int64]*models.UserState),
 	}, nil
 }
 
+// button создает inline-кнопку с подписанными данными
+func (b *Bot) button(text, action string, args ...interface{}) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, b.Callbacks.Encode(action, args...))
+}
+
+// userLang определяет язык интерфейса для чата: в личном чате — язык
+// пользователя, в группе — язык группы. Если язык еще не сохранен, он
+// выбирается по настройкам Telegram отправителя.
+func (b *Bot) userLang(chat *tgbotapi.Chat, from *tgbotapi.User) string {
+	user, err := b.DB.GetUserByTelegramID(accountID(chat, from))
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+	}
+	if user != nil && user.Language != "" {
+		return i18n.Normalize(user.Language)
+	}
+	return i18n.Detect(from.LanguageCode)
+}
+
+// sendText отправляет простое текстовое сообщение
+func (b *Bot) sendText(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	b.API.Send(msg)
+}
+
+// inputErrorText переводит ошибку проверки ввода на язык пользователя
+func inputErrorText(lang string, err error) string {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return i18n.T(lang, "error."+validationErr.Key)
+	}
+	return err.Error()
+}
+
+// formatEventDetails форматирует основные поля события для карточки
+func (b *Bot) formatEventDetails(lang string, event *models.Event) string {
+	details := i18n.T(lang, "event.details",
+		b.typeLabel(lang, event.TypeID, event.Type),
+		i18n.FormatDate(lang, event.EventDate),
+		i18n.N(lang, event.NotifyDays, "days"))
+
+	if event.Description != "" {
+		details += i18n.T(lang, "event.description", event.Description)
+	}
+
+	if hasLocation(event) {
+		details += i18n.T(lang, "event.location", locationLabel(lang, event))
+	}
+	if event.MeetingTime != "" {
+		details += leaveByLabel(lang, event)
+	}
+
+	if event.GreetingID != 0 {
+		details += i18n.T(lang, "event.greeting")
+	}
+
+	if event.CalendarID != 0 {
+		if name, err := b.DB.GetCalendarName(event.CalendarID); err == nil {
+			details += i18n.T(lang, "event.calendar", name)
+		} else {
+			log.Printf("Ошибка при получении календаря %d: %v", event.CalendarID, err)
+		}
+	}
+
+	// Ближайшая дата с возрастом или номером годовщины
+	if next, err := nextOccurrence(event, time.Now()); err == nil {
+		if m, ok := eventMilestone(event, next); ok {
+			details += i18n.T(lang, "event.milestone", i18n.FormatDate(lang, next.Format("2006-01-02")), m.text(lang))
+		}
+	}
+
+	switch event.Recurrence {
+	case models.RecurrenceMonthly:
+		details += i18n.T(lang, "event.monthly")
+	case models.RecurrenceOnce:
+		details += i18n.T(lang, "event.once")
+	}
+	if isLeapDay(event.EventDate) && event.Recurrence != models.RecurrenceMonthly && event.Recurrence != models.RecurrenceOnce {
+		details += i18n.T(lang, "event.leap_policy", i18n.T(lang, "leap_policy."+leapPolicyOrDefault(event.LeapPolicy)))
+	}
+
+	if event.Priority == models.PriorityHigh {
+		details += i18n.T(lang, "event.priority_high")
+		if event.NagHours > 0 {
+			details += i18n.T(lang, "event.nag", i18n.N(lang, event.NagHours, "hours"))
+		}
+	}
+
+	tags, err := b.DB.GetEventTags(event.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении тегов события %d: %v", event.ID, err)
+	}
+	if len(tags) > 0 {
+		details += i18n.T(lang, "event.tags", formatTags(tags))
+	}
+	return details
+}
 
 // Start запускает бота
 func (b *Bot) Start() {
@@ -50,6 +166,10 @@ func (b *Bot) Start() {
 			go b.handleMessage(update.Message)
 		} else if update.CallbackQuery != nil {
 			go b.handleCallbackQuery(update.CallbackQuery)
+		} else if update.InlineQuery != nil {
+			go b.handleInlineQuery(update.InlineQuery)
+		} else if update.ChosenInlineResult != nil {
+			go b.handleChosenInlineResult(update.ChosenInlineResult)
 		}
 	}
 }
@@ -118,19 +238,23 @@ func (b *Bot) SaveUserData(userID int64, key string, value interface{}) {
 }
 
 // SendMainMenu отправляет основное меню
-func (b *Bot) SendMainMenu(chatID int64) error {
+func (b *Bot) SendMainMenu(chatID int64, lang string) error {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Добавить событие", "add_event"),
-			tgbotapi.NewInlineKeyboardButtonData("Мои события", "list_events"),
+			b.button(i18n.T(lang, "menu.add"), actAddEvent),
+			b.button(i18n.T(lang, "menu.list"), actListEvents),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Настройки", "settings"),
-			tgbotapi.NewInlineKeyboardButtonData("Помощь", "help"),
+			b.button(i18n.T(lang, "menu.today"), actAgenda, agendaToday),
+			b.button(i18n.T(lang, "menu.agenda"), actAgenda, agendaWeek),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "menu.settings"), actSettings),
+			b.button(i18n.T(lang, "menu.help"), actHelp),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(chatID, "Что вы хотите сделать?")
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "menu.prompt"))
 	msg.ReplyMarkup = keyboard
 
 	_, err := b.API.Send(msg)
@@ -144,77 +268,153 @@ func (b *Bot) SendMainMenu(chatID int64) error {
 // SendNotification отправляет уведомление о предстоящем событии
 func (b *Bot) SendNotification(user *models.User, event *models.Event, daysLeft int) error {
 	var messageText string
-	
+	lang := i18n.Normalize(user.Language)
+
+	// Возраст или номер годовщины в день наступления события
+	occurrence := utils.StartOfDay(time.Now()).AddDate(0, 0, daysLeft)
+	title := titleWithMilestone(lang, event, occurrence)
+
+	var greeting string
 	if daysLeft == 0 {
 		// Событие сегодня
-		messageText = fmt.Sprintf("🎉 Сегодня: %s (%s)\n%s", 
-			event.Title, event.Type, event.Description)
+		messageText = i18n.T(lang, "notify.today",
+			title, b.typeLabel(lang, event.TypeID, event.Type), event.Description)
+
+		// Готовое поздравление придет следующим сообщением, чтобы его можно было переслать
+		greeting = b.eventGreeting(user, event, occurrence)
+		if greeting != "" {
+			messageText += i18n.T(lang, "notify.greeting_hint")
+		}
 	} else {
 		// Уведомление за N дней
-		messageText = fmt.Sprintf("🔔 Через %d дней: %s (%s)\n%s", 
-			daysLeft, event.Title, event.Type, event.Description)
+		messageText = i18n.T(lang, "notify.in_days",
+			i18n.N(lang, daysLeft, "days"), title, b.typeLabel(lang, event.TypeID, event.Type), event.Description)
+
+		// Напоминаем о еще не купленных подарках, пока есть время
+		messageText += b.giftIdeasText(event, lang)
 	}
 	
 	msg := tgbotapi.NewMessage(user.TelegramID, messageText)
+	msg.ReplyMarkup = b.reminderKeyboard(lang, event.ID, occurrenceDate(time.Now(), daysLeft))
+	if event.Priority == models.PriorityHigh {
+		msg.Text = "⚡ " + msg.Text
+	}
 	_, err := b.API.Send(msg)
 	
 	if err != nil {
 		return fmt.Errorf("ошибка при отправке уведомления: %w", err)
 	}
+
+	if greeting != "" {
+		if _, err := b.API.Send(tgbotapi.NewMessage(user.TelegramID, greeting)); err != nil {
+			log.Printf("Ошибка при отправке поздравления для события %d: %v", event.ID, err)
+		}
+	}
+
+	// Место встречи приходит точкой на карте
+	if hasLocation(event) {
+		b.sendEventLocation(user.TelegramID, event)
+	}
+
+	// В день события вместе с напоминанием приходят фото и файлы события
+	if daysLeft == 0 {
+		b.sendEventAttachments(user.TelegramID, event)
+	}
+
+	if err := b.DB.LogNotification(user.ID, event, daysLeft); err != nil {
+		log.Printf("Ошибка при сохранении истории уведомлений: %v", err)
+	}
+
+	b.startNagging(user, event, occurrenceDate(time.Now(), daysLeft), time.Now())
 	
 	return nil
 }
 
-// CheckAndSendNotifications проверяет и отправляет уведомления
-func (b *Bot) CheckAndSendNotifications() error {
-	// Получаем предстоящие события
-	events, err := b.DB.GetUpcomingEvents()
+// CheckAndSendNotifications проверяет и отправляет уведомления, запланированные
+// на время now. Время уведомления берется из типа события или из настроек пользователя.
+func (b *Bot) CheckAndSendNotifications(now time.Time) error {
+	currentTime := now.Format("15:04")
+	recipients, err := b.DB.GetEventsForNotification(currentTime)
 	if err != nil {
-		return fmt.Errorf("ошибка при получении предстоящих событий: %w", err)
+		return fmt.Errorf("ошибка при получении событий для уведомлений: %w", err)
 	}
-	
-	for _, event := range events {
-		// Получаем пользователя
-		user, err := b.DB.GetUserByTelegramID(event.UserID)
-		if err != nil {
-			log.Printf("Ошибка при получении пользователя %d: %v", event.UserID, err)
-			continue
+
+	users := make(map[int64]*models.User)
+	for _, recipient := range recipients {
+		event := recipient.Event
+
+		// Получаем пользователя, которому нужно напомнить о событии
+		user, ok := users[recipient.UserID]
+		if !ok {
+			user, err = b.DB.GetUserByID(recipient.UserID)
+			if err != nil {
+				log.Printf("Ошибка при получении пользователя %d: %v", recipient.UserID, err)
+				continue
+			}
+			users[recipient.UserID] = user
 		}
-		
+
 		if user == nil {
 			continue
 		}
-		
+
 		// Определяем, сколько дней осталось до события
-		daysLeft, err := utils.DaysUntilEvent(event.EventDate)
+		daysLeft, err := utils.DaysUntilEvent(event.EventDate, occurrenceRule(event))
 		if err != nil {
 			log.Printf("Ошибка при расчете дней до события %d: %v", event.ID, err)
 			continue
 		}
-		
+
+		// Задачи подготовки напоминаются за свой срок до события
+		b.sendTaskReminders(user, event, daysLeft, now)
+
 		// Отправляем уведомление, если осталось столько дней, сколько указано в настройках
-		// или если событие сегодня
-		if daysLeft == event.NotifyDays || daysLeft == 0 {
+		// или если событие сегодня, и пользователь еще не отметил это наступление
+		if (daysLeft == recipient.NotifyDays || daysLeft == 0) && !b.isAcknowledged(user.ID, event.ID, occurrenceDate(now, daysLeft)) {
+			// В тихие часы и во время паузы обычное напоминание откладывается
+			if until, ok := deferUntil(user, now); ok && !isUrgent(event) {
+				if err := b.DB.SnoozeReminder(user.ID, event.ID, occurrenceDate(now, daysLeft), until); err != nil {
+					log.Printf("Ошибка при переносе уведомления для события %d: %v", event.ID, err)
+				}
+				continue
+			}
+
 			err = b.SendNotification(user, event, daysLeft)
 			if err != nil {
 				log.Printf("Ошибка при отправке уведомления для события %d: %v", event.ID, err)
 			}
 		}
 	}
-	
-	return nil
+
+	// Отложенные напоминания отправляются в любое время, а не только во время уведомлений
+	b.sendSnoozedReminders(now)
+	b.sendSnoozedTaskReminders(now)
+	b.sendNags(now)
+	b.sendLeaveByReminders(now)
+
+	// Пользователи с включенной сводкой получают одно сообщение на все события
+	return b.sendDigests(now)
 }
 
 // HandleMessage обрабатывает текстовые сообщения
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
+	// В группе действия выполняются от имени группы и только администраторами
+	if isGroupChat(message.Chat) {
+		if !b.allowGroupMessage(message) {
+			return
+		}
+		defer b.rememberGroupActor(message.Chat.ID, message.From.ID, b.GetUserState(message.Chat.ID).State)
+	}
+
 	if message.IsCommand() {
 		b.handleCommand(message)
 		return
 	}
 
-	userID := message.From.ID
+	userID := accountID(message.Chat, message.From)
 	chatID := message.Chat.ID
 	userState := b.GetUserState(userID)
+	lang := b.userLang(message.Chat, message.From)
 
 	switch userState.State {
 	case models.StateAddEventTitle:
@@ -222,17 +422,17 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 		b.SaveUserData(userID, "title", message.Text)
 		b.SetUserState(userID, models.StateAddEventType)
 
-		// Показываем варианты типов событий
-		keyboard := tgbotapi.NewInlineKeyboardMarkup()
-		for _, eventType := range models.EventTypes {
-			row := tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData(eventType, "type:"+eventType),
-			)
-			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+		user, err := b.DB.GetUserByTelegramID(userID)
+		if err != nil || user == nil {
+			log.Printf("Ошибка при получении пользователя: %v", err)
+			b.sendText(chatID, i18n.T(lang, "error.create_event"))
+			b.ResetUserState(userID)
+			return
 		}
 
-		msg := tgbotapi.NewMessage(chatID, "Выберите тип события:")
-		msg.ReplyMarkup = keyboard
+		// Показываем типы событий пользователя
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "add.type_prompt"))
+		msg.ReplyMarkup = b.eventTypeKeyboard(lang, user.ID, actType)
 		b.API.Send(msg)
 
 	case models.StateAddEventDate:
@@ -240,23 +440,21 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 		dateStr := message.Text
 		formattedDate, err := utils.FormatDate(dateStr)
 		if err != nil {
-			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ %s. Пожалуйста, введите дату в формате ДД.ММ.ГГГГ:", err))
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.retry_date", inputErrorText(lang, err)))
 			b.API.Send(msg)
 			return
 		}
 
 		b.SaveUserData(userID, "event_date", formattedDate)
 		b.SetUserState(userID, models.StateAddEventNotify)
-
-		msg := tgbotapi.NewMessage(chatID, "За сколько дней до события отправить напоминание? (введите число от 1 до 30):")
-		b.API.Send(msg)
+		b.sendNotifyPrompt(chatID, userState, lang)
 
 	case models.StateAddEventNotify:
 		// Обработка ввода дней для напоминания
 		daysStr := message.Text
 		days, err := strconv.Atoi(daysStr)
 		if err != nil || days < 1 || days > 30 {
-			msg := tgbotapi.NewMessage(chatID, "❌ Пожалуйста, введите число от 1 до 30:")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.notify_days"))
 			b.API.Send(msg)
 			return
 		}
@@ -264,61 +462,82 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 		b.SaveUserData(userID, "notify_days", days)
 		b.SetUserState(userID, models.StateAddEventDesc)
 
-		msg := tgbotapi.NewMessage(chatID, "Введите описание события (или отправьте /skip, чтобы пропустить):")
+		eventType, _ := userState.CurrentData["type"].(string)
+		msg := tgbotapi.NewMessage(chatID, descPrompt(lang, eventType))
 		b.API.Send(msg)
 
 	case models.StateAddEventDesc:
-		// Обработка ввода описания события
+		// Обработка ввода описания события. Вместо текста можно прислать
+		// фото или файл, тогда описанием станет подпись к нему, или место встречи
 		description := message.Text
 		if message.Text == "/skip" {
 			description = ""
 		}
+		attachment := attachmentFromMessage(message)
+		if attachment != nil {
+			description = message.Caption
+		}
+		location := locationFromMessage(message)
 
 		b.SaveUserData(userID, "description", description)
 
 		// Создаем событие в БД
 		userData := userState.CurrentData
 		user, err := b.DB.GetUserByTelegramID(userID)
-		if err != nil {
+		if err != nil || user == nil {
 			log.Printf("Ошибка при получении пользователя: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при создании события.")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.create_event"))
 			b.API.Send(msg)
 			b.ResetUserState(userID)
 			return
 		}
 
+		// Событие, добавляемое из общего календаря, сразу попадает в него
+		calendarID, _ := userData["calendar_id"].(int64)
+
 		event := &models.Event{
 			UserID:      user.ID,
+			CalendarID:  calendarID,
 			Title:       userData["title"].(string),
 			Type:        userData["type"].(string),
+			TypeID:      userData["type_id"].(int64),
 			EventDate:   userData["event_date"].(string),
 			NotifyDays:  userData["notify_days"].(int),
 			Description: description,
 		}
 
 		var eventID int64
-		eventID, err = b.DB.CreateEvent(event)
+		eventID, err = b.DB.CreateEventForUser(event)
 		if err != nil {
 			log.Printf("Ошибка при создании события: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при сохранении события.")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.save_event"))
 			b.API.Send(msg)
 			b.ResetUserState(userID)
 			return
 		}
-		_ = eventID // Используем переменную, чтобы избежать ошибки "unused variable"
 
-		// Форматируем дату для отображения
-		displayDate := utils.FormatDisplayDate(event.EventDate)
+		if location != nil {
+			err := b.DB.SetEventLocationForUser(eventID, user.ID, location.Latitude, location.Longitude, location.Venue, location.Address)
+			if err != nil {
+				log.Printf("Ошибка при сохранении места встречи события %d: %v", eventID, err)
+				b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+			} else {
+				event.Latitude, event.Longitude = location.Latitude, location.Longitude
+				event.Venue, event.VenueAddress = location.Venue, location.Address
+			}
+		}
 
-		successMsg := fmt.Sprintf("✅ Событие успешно добавлено!\n\n"+
-			"🔤 Название: %s\n"+
-			"🏷 Тип: %s\n"+
-			"📅 Дата: %s\n"+
-			"🔔 Напоминание: за %d дней\n",
-			event.Title, event.Type, displayDate, event.NotifyDays)
+		if attachment != nil {
+			attachment.EventID = eventID
+			if _, err := b.DB.CreateAttachmentForUser(attachment, user.ID); err != nil {
+				log.Printf("Ошибка при добавлении вложения к событию %d: %v", eventID, err)
+				b.sendText(chatID, attachmentErrorText(lang, err))
+			}
+		}
 
-		if event.Description != "" {
-			successMsg += fmt.Sprintf("📝 Описание: %s\n", event.Description)
+		successMsg := i18n.T(lang, "add.success", event.Title) + b.formatEventDetails(lang, event)
+		if location != nil {
+			successMsg += i18n.T(lang, "add.leave_by_hint")
 		}
 
 		msg := tgbotapi.NewMessage(chatID, successMsg)
@@ -326,22 +545,46 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 
 		// Возвращаем пользователя в главное меню
 		b.ResetUserState(userID)
-		b.SendMainMenu(chatID)
+		b.SendMainMenu(chatID, lang)
+
+	case models.StateNewGreeting:
+		// Обработка ввода текста шаблона поздравления
+		b.handleNewGreetingInput(chatID, userID, message.Text, lang)
+
+	case models.StateAddAttachment:
+		// Прием фото и файлов для события
+		b.handleAttachmentInput(message, userState, lang)
+
+	case models.StateSubscriptionNotify:
+		// Обработка ввода срока напоминаний подписчика
+		b.handleSubscriptionNotifyInput(chatID, userID, userState, message.Text, lang)
+
+	case models.StateNewTask:
+		// Обработка ввода задачи подготовки к событию
+		b.handleNewTaskInput(chatID, userID, userState, message.Text, lang)
+
+	case models.StateNewGift:
+		// Обработка ввода идеи подарка
+		b.handleNewGiftInput(chatID, userID, userState, message.Text, lang)
+
+	case models.StateSetQuietHours:
+		// Обработка ввода тихих часов
+		b.handleQuietHoursInput(chatID, userID, message.Text, lang)
 
 	case models.StateSetNotifyTime:
 		// Обработка ввода времени для уведомлений
 		timeStr := message.Text
 		formattedTime, err := utils.ValidateTime(timeStr)
 		if err != nil {
-			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ %s. Пожалуйста, введите время в формате ЧЧ:ММ:", err))
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.retry_time", inputErrorText(lang, err)))
 			b.API.Send(msg)
 			return
 		}
 
 		user, err := b.DB.GetUserByTelegramID(userID)
-		if err != nil {
+		if err != nil || user == nil {
 			log.Printf("Ошибка при получении пользователя: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при обновлении настроек.")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.update_settings"))
 			b.API.Send(msg)
 			b.ResetUserState(userID)
 			return
@@ -350,30 +593,56 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 		err = b.DB.SetUserNotificationTime(user.ID, formattedTime)
 		if err != nil {
 			log.Printf("Ошибка при обновлении времени уведомлений: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при сохранении настроек.")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.save_settings"))
 			b.API.Send(msg)
 			b.ResetUserState(userID)
 			return
 		}
 
-		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ Время уведомлений установлено на %s", formattedTime))
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "settings.time_set", formattedTime))
 		b.API.Send(msg)
 
 		// Возвращаем пользователя в главное меню
 		b.ResetUserState(userID)
-		b.SendMainMenu(chatID)
+		b.SendMainMenu(chatID, lang)
 
 	case models.StateEditEventValue:
 		// Обработка ввода нового значения для редактирования поля события
 		field := userState.CurrentData["field"].(string)
 		eventID := userState.CurrentData["event_id"].(int64)
 
-		event, err := b.DB.GetEventByID(eventID)
-		if err != nil {
-			log.Printf("Ошибка при получении события: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при редактировании события.")
-			b.API.Send(msg)
+		user, event := b.loadEvent(chatID, userID, eventID, db.PermissionEdit, lang)
+		if event == nil {
+			b.ResetUserState(userID)
+			return
+		}
+
+		// Место встречи и напоминание о выезде тоже сохраняются отдельно
+		switch field {
+		case "location":
+			b.handleLocationInput(chatID, userID, user, event, message, lang)
+			return
+		case "leave_by":
+			b.handleLeaveByInput(chatID, userID, user, event, message.Text, lang)
+			return
+		}
+
+		if field == "tags" {
+			// Теги не входят в историю изменений и сохраняются отдельно
+			var tags []string
+			if message.Text != resetValue {
+				tags = db.SplitTags(message.Text)
+			}
+
 			b.ResetUserState(userID)
+			if err := b.DB.SetEventTagsForUser(event.ID, user.ID, tags); err != nil {
+				log.Printf("Ошибка при сохранении тегов: %v", err)
+				b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+				return
+			}
+
+			b.sendText(chatID, i18n.T(lang, "edit.tags_saved"))
+			b.showEvent(chatID, userID, event.ID, lang)
 			return
 		}
 
@@ -384,16 +653,23 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 		case "date":
 			formattedDate, err := utils.FormatDate(message.Text)
 			if err != nil {
-				msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ %s. Пожалуйста, введите дату в формате ДД.ММ.ГГГГ:", err))
+				msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.retry_date", inputErrorText(lang, err)))
 				b.API.Send(msg)
 				return
 			}
+			// Дата разового события без года — ближайшая такая дата
+			if event.Recurrence == models.RecurrenceOnce {
+				if formattedDate, err = utils.OnceDate(formattedDate, time.Now()); err != nil {
+					b.sendText(chatID, i18n.T(lang, "error.retry_date", inputErrorText(lang, err)))
+					return
+				}
+			}
 			event.EventDate = formattedDate
 
 		case "notify_days":
 			days, err := strconv.Atoi(message.Text)
 			if err != nil || days < 1 || days > 30 {
-				msg := tgbotapi.NewMessage(chatID, "❌ Пожалуйста, введите число от 1 до 30:")
+				msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.notify_days"))
 				b.API.Send(msg)
 				return
 			}
@@ -401,167 +677,229 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 
 		case "description":
 			event.Description = message.Text
+
+		case "nag_hours":
+			hours, err := strconv.Atoi(message.Text)
+			if err != nil || hours < 0 || hours > 24 {
+				b.sendText(chatID, i18n.T(lang, "error.nag_hours"))
+				return
+			}
+			// Повторы имеют смысл только для важных событий
+			event.NagHours = hours
+			if hours > 0 {
+				event.Priority = models.PriorityHigh
+			}
 		}
 
-		err = b.DB.UpdateEvent(event)
+		revisionID, err := b.DB.UpdateEventForUser(event, user.ID)
 		if err != nil {
 			log.Printf("Ошибка при обновлении события: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при сохранении изменений.")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.save_changes"))
 			b.API.Send(msg)
 			b.ResetUserState(userID)
 			return
 		}
 
-		// Форматируем дату для отображения
-		displayDate := utils.FormatDisplayDate(event.EventDate)
-
-		successMsg := fmt.Sprintf("✅ Событие успешно обновлено!\n\n"+
-			"🔤 Название: %s\n"+
-			"🏷 Тип: %s\n"+
-			"📅 Дата: %s\n"+
-			"🔔 Напоминание: за %d дней\n",
-			event.Title, event.Type, displayDate, event.NotifyDays)
+		b.ResetUserState(userID)
+		b.sendEventUpdated(chatID, event, revisionID, lang)
 
-		if event.Description != "" {
-			successMsg += fmt.Sprintf("📝 Описание: %s\n", event.Description)
-		}
+	case models.StateSearch:
+		// Поиск по введенному запросу
+		b.ResetUserState(userID)
+		b.sendSearchResults(chatID, 0, userID, message.Text, 0, lang)
 
-		msg := tgbotapi.NewMessage(chatID, successMsg)
-		b.API.Send(msg)
+	case models.StateNewEventType, models.StateEditEventType:
+		// Создание и изменение типов событий
+		b.handleEventTypeInput(message, userState, lang)
 
-		// Возвращаем пользователя в главное меню
-		b.ResetUserState(userID)
-		b.SendMainMenu(chatID)
+	case models.StateNewCalendar:
+		// Создание общего календаря
+		b.handleNewCalendarInput(chatID, userID, message.Text, lang)
 
 	default:
 		// В других состояниях отправляем главное меню
 		b.ResetUserState(userID)
-		b.SendMainMenu(chatID)
+		b.SendMainMenu(chatID, lang)
 	}
 }
 
 // HandleCallbackQuery обрабатывает нажатия на inline-кнопки
 func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
-	userID := callback.From.ID
+	userID := accountID(callback.Message.Chat, callback.From)
 	chatID := callback.Message.Chat.ID
-	data := callback.Data
 	userState := b.GetUserState(userID)
+	lang := b.userLang(callback.Message.Chat, callback.From)
+
+	cb, err := b.Callbacks.Decode(callback.Data)
+	if err != nil {
+		// Подделанные и устаревшие кнопки не выполняем, а просим открыть меню заново
+		if !errors.Is(err, ErrCallbackStale) {
+			log.Printf("Отклонены данные кнопки от пользователя %d: %v", userID, err)
+		}
+		alert := tgbotapi.NewCallbackWithAlert(callback.ID, i18n.T(lang, "error.stale_button"))
+		b.API.Request(alert)
+		b.SendMainMenu(chatID, lang)
+		return
+	}
+
+	// В группе кнопки, которые что-то меняют, доступны только администраторам
+	if isGroupChat(callback.Message.Chat) {
+		if !groupReadOnlyActions[cb.Action] && !b.isChatAdmin(chatID, callback.From.ID) {
+			alert := tgbotapi.NewCallbackWithAlert(callback.ID, i18n.T(lang, "group.admin_only"))
+			b.API.Request(alert)
+			return
+		}
+		defer b.rememberGroupActor(chatID, callback.From.ID, userState.State)
+	}
 
 	// Отправляем уведомление о получении запроса
 	b.API.Request(tgbotapi.NewCallback(callback.ID, ""))
 
-	switch {
-	case data == "add_event":
+	switch cb.Action {
+	case actAddEvent:
 		// Начинаем процесс добавления события
+		b.ResetUserState(userID)
 		b.SetUserState(userID, models.StateAddEventTitle)
-		msg := tgbotapi.NewMessage(chatID, "Введите название события:")
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "add.title_prompt"))
 		b.API.Send(msg)
 
-	case data == "list_events":
+	case actListEvents:
 		// Отправляем список событий пользователя
-		b.sendEventsList(chatID, userID)
+		b.sendEventsList(chatID, userID, lang)
 
-	case data == "settings":
+	case actSettings:
 		// Показываем меню настроек
-		b.showSettings(chatID, userID)
+		b.showSettings(chatID, userID, lang)
 
-	case data == "help":
+	case actHelp:
 		// Отправляем справку
-		helpMsg := "🤖 *Справка по командам:*\n\n" +
-			"/start - запустить бота и показать главное меню\n" +
-			"/help - показать справку по командам\n" +
-			"/add - добавить новое событие\n" +
-			"/list - показать список ваших событий\n" +
-			"/settings - настройки уведомлений\n\n" +
-			"Вы также можете использовать кнопки меню для более удобной навигации."
-
-		msg := tgbotapi.NewMessage(chatID, helpMsg)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "help.text"))
 		msg.ParseMode = "Markdown"
 		b.API.Send(msg)
 
-	case data == "back_to_menu":
+	case actMenu:
 		// Возвращаем пользователя в главное меню
 		b.ResetUserState(userID)
-		b.SendMainMenu(chatID)
+		b.SendMainMenu(chatID, lang)
 
-	case data == "set_notify_time":
+	case actSetNotifyTime:
 		// Начинаем процесс установки времени уведомлений
 		b.SetUserState(userID, models.StateSetNotifyTime)
-		msg := tgbotapi.NewMessage(chatID, "Введите время для получения уведомлений в формате ЧЧ:ММ (например, 09:00):")
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "settings.time_prompt"))
 		b.API.Send(msg)
 
-	case strings.HasPrefix(data, "type:"):
+	case actType:
 		// Обработка выбора типа события
 		if userState.State == models.StateAddEventType {
-			eventType := strings.TrimPrefix(data, "type:")
-			b.SaveUserData(userID, "type", eventType)
+			typeID, err := cb.Int64(0)
+			if err != nil {
+				log.Printf("Ошибка при парсинге ID типа события: %v", err)
+				return
+			}
+
+			_, eventType := b.loadEventType(chatID, userID, typeID, lang)
+			if eventType == nil {
+				return
+			}
+
+			b.SaveUserData(userID, "type", eventType.Kind)
+			b.SaveUserData(userID, "type_id", eventType.ID)
+			b.SaveUserData(userID, "type_notify_days", eventType.NotifyDays)
 			b.SetUserState(userID, models.StateAddEventDate)
 
-			msg := tgbotapi.NewMessage(chatID, "Введите дату события в формате ДД.ММ.ГГГГ:")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "add.date_prompt"))
 			b.API.Send(msg)
 		}
 
-	case strings.HasPrefix(data, "event:"):
-		// Обработка выбора события для редактирования или просмотра
-		eventIDStr := strings.TrimPrefix(data, "event:")
-		eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+	case actNotifyDefault:
+		// Напоминание за число дней, заданное в типе события
+		if userState.State == models.StateAddEventNotify {
+			days, err := cb.Int64(0)
+			if err != nil {
+				log.Printf("Ошибка при парсинге числа дней: %v", err)
+				return
+			}
+
+			b.SaveUserData(userID, "notify_days", int(days))
+			b.SetUserState(userID, models.StateAddEventDesc)
+			eventType, _ := userState.CurrentData["type"].(string)
+			b.sendText(chatID, descPrompt(lang, eventType))
+		}
+
+	case actListPage:
+		// Страница, сортировка или фильтр списка событий
+		b.showEventsPage(chatID, callback.Message.MessageID, userID, listOptionsFromCallback(cb), lang)
+
+	case actSnooze, actAcknowledge:
+		// Кнопки напоминания: отложить или отметить выполненным
+		b.handleReminderCallback(chatID, userID, cb, lang)
+
+	case actDND:
+		// Пауза напоминаний: без аргумента показываем варианты
+		if cb.Arg(0) == "" {
+			b.showDNDOptions(chatID, userID, lang)
+			return
+		}
+		days, err := cb.Int64(0)
 		if err != nil {
-			log.Printf("Ошибка при парсинге ID события: %v", err)
+			log.Printf("Ошибка при парсинге длительности паузы: %v", err)
 			return
 		}
+		b.setDND(chatID, userID, int(days), lang)
+
+	case actQuietHours:
+		// Начинаем ввод тихих часов
+		b.SetUserState(userID, models.StateSetQuietHours)
+		b.sendText(chatID, i18n.T(lang, "settings.quiet_prompt"))
+
+	case actDigest:
+		// Включение и выключение сводки
+		b.toggleDigest(chatID, userID, cb.Arg(0), lang)
+
+	case actAgenda:
+		// Повестка за период; кнопки переключения периодов редактируют сообщение
+		messageID := 0
+		if cb.Arg(1) != "" {
+			messageID = callback.Message.MessageID
+		}
+		b.showAgenda(chatID, messageID, userID, cb.Arg(0), lang)
 
-		event, err := b.DB.GetEventByID(eventID)
-		if err != nil || event == nil {
-			log.Printf("Ошибка при получении события: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Событие не найдено.")
-			b.API.Send(msg)
+	case actNoop:
+		// Кнопка только показывает информацию
+
+	case actSearch:
+		// Переключение страницы результатов поиска
+		page, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге номера страницы: %v", err)
 			return
 		}
+		b.sendSearchResults(chatID, callback.Message.MessageID, userID, cb.Arg(1), int(page), lang)
 
-		// Форматируем дату для отображения
-		displayDate := utils.FormatDisplayDate(event.EventDate)
-
-		// Отправляем информацию о событии с кнопками редактирования
-		eventMsg := fmt.Sprintf("🗓 *%s*\n\n"+
-			"🏷 Тип: %s\n"+
-			"📅 Дата: %s\n"+
-			"🔔 Напоминание: за %d дней\n",
-			event.Title, event.Type, displayDate, event.NotifyDays)
+	case actEventTypes, actEventType, actNewEventType, actEventTypeField, actDeleteEventType, actConfirmDeleteEventType:
+		// Раздел настроек с типами событий
+		b.handleEventTypeCallback(chatID, userID, cb, lang)
 
-		if event.Description != "" {
-			eventMsg += fmt.Sprintf("📝 Описание: %s\n", event.Description)
+	case actEvent:
+		// Обработка выбора события для редактирования или просмотра
+		eventID, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID события: %v", err)
+			return
 		}
 
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("✏️ Редактировать", fmt.Sprintf("edit:%d", event.ID)),
-				tgbotapi.NewInlineKeyboardButtonData("❌ Удалить", fmt.Sprintf("delete:%d", event.ID)),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к списку", "list_events"),
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", "back_to_menu"),
-			),
-		)
-
-		msg := tgbotapi.NewMessage(chatID, eventMsg)
-		msg.ParseMode = "Markdown"
-		msg.ReplyMarkup = keyboard
-		b.API.Send(msg)
+		b.showEvent(chatID, userID, eventID, lang)
 
-	case strings.HasPrefix(data, "edit:"):
+	case actEdit:
 		// Начало редактирования события
-		eventIDStr := strings.TrimPrefix(data, "edit:")
-		eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+		eventID, err := cb.Int64(0)
 		if err != nil {
 			log.Printf("Ошибка при парсинге ID события: %v", err)
 			return
 		}
 
-		event, err := b.DB.GetEventByID(eventID)
-		if err != nil || event == nil {
-			log.Printf("Ошибка при получении события: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Событие не найдено.")
-			b.API.Send(msg)
+		_, event := b.loadEvent(chatID, userID, eventID, db.PermissionEdit, lang)
+		if event == nil {
 			return
 		}
 
@@ -572,214 +910,582 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 		// Показываем варианты полей для редактирования
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🔤 Название", "edit_field:title"),
-				tgbotapi.NewInlineKeyboardButtonData("🏷 Тип", "edit_field:type"),
+				b.button(i18n.T(lang, "edit.title"), actEditField, "title"),
+				b.button(i18n.T(lang, "edit.type"), actEditField, "type"),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📅 Дата", "edit_field:date"),
-				tgbotapi.NewInlineKeyboardButtonData("🔔 Дни напоминания", "edit_field:notify_days"),
+				b.button(i18n.T(lang, "edit.date"), actEditField, "date"),
+				b.button(i18n.T(lang, "edit.notify_days"), actEditField, "notify_days"),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📝 Описание", "edit_field:description"),
-				tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", fmt.Sprintf("event:%d", eventID)),
+				b.button(i18n.T(lang, "edit.description"), actEditField, "description"),
+				b.button(i18n.T(lang, "edit.tags"), actEditField, "tags"),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "edit.priority"), actEditField, "priority"),
+				b.button(i18n.T(lang, "edit.nag_hours"), actEditField, "nag_hours"),
 			),
 		)
 
-		msg := tgbotapi.NewMessage(chatID, "Что вы хотите изменить?")
+		// Правило для 29 февраля имеет смысл только для событий в этот день
+		ruleRow := tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "edit.recurrence"), actEditField, "recurrence"),
+		)
+		if isLeapDay(event.EventDate) {
+			ruleRow = append(ruleRow, b.button(i18n.T(lang, "edit.leap_policy"), actEditField, "leap_policy"))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, ruleRow,
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "edit.calendar"), actEditField, "calendar"),
+				b.button(i18n.T(lang, "edit.greeting"), actEditField, "greeting"),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "edit.location"), actEditField, "location"),
+				b.button(i18n.T(lang, "edit.leave_by"), actEditField, "leave_by"),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "edit.attachments"), actAttach, eventID),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "button.back"), actEvent, eventID),
+			),
+		)
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "edit.prompt"))
 		msg.ReplyMarkup = keyboard
 		b.API.Send(msg)
 
-	case strings.HasPrefix(data, "edit_field:"):
+	case actEditField:
 		// Обработка выбора поля для редактирования
-		field := strings.TrimPrefix(data, "edit_field:")
+		field := cb.Arg(0)
 		b.SaveUserData(userID, "field", field)
 		b.SetUserState(userID, models.StateEditEventValue)
 
 		var promptMsg string
 		switch field {
 		case "title":
-			promptMsg = "Введите новое название события:"
+			promptMsg = i18n.T(lang, "edit.title_prompt")
 		case "type":
-			// Для типа показываем кнопки с вариантами
-			keyboard := tgbotapi.NewInlineKeyboardMarkup()
-			for _, eventType := range models.EventTypes {
-				row := tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData(eventType, "set_type:"+eventType),
-				)
-				keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+			// Для типа показываем кнопки с типами событий пользователя
+			user, err := b.DB.GetUserByTelegramID(userID)
+			if err != nil || user == nil {
+				log.Printf("Ошибка при получении пользователя: %v", err)
+				return
 			}
 
-			msg := tgbotapi.NewMessage(chatID, "Выберите новый тип события:")
-			msg.ReplyMarkup = keyboard
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "edit.type_prompt"))
+			msg.ReplyMarkup = b.eventTypeKeyboard(lang, user.ID, actSetType)
 			b.API.Send(msg)
 			return
 		case "date":
-			promptMsg = "Введите новую дату события в формате ДД.ММ.ГГГГ:"
+			promptMsg = i18n.T(lang, "edit.date_prompt")
 		case "notify_days":
-			promptMsg = "За сколько дней до события отправлять напоминание? (введите число от 1 до 30):"
+			promptMsg = i18n.T(lang, "edit.notify_prompt")
 		case "description":
-			promptMsg = "Введите новое описание события:"
+			promptMsg = i18n.T(lang, "edit.desc_prompt")
+		case "tags":
+			promptMsg = i18n.T(lang, "edit.tags_prompt")
+		case "location":
+			promptMsg = i18n.T(lang, "edit.location_prompt")
+		case "leave_by":
+			promptMsg = i18n.T(lang, "edit.leave_by_prompt", db.MaxTravelMinutes)
+		case "priority":
+			// Приоритет выбирается кнопками
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "edit.priority_prompt"))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					b.button(i18n.T(lang, "priority.normal"), actSetPriority, models.PriorityNormal),
+					b.button(i18n.T(lang, "priority.high"), actSetPriority, models.PriorityHigh),
+				),
+			)
+			b.API.Send(msg)
+			return
+		case "nag_hours":
+			promptMsg = i18n.T(lang, "edit.nag_prompt")
+		case "recurrence":
+			// Повторение выбирается кнопками
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "edit.recurrence_prompt"))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					b.button(i18n.T(lang, "recurrence.yearly"), actSetRule, models.RecurrenceYearly),
+					b.button(i18n.T(lang, "recurrence.monthly"), actSetRule, models.RecurrenceMonthly),
+					b.button(i18n.T(lang, "recurrence.once"), actSetRule, models.RecurrenceOnce),
+				),
+			)
+			b.API.Send(msg)
+			return
+		case "calendar":
+			// Календарь выбирается из тех, куда пользователь может добавлять события
+			b.sendCalendarChoice(chatID, userID, lang)
+			return
+		case "greeting":
+			// Поздравление выбирается из шаблонов пользователя
+			user, err := b.DB.GetUserByTelegramID(userID)
+			if err != nil || user == nil {
+				log.Printf("Ошибка при получении пользователя: %v", err)
+				return
+			}
+			eventID, _ := userState.CurrentData["event_id"].(int64)
+			b.sendGreetingChoice(chatID, user, greetingTargetEvent, eventID, lang)
+			return
+		case "leap_policy":
+			// Правило для 29 февраля выбирается кнопками
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "edit.leap_policy_prompt"))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					b.button(i18n.T(lang, "leap_policy.feb28"), actSetRule, models.LeapPolicyFeb28),
+					b.button(i18n.T(lang, "leap_policy.mar1"), actSetRule, models.LeapPolicyMar1),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					b.button(i18n.T(lang, "leap_policy.leap_only"), actSetRule, models.LeapPolicyLeapOnly),
+				),
+			)
+			b.API.Send(msg)
+			return
 		default:
-			promptMsg = "Выберите поле для редактирования:"
+			promptMsg = i18n.T(lang, "edit.field_prompt")
 		}
 
 		msg := tgbotapi.NewMessage(chatID, promptMsg)
 		b.API.Send(msg)
 
-	case strings.HasPrefix(data, "set_type:"):
-		// Обработка выбора нового типа события при редактировании
-		if userState.State == models.StateEditEventValue && userState.CurrentData["field"] == "type" {
-			newType := strings.TrimPrefix(data, "set_type:")
+	case actSetPriority:
+		// Обработка выбора приоритета события при редактировании
+		if userState.State == models.StateEditEventValue && userState.CurrentData["field"] == "priority" {
+			priority, err := cb.Int64(0)
+			if err != nil {
+				log.Printf("Ошибка при парсинге приоритета: %v", err)
+				return
+			}
 			eventID := userState.CurrentData["event_id"].(int64)
 
-			event, err := b.DB.GetEventByID(eventID)
+			user, event := b.loadEvent(chatID, userID, eventID, db.PermissionEdit, lang)
+			if event == nil {
+				b.ResetUserState(userID)
+				return
+			}
+
+			event.Priority = models.PriorityNormal
+			if priority == models.PriorityHigh {
+				event.Priority = models.PriorityHigh
+			}
+			revisionID, err := b.DB.UpdateEventForUser(event, user.ID)
 			if err != nil {
-				log.Printf("Ошибка при получении события: %v", err)
-				msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при редактировании события.")
-				b.API.Send(msg)
+				log.Printf("Ошибка при обновлении события: %v", err)
+				b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+				b.ResetUserState(userID)
+				return
+			}
+
+			b.ResetUserState(userID)
+			b.sendEventUpdated(chatID, event, revisionID, lang)
+		}
+
+	case actSetRule:
+		// Обработка выбора повторения или правила для 29 февраля при редактировании
+		field, _ := userState.CurrentData["field"].(string)
+		if userState.State == models.StateEditEventValue && (field == "recurrence" || field == "leap_policy") {
+			eventID := userState.CurrentData["event_id"].(int64)
+
+			user, event := b.loadEvent(chatID, userID, eventID, db.PermissionEdit, lang)
+			if event == nil {
 				b.ResetUserState(userID)
 				return
 			}
 
-			event.Type = newType
-			err = b.DB.UpdateEvent(event)
+			value := cb.Arg(0)
+			switch {
+			case field == "recurrence" && (value == models.RecurrenceYearly || value == models.RecurrenceMonthly):
+				event.Recurrence = value
+			case field == "recurrence" && value == models.RecurrenceOnce:
+				// Разовому событию нужна конкретная дата с годом
+				date, err := utils.OnceDate(event.EventDate, time.Now())
+				if err != nil {
+					log.Printf("Ошибка при расчете даты разового события %d: %v", event.ID, err)
+					b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+					b.ResetUserState(userID)
+					return
+				}
+				event.Recurrence = value
+				event.EventDate = date
+			case field == "leap_policy" && (value == models.LeapPolicyFeb28 || value == models.LeapPolicyMar1 ||
+				value == models.LeapPolicyLeapOnly):
+				event.LeapPolicy = value
+			default:
+				log.Printf("Неизвестное значение правила повторения: %s=%s", field, value)
+				b.ResetUserState(userID)
+				return
+			}
+
+			revisionID, err := b.DB.UpdateEventForUser(event, user.ID)
 			if err != nil {
 				log.Printf("Ошибка при обновлении события: %v", err)
-				msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при сохранении изменений.")
-				b.API.Send(msg)
+				b.sendText(chatID, i18n.T(lang, "error.save_changes"))
 				b.ResetUserState(userID)
 				return
 			}
 
-			// Форматируем дату для отображения
-			displayDate := utils.FormatDisplayDate(event.EventDate)
+			b.ResetUserState(userID)
+			b.sendEventUpdated(chatID, event, revisionID, lang)
+		}
+
+	case actSetCalendar:
+		// Перенос события в общий календарь или в личный список
+		if userState.State == models.StateEditEventValue && userState.CurrentData["field"] == "calendar" {
+			calendarID, err := cb.Int64(0)
+			if err != nil {
+				log.Printf("Ошибка при парсинге ID календаря: %v", err)
+				return
+			}
+			eventID := userState.CurrentData["event_id"].(int64)
+			b.ResetUserState(userID)
+			b.moveEvent(chatID, userID, eventID, calendarID, lang)
+		}
+
+	case actCalendars, actCalendar, actNewCalendar, actCalendarAdd, actCalendarInvite,
+		actCalendarRole, actCalendarRemove, actCalendarDelete:
+		b.handleCalendarCallback(chatID, userID, cb, lang)
+
+	case actGreetings, actGreeting, actNewGreeting, actGreetingLibrary, actDeleteGreeting,
+		actChooseGreeting, actSetGreeting:
+		// Шаблоны поздравлений и их привязка к событиям и типам
+		b.handleGreetingCallback(chatID, userID, cb, lang)
+
+	case actGifts, actGift, actNewGift, actGiftStatus, actDeleteGift:
+		// Идеи подарков к событию и история подаренного
+		b.handleGiftCallback(chatID, userID, cb, lang)
+
+	case actAttachments, actAttach, actDeleteAttachment:
+		// Фото и файлы, прикрепленные к событию
+		b.handleAttachmentCallback(chatID, userID, cb, lang)
 
-			successMsg := fmt.Sprintf("✅ Событие успешно обновлено!\n\n"+
-				"🔤 Название: %s\n"+
-				"🏷 Тип: %s\n"+
-				"📅 Дата: %s\n"+
-				"🔔 Напоминание: за %d дней\n",
-				event.Title, event.Type, displayDate, event.NotifyDays)
+	case actTasks, actNewTask, actToggleTask, actDeleteTask:
+		// Задачи подготовки к событию; отметки обновляют сообщение на месте
+		b.handleTaskCallback(chatID, callback.Message.MessageID, userID, cb, lang)
 
-			if event.Description != "" {
-				successMsg += fmt.Sprintf("📝 Описание: %s\n", event.Description)
+	case actShare:
+		// Ссылка-приглашение подписаться на событие и список подписчиков
+		eventID, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID события: %v", err)
+			return
+		}
+
+		b.showEventShare(chatID, userID, eventID, lang)
+
+	case actUnsubscribe:
+		// Отписка от события или отключение подписчика
+		b.unsubscribeEvent(chatID, userID, cb, lang)
+
+	case actSubscriptionNotify:
+		// Собственный срок напоминаний подписчика
+		b.askSubscriptionNotify(chatID, userID, cb, lang)
+
+	case actSetType:
+		// Обработка выбора нового типа события при редактировании
+		if userState.State == models.StateEditEventValue && userState.CurrentData["field"] == "type" {
+			typeID, err := cb.Int64(0)
+			if err != nil {
+				log.Printf("Ошибка при парсинге ID типа события: %v", err)
+				return
 			}
+			eventID := userState.CurrentData["event_id"].(int64)
 
-			msg := tgbotapi.NewMessage(chatID, successMsg)
-			b.API.Send(msg)
+			user, event := b.loadEvent(chatID, userID, eventID, db.PermissionEdit, lang)
+			if event == nil {
+				b.ResetUserState(userID)
+				return
+			}
+
+			eventType, err := b.DB.GetEventTypeForUser(typeID, user.ID)
+			if err != nil {
+				log.Printf("Ошибка при получении типа события: %v", err)
+				b.sendText(chatID, i18n.T(lang, "error.event_type_not_found"))
+				b.ResetUserState(userID)
+				return
+			}
+
+			event.Type = eventType.Kind
+			event.TypeID = eventType.ID
+			revisionID, err := b.DB.UpdateEventForUser(event, user.ID)
+			if err != nil {
+				log.Printf("Ошибка при обновлении события: %v", err)
+				msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.save_changes"))
+				b.API.Send(msg)
+				b.ResetUserState(userID)
+				return
+			}
 
-			// Возвращаем пользователя в главное меню
 			b.ResetUserState(userID)
-			b.SendMainMenu(chatID)
+			b.sendEventUpdated(chatID, event, revisionID, lang)
 		}
 
-	case strings.HasPrefix(data, "delete:"):
+	case actDelete:
 		// Подтверждение удаления события
-		eventIDStr := strings.TrimPrefix(data, "delete:")
-		eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+		eventID, err := cb.Int64(0)
 		if err != nil {
 			log.Printf("Ошибка при парсинге ID события: %v", err)
 			return
 		}
 
+		if _, event := b.loadEvent(chatID, userID, eventID, db.PermissionEdit, lang); event == nil {
+			return
+		}
+
 		// Сохраняем ID события для последующего удаления
 		b.SaveUserData(userID, "delete_event_id", eventID)
 
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("✅ Да, удалить", fmt.Sprintf("confirm_delete:%d", eventID)),
-				tgbotapi.NewInlineKeyboardButtonData("❌ Нет, отменить", fmt.Sprintf("event:%d", eventID)),
+				b.button(i18n.T(lang, "delete.confirm"), actConfirmDelete, eventID),
+				b.button(i18n.T(lang, "delete.cancel"), actEvent, eventID),
 			),
 		)
 
-		msg := tgbotapi.NewMessage(chatID, "❓ Вы уверены, что хотите удалить это событие? Это действие нельзя отменить.")
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "delete.prompt",
+			i18n.N(lang, b.Config.TrashRetentionDays, "days")))
 		msg.ReplyMarkup = keyboard
 		b.API.Send(msg)
 
-	case strings.HasPrefix(data, "confirm_delete:"):
+	case actConfirmDelete:
 		// Выполнение удаления события
-		eventIDStr := strings.TrimPrefix(data, "confirm_delete:")
-		eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+		eventID, err := cb.Int64(0)
 		if err != nil {
 			log.Printf("Ошибка при парсинге ID события: %v", err)
 			return
 		}
 
-		err = b.DB.DeleteEvent(eventID)
+		user, event := b.loadEvent(chatID, userID, eventID, db.PermissionEdit, lang)
+		if event == nil {
+			return
+		}
+
+		err = b.DB.DeleteEventForUser(event.ID, user.ID)
 		if err != nil {
 			log.Printf("Ошибка при удалении события: %v", err)
-			msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при удалении события.")
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.delete_event"))
 			b.API.Send(msg)
 			return
 		}
 
-		msg := tgbotapi.NewMessage(chatID, "✅ Событие успешно удалено!")
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "button.undo"), actRestore, eventID),
+			),
+		)
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "delete.done"))
+		msg.ReplyMarkup = keyboard
 		b.API.Send(msg)
 
 		// Обновляем список событий
-		b.sendEventsList(chatID, userID)
+		b.sendEventsList(chatID, userID, lang)
+
+	case actRestore:
+		// Восстановление события из корзины
+		eventID, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID события: %v", err)
+			return
+		}
+
+		user, err := b.DB.GetUserByTelegramID(userID)
+		if err == nil && user != nil {
+			err = b.DB.RestoreEventForUser(eventID, user.ID, b.Config.TrashRetentionDays)
+		}
+		if err != nil || user == nil {
+			log.Printf("Ошибка при восстановлении события: %v", err)
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.restore_event"))
+			b.API.Send(msg)
+			return
+		}
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "trash.restored"))
+		b.API.Send(msg)
+		b.sendEventsList(chatID, userID, lang)
+
+	case actTrash:
+		// Показываем корзину
+		b.showTrash(chatID, userID, lang)
+
+	case actHistory:
+		// Показываем историю изменений события
+		eventID, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID события: %v", err)
+			return
+		}
+
+		b.showEventHistory(chatID, userID, eventID, lang)
+
+	case actRevert:
+		// Откат события к значениям из ревизии
+		revisionID, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID ревизии: %v", err)
+			return
+		}
+
+		user, err := b.DB.GetUserByTelegramID(userID)
+		if err != nil || user == nil {
+			log.Printf("Ошибка при получении пользователя: %v", err)
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.revision_not_found"))
+			b.API.Send(msg)
+			return
+		}
+
+		event, newRevisionID, err := b.DB.RevertEventForUser(revisionID, user.ID)
+		if err != nil {
+			log.Printf("Ошибка при откате события: %v", err)
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.revert_event"))
+			b.API.Send(msg)
+			return
+		}
+
+		b.sendEventUpdated(chatID, event, newRevisionID, lang)
+
+	case actConfirmDeleteMe:
+		// Удаление аккаунта пользователя
+		b.deleteUserData(chatID, userID, lang)
+
+	case actCancelDeleteMe:
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "deleteme.cancelled"))
+		b.API.Send(msg)
+		b.SendMainMenu(chatID, lang)
+
+	case actLanguage:
+		// Показываем выбор языка
+		keyboard := tgbotapi.NewInlineKeyboardMarkup()
+		for _, code := range i18n.Languages {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.LanguageName(code), actSetLanguage, code),
+			))
+		}
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "settings.language_prompt"))
+		msg.ReplyMarkup = keyboard
+		b.API.Send(msg)
+
+	case actSetLanguage:
+		// Сохраняем выбранный язык
+		newLang := i18n.Normalize(cb.Arg(0))
+		user, err := b.DB.GetUserByTelegramID(userID)
+		if err == nil && user != nil {
+			err = b.DB.SetUserLanguage(user.ID, newLang)
+		}
+		if err != nil || user == nil {
+			log.Printf("Ошибка при обновлении языка: %v", err)
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.save_settings"))
+			b.API.Send(msg)
+			return
+		}
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(newLang, "settings.language_set"))
+		b.API.Send(msg)
+		b.showSettings(chatID, userID, newLang)
 	}
 }
 
 // HandleCommand обрабатывает команды бота
 func (b *Bot) handleCommand(message *tgbotapi.Message) {
-	userID := message.From.ID
+	userID := accountID(message.Chat, message.From)
 	chatID := message.Chat.ID
+	lang := b.userLang(message.Chat, message.From)
 
 	switch message.Command() {
 	case "start":
-		// Регистрируем пользователя если он новый
-		_, err := b.DB.CreateUser(
+		// Регистрируем пользователя если он новый, язык берем из настроек Telegram
+		internalID, err := b.DB.CreateUser(
 			userID,
 			message.From.UserName,
 			message.From.FirstName,
 			message.From.LastName,
+			lang,
 		)
 		if err != nil {
 			log.Printf("Ошибка при создании пользователя: %v", err)
+			b.sendText(chatID, i18n.T(lang, "error.register"))
+			return
 		}
 
 		// Приветственное сообщение
-		welcomeMsg := fmt.Sprintf(
-			"👋 Привет, %s!\n\n"+
-				"Я бот для напоминания о днях рождения и важных событиях. "+
-				"С моей помощью вы не забудете поздравить друзей и близких с праздниками.\n\n"+
-				"Используйте меню для управления вашими событиями и напоминаниями:",
-			message.From.FirstName,
-		)
-		msg := tgbotapi.NewMessage(chatID, welcomeMsg)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "start.welcome", message.From.FirstName))
 		b.API.Send(msg)
+		if isGroupChat(message.Chat) {
+			b.sendText(chatID, i18n.T(lang, "group.welcome"))
+		}
+
+		// Ссылка-приглашение передает код календаря или события в параметре /start
+		switch code := message.CommandArguments(); {
+		case strings.HasPrefix(code, db.InvitePrefix):
+			b.joinCalendar(chatID, internalID, code, lang)
+		case strings.HasPrefix(code, db.ShareInvitePrefix):
+			b.subscribeToEvent(chatID, internalID, code, lang)
+		}
 
 		// Сбрасываем состояние и отправляем главное меню
 		b.ResetUserState(userID)
-		b.SendMainMenu(chatID)
+		b.SendMainMenu(chatID, lang)
 
 	case "help":
-		helpMsg := "🤖 *Справка по командам:*\n\n" +
-			"/start - запустить бота и показать главное меню\n" +
-			"/help - показать справку по командам\n" +
-			"/add - добавить новое событие\n" +
-			"/list - показать список ваших событий\n" +
-			"/settings - настройки уведомлений\n\n" +
-			"Вы также можете использовать кнопки меню для более удобной навигации."
-
-		msg := tgbotapi.NewMessage(chatID, helpMsg)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "help.text"))
 		msg.ParseMode = "Markdown"
 		b.API.Send(msg)
 
 	case "add":
 		// Начинаем процесс добавления события
+		b.ResetUserState(userID)
 		b.SetUserState(userID, models.StateAddEventTitle)
-		msg := tgbotapi.NewMessage(chatID, "Введите название события:")
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "add.title_prompt"))
 		b.API.Send(msg)
 
 	case "list":
 		// Отправляем список событий пользователя
-		b.sendEventsList(chatID, userID)
+		b.sendEventsList(chatID, userID, lang)
+
+	case agendaToday, agendaWeek, agendaMonth, agendaNext:
+		// Повестка: события сегодня, за неделю, за месяц или ближайшее событие
+		b.showAgenda(chatID, 0, userID, message.Command(), lang)
 
 	case "settings":
 		// Показываем меню настроек
-		b.showSettings(chatID, userID)
+		b.showSettings(chatID, userID, lang)
+
+	case "calendars":
+		// Показываем общие календари
+		b.ResetUserState(userID)
+		b.showCalendars(chatID, userID, lang)
+
+	case "trash":
+		// Показываем корзину
+		b.showTrash(chatID, userID, lang)
+
+	case "mydata":
+		// Отправляем архив с данными пользователя
+		b.sendUserData(chatID, userID, lang)
+
+	case "deleteme":
+		// Запрашиваем подтверждение удаления аккаунта
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "deleteme.confirm"), actConfirmDeleteMe),
+				b.button(i18n.T(lang, "delete.cancel"), actCancelDeleteMe),
+			),
+		)
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "deleteme.prompt"))
+		msg.ReplyMarkup = keyboard
+		b.API.Send(msg)
+
+	case "search":
+		// Поиск событий: запрос можно передать сразу после команды
+		if query := strings.TrimSpace(message.CommandArguments()); query != "" {
+			b.sendSearchResults(chatID, 0, userID, query, 0, lang)
+			return
+		}
+
+		b.SetUserState(userID, models.StateSearch)
+		b.sendText(chatID, i18n.T(lang, "search.prompt"))
 
 	case "skip":
 		// Обработка команды пропуска (например, для описания события)
@@ -794,107 +1500,327 @@ func (b *Bot) handleCommand(message *tgbotapi.Message) {
 		}
 
 	default:
-		msg := tgbotapi.NewMessage(chatID, "Неизвестная команда. Используйте /help для списка доступных команд.")
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.unknown_command"))
 		b.API.Send(msg)
 	}
 }
 
-// sendEventsList отправляет список событий пользователя
-func (b *Bot) sendEventsList(chatID, userID int64) {
-	user, err := b.DB.GetUserByTelegramID(userID)
-	if err != nil {
-		log.Printf("Ошибка при получении пользователя: %v", err)
-		msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при получении списка событий.")
-		b.API.Send(msg)
+// sendEventsList отправляет первую страницу списка событий пользователя
+func (b *Bot) sendEventsList(chatID, userID int64, lang string) {
+	b.showEventsPage(chatID, 0, userID, listOptions{}, lang)
+}
+
+// showEvent отправляет карточку события с кнопками редактирования
+func (b *Bot) showEvent(chatID, userID, eventID int64, lang string) {
+	user, event := b.loadEvent(chatID, userID, eventID, db.PermissionView, lang)
+	if event == nil {
 		return
 	}
 
-	events, err := b.DB.GetEventsByUserID(user.ID)
+	eventMsg := fmt.Sprintf("🗓 *%s*\n\n", event.Title) + b.formatEventDetails(lang, event)
+
+	// Сами вложения отправляются по кнопке, в карточке только их число
+	attachments, err := b.DB.CountEventAttachments(event.ID)
 	if err != nil {
-		log.Printf("Ошибка при получении событий: %v", err)
-		msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при получении списка событий.")
-		b.API.Send(msg)
-		return
+		log.Printf("Ошибка при подсчете вложений события %d: %v", event.ID, err)
+	}
+	if attachments > 0 {
+		eventMsg += i18n.T(lang, "event.attachments_count", attachments)
 	}
 
-	if len(events) == 0 {
-		// Если событий нет
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+	// Подписчику чужого события доступны только просмотр и отписка
+	subscribed, err := b.DB.IsEventSubscriber(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при проверке подписки на событие %d: %v", event.ID, err)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if subscribed {
+		eventMsg += i18n.T(lang, "event.subscribed") + b.subscriptionNotifyText(lang, event, user.ID)
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "event.subscription_notify"), actSubscriptionNotify, event.ID),
+				b.button(i18n.T(lang, "event.unsubscribe"), actUnsubscribe, event.ID, user.ID),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "event.tasks"), actTasks, event.ID),
+				b.button(i18n.T(lang, "event.gifts"), actGifts, event.ID),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "event.attachments"), actAttachments, event.ID),
+				b.button(i18n.T(lang, "event.history"), actHistory, event.ID),
+			),
+		)
+	} else {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "event.edit"), actEdit, event.ID),
+				b.button(i18n.T(lang, "event.delete"), actDelete, event.ID),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "event.tasks"), actTasks, event.ID),
+				b.button(i18n.T(lang, "event.gifts"), actGifts, event.ID),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "event.attachments"), actAttachments, event.ID),
+				b.button(i18n.T(lang, "event.share"), actShare, event.ID),
+			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("➕ Добавить событие", "add_event"),
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", "back_to_menu"),
+				b.button(i18n.T(lang, "event.history"), actHistory, event.ID),
 			),
 		)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.back_to_list"), actListEvents),
+		b.button(i18n.T(lang, "button.main_menu"), actMenu),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-		msg := tgbotapi.NewMessage(chatID, "У вас пока нет добавленных событий. Добавьте первое событие!")
-		msg.ReplyMarkup = keyboard
+	msg := tgbotapi.NewMessage(chatID, eventMsg)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// loadEvent получает пользователя и событие с проверкой его прав.
+// Если событие недоступно, сообщает об этом в чат и возвращает nil.
+func (b *Bot) loadEvent(chatID, telegramID, eventID int64, perm db.Permission, lang string) (*models.User, *models.Event) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err == nil && user == nil {
+		err = db.ErrAccessDenied
+	}
+
+	var event *models.Event
+	if err == nil {
+		event, err = b.DB.GetEventForUser(eventID, user.ID, perm)
+	}
+
+	if err != nil {
+		// Не различаем «нет такого события» и «чужое событие», чтобы не раскрывать чужие ID
+		if !errors.Is(err, db.ErrEventNotFound) && !errors.Is(err, db.ErrAccessDenied) {
+			log.Printf("Ошибка при получении события: %v", err)
+		}
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.event_not_found"))
+		b.API.Send(msg)
+		return nil, nil
+	}
+
+	return user, event
+}
+
+// sendEventUpdated сообщает об изменении события и предлагает отменить его
+func (b *Bot) sendEventUpdated(chatID int64, event *models.Event, revisionID int64, lang string) {
+	successMsg := i18n.T(lang, "edit.success", event.Title) + b.formatEventDetails(lang, event)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.undo"), actRevert, revisionID),
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, successMsg)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// showTrash показывает события в корзине с кнопками восстановления
+func (b *Bot) showTrash(chatID, userID int64, lang string) {
+	user, err := b.DB.GetUserByTelegramID(userID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.trash"))
+		b.API.Send(msg)
+		return
+	}
+
+	events, err := b.DB.GetDeletedEventsByUserID(user.ID, b.Config.TrashRetentionDays)
+	if err != nil {
+		log.Printf("Ошибка при получении корзины: %v", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.trash"))
 		b.API.Send(msg)
 		return
 	}
 
-	// Создаем клавиатуру с кнопками для каждого события
 	keyboard := tgbotapi.NewInlineKeyboardMarkup()
 	for _, event := range events {
-		// Форматируем дату для отображения
-		displayDate := utils.FormatDisplayDate(event.EventDate)
-		
-		// Пытаемся получить дни до события
-		daysLeft, err := utils.DaysUntilEvent(event.EventDate)
-		daysInfo := ""
-		if err == nil {
-			if daysLeft == 0 {
-				daysInfo = " (сегодня!)"
-			} else {
-				daysInfo = fmt.Sprintf(" (через %d дн.)", daysLeft)
-			}
-		}
-		
-		buttonText := fmt.Sprintf("%s - %s%s", event.Title, displayDate, daysInfo)
+		buttonText := fmt.Sprintf("♻️ %s - %s", event.Title, i18n.FormatDate(lang, event.EventDate))
 		row := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(buttonText, fmt.Sprintf("event:%d", event.ID)),
+			b.button(buttonText, actRestore, event.ID),
 		)
 		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
 	}
-
-	// Добавляем кнопки для добавления нового события и возврата в меню
-	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, 
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard,
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("➕ Добавить событие", "add_event"),
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", "back_to_menu"),
+			b.button(i18n.T(lang, "button.back_to_list"), actListEvents),
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(chatID, "🗓 Ваши события:")
+	retention := i18n.N(lang, b.Config.TrashRetentionDays, "days")
+	text := i18n.T(lang, "trash.empty", retention)
+	if len(events) > 0 {
+		text = i18n.T(lang, "trash.title", retention)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// showEventHistory показывает прежние версии события с кнопками отката
+func (b *Bot) showEventHistory(chatID, userID, eventID int64, lang string) {
+	user, event := b.loadEvent(chatID, userID, eventID, db.PermissionView, lang)
+	if event == nil {
+		return
+	}
+
+	revisions, err := b.DB.GetEventRevisionsForUser(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении истории изменений: %v", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.history"))
+		b.API.Send(msg)
+		return
+	}
+
+	backRow := tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.back"), actEvent, eventID),
+	)
+
+	if len(revisions) == 0 {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "history.empty"))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(backRow)
+		b.API.Send(msg)
+		return
+	}
+
+	// Показываем не больше 10 последних версий
+	if len(revisions) > 10 {
+		revisions = revisions[:10]
+	}
+
+	var text strings.Builder
+	text.WriteString(i18n.T(lang, "history.title"))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for i, revision := range revisions {
+		changedAt := i18n.FormatDateTime(lang, revision.CreatedAt.Local())
+		text.WriteString(i18n.T(lang, "history.revision",
+			i+1, changedAt, revision.Title, b.typeLabel(lang, revision.TypeID, revision.Type),
+			i18n.FormatDate(lang, revision.EventDate), i18n.N(lang, revision.NotifyDays, "days")))
+		if revision.Description != "" {
+			fmt.Fprintf(&text, "📝 %s\n", revision.Description)
+		}
+
+		row := tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "history.revert", i+1), actRevert, revision.ID),
+		)
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, backRow)
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
 	msg.ReplyMarkup = keyboard
 	b.API.Send(msg)
 }
 
 // showSettings показывает меню настроек
-func (b *Bot) showSettings(chatID, userID int64) {
+func (b *Bot) showSettings(chatID, userID int64, lang string) {
 	user, err := b.DB.GetUserByTelegramID(userID)
-	if err != nil {
+	if err != nil || user == nil {
 		log.Printf("Ошибка при получении пользователя: %v", err)
-		msg := tgbotapi.NewMessage(chatID, "❌ Произошла ошибка при получении настроек.")
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.settings"))
 		b.API.Send(msg)
 		return
 	}
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⏰ Изменить время уведомлений", "set_notify_time"),
+			b.button(i18n.T(lang, "settings.change_time"), actSetNotifyTime),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "settings.event_types"), actEventTypes),
+			b.button(i18n.T(lang, "settings.calendars"), actCalendars),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "settings.change_language"), actLanguage),
+			b.button(i18n.T(lang, "settings.greetings"), actGreetings),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", "back_to_menu"),
+			b.button(i18n.T(lang, "settings.change_quiet_hours"), actQuietHours),
+			b.button(i18n.T(lang, "settings.change_dnd"), actDND),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "settings.toggle_daily_digest"), actDigest, digestDaily),
+			b.button(i18n.T(lang, "settings.toggle_weekly_digest"), actDigest, digestWeekly),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
 		),
 	)
 
-	settingsMsg := fmt.Sprintf("⚙️ *Настройки:*\n\n"+
-		"⏰ Время уведомлений: *%s*\n\n"+
-		"Выберите настройку, которую хотите изменить:",
-		user.NotificationTime)
+	settingsMsg := i18n.T(lang, "settings.title") +
+		i18n.T(lang, "settings.time", user.NotificationTime) +
+		i18n.T(lang, "settings.daily_digest", digestStatus(lang, user.DailyDigest)) +
+		i18n.T(lang, "settings.weekly_digest", digestStatus(lang, user.WeeklyDigest)) +
+		i18n.T(lang, "settings.quiet_hours", quietHoursStatus(lang, user)) +
+		i18n.T(lang, "settings.dnd", dndStatus(lang, user)) +
+		i18n.T(lang, "settings.language", i18n.LanguageName(lang)) +
+		i18n.T(lang, "settings.choose")
 
 	msg := tgbotapi.NewMessage(chatID, settingsMsg)
 	msg.ParseMode = "Markdown"
 	msg.ReplyMarkup = keyboard
 	b.API.Send(msg)
-}
\ No newline at end of file
+}
+
+// sendUserData отправляет пользователю JSON-архив со всеми его данными
+func (b *Bot) sendUserData(chatID, userID int64, lang string) {
+	export, err := b.DB.ExportUserData(userID)
+	if err != nil {
+		log.Printf("Ошибка при выгрузке данных пользователя: %v", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.export"))
+		b.API.Send(msg)
+		return
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		log.Printf("Ошибка при сериализации данных пользователя: %v", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.export"))
+		b.API.Send(msg)
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("reminder-bot-data-%d.json", userID),
+		Bytes: data,
+	})
+	doc.Caption = i18n.T(lang, "mydata.caption")
+	if _, err := b.API.Send(doc); err != nil {
+		log.Printf("Ошибка при отправке архива данных: %v", err)
+	}
+}
+
+// deleteUserData удаляет пользователя и все его события
+func (b *Bot) deleteUserData(chatID, userID int64, lang string) {
+	user, err := b.DB.GetUserByTelegramID(userID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.account_not_found"))
+		b.API.Send(msg)
+		return
+	}
+
+	if err := b.DB.DeleteUser(user.ID); err != nil {
+		log.Printf("Ошибка при удалении пользователя: %v", err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "error.delete_account"))
+		b.API.Send(msg)
+		return
+	}
+
+	b.ResetUserState(userID)
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "deleteme.done"))
+	b.API.Send(msg)
+}
@@ -0,0 +1,373 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxCalendarName ограничение длины названия календаря в символах
+const maxCalendarName = 64
+
+// roleLabel возвращает название роли участника календаря
+func roleLabel(lang, role string) string {
+	return i18n.T(lang, "calendars.role_"+role)
+}
+
+// memberName возвращает имя участника календаря для отображения
+func memberName(member *models.CalendarMember) string {
+	if member.FirstName != "" {
+		return member.FirstName
+	}
+	if member.Username != "" {
+		return "@" + member.Username
+	}
+	return fmt.Sprintf("#%d", member.UserID)
+}
+
+// calendarErrorText возвращает сообщение об ошибке действия с календарем
+func calendarErrorText(lang string, err error) string {
+	if errors.Is(err, db.ErrCalendarNotFound) || errors.Is(err, db.ErrAccessDenied) {
+		return i18n.T(lang, "calendars.not_found")
+	}
+	return i18n.T(lang, "error.save_changes")
+}
+
+// showCalendars показывает общие календари пользователя
+func (b *Bot) showCalendars(chatID, telegramID int64, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	calendars, err := b.DB.GetCalendarsByUserID(user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении календарей: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, calendar := range calendars {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.item", calendar.Name, roleLabel(lang, calendar.Role)), actCalendar, calendar.ID),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard,
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.new"), actNewCalendar),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.back"), actSettings),
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
+		),
+	)
+
+	text := i18n.T(lang, "calendars.title")
+	if len(calendars) == 0 {
+		text += i18n.T(lang, "calendars.empty")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// showCalendar показывает карточку календаря с участниками. Владельцу
+// доступны приглашения и управление участниками, остальным — выход.
+func (b *Bot) showCalendar(chatID int64, user *models.User, calendar *models.Calendar, lang string) {
+	members, err := b.DB.GetCalendarMembers(calendar.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении участников календаря: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	isOwner := calendar.Role == models.CalendarRoleOwner
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	if db.CanEditCalendar(calendar.Role) {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.add_event"), actCalendarAdd, calendar.ID),
+		))
+	}
+	if isOwner {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.invite_editor"), actCalendarInvite, calendar.ID, models.CalendarRoleEditor),
+			b.button(i18n.T(lang, "calendars.invite_viewer"), actCalendarInvite, calendar.ID, models.CalendarRoleViewer),
+		))
+	}
+
+	var lines []string
+	for _, member := range members {
+		lines = append(lines, i18n.T(lang, "calendars.member", memberName(member), roleLabel(lang, member.Role)))
+
+		if !isOwner || member.Role == models.CalendarRoleOwner {
+			continue
+		}
+		// Кнопка с участником переключает его роль между редактором и зрителем
+		nextRole := models.CalendarRoleViewer
+		if member.Role == models.CalendarRoleViewer {
+			nextRole = models.CalendarRoleEditor
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.change_role", memberName(member), roleLabel(lang, nextRole)),
+				actCalendarRole, calendar.ID, member.UserID, nextRole),
+			b.button(i18n.T(lang, "calendars.remove"), actCalendarRemove, calendar.ID, member.UserID),
+		))
+	}
+
+	if isOwner {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.delete"), actCalendarDelete, calendar.ID),
+		))
+	} else {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.leave"), actCalendarRemove, calendar.ID, user.ID),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.back"), actCalendars),
+	))
+
+	text := i18n.T(lang, "calendars.card", calendar.Name, roleLabel(lang, calendar.Role), strings.Join(lines, "\n"))
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// handleCalendarCallback обрабатывает кнопки раздела общих календарей
+func (b *Bot) handleCalendarCallback(chatID, telegramID int64, cb *Callback, lang string) {
+	switch cb.Action {
+	case actCalendars:
+		b.ResetUserState(telegramID)
+		b.showCalendars(chatID, telegramID, lang)
+		return
+
+	case actNewCalendar:
+		b.SetUserState(telegramID, models.StateNewCalendar)
+		b.sendText(chatID, i18n.T(lang, "calendars.name_prompt"))
+		return
+	}
+
+	calendarID, err := cb.Int64(0)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID календаря: %v", err)
+		return
+	}
+
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err == nil && user == nil {
+		err = db.ErrAccessDenied
+	}
+	var calendar *models.Calendar
+	if err == nil {
+		calendar, err = b.DB.GetCalendarForUser(calendarID, user.ID)
+	}
+	if err != nil {
+		log.Printf("Ошибка при получении календаря %d: %v", calendarID, err)
+		b.sendText(chatID, calendarErrorText(lang, err))
+		return
+	}
+
+	switch cb.Action {
+	case actCalendar:
+		b.showCalendar(chatID, user, calendar, lang)
+
+	case actCalendarAdd:
+		if !db.CanEditCalendar(calendar.Role) {
+			b.sendText(chatID, i18n.T(lang, "calendars.not_found"))
+			return
+		}
+		// Добавление события начинается как обычно, но событие попадет в календарь
+		b.ResetUserState(telegramID)
+		b.SetUserState(telegramID, models.StateAddEventTitle)
+		b.SaveUserData(telegramID, "calendar_id", calendar.ID)
+		b.sendText(chatID, i18n.T(lang, "calendars.add_prompt", calendar.Name)+"\n\n"+i18n.T(lang, "add.title_prompt"))
+
+	case actCalendarInvite:
+		role := cb.Arg(1)
+		code, err := b.DB.CreateCalendarInvite(calendar.ID, user.ID, role)
+		if err != nil {
+			log.Printf("Ошибка при создании приглашения: %v", err)
+			b.sendText(chatID, calendarErrorText(lang, err))
+			return
+		}
+
+		link := fmt.Sprintf("https://t.me/%s?start=%s", b.API.Self.UserName, code)
+		b.sendText(chatID, i18n.T(lang, "calendars.invite", calendar.Name, roleLabel(lang, role), link))
+
+	case actCalendarRole:
+		memberID, err := cb.Int64(1)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID участника: %v", err)
+			return
+		}
+		if err := b.DB.SetCalendarMemberRole(calendar.ID, user.ID, memberID, cb.Arg(2)); err != nil {
+			log.Printf("Ошибка при изменении роли участника: %v", err)
+			b.sendText(chatID, calendarErrorText(lang, err))
+			return
+		}
+		b.showCalendar(chatID, user, calendar, lang)
+
+	case actCalendarRemove:
+		memberID, err := cb.Int64(1)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID участника: %v", err)
+			return
+		}
+		if err := b.DB.RemoveCalendarMember(calendar.ID, user.ID, memberID); err != nil {
+			log.Printf("Ошибка при исключении участника календаря: %v", err)
+			b.sendText(chatID, calendarErrorText(lang, err))
+			return
+		}
+
+		if memberID == user.ID {
+			b.sendText(chatID, i18n.T(lang, "calendars.left", calendar.Name))
+			b.showCalendars(chatID, telegramID, lang)
+			return
+		}
+		b.showCalendar(chatID, user, calendar, lang)
+
+	case actCalendarDelete:
+		// Первое нажатие запрашивает подтверждение
+		if cb.Arg(1) == "" {
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					b.button(i18n.T(lang, "delete.confirm"), actCalendarDelete, calendar.ID, 1),
+					b.button(i18n.T(lang, "delete.cancel"), actCalendar, calendar.ID),
+				),
+			)
+
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "calendars.delete_prompt", calendar.Name))
+			msg.ReplyMarkup = keyboard
+			b.API.Send(msg)
+			return
+		}
+
+		if err := b.DB.DeleteCalendar(calendar.ID, user.ID); err != nil {
+			log.Printf("Ошибка при удалении календаря: %v", err)
+			b.sendText(chatID, calendarErrorText(lang, err))
+			return
+		}
+		b.sendText(chatID, i18n.T(lang, "calendars.deleted", calendar.Name))
+		b.showCalendars(chatID, telegramID, lang)
+	}
+}
+
+// handleNewCalendarInput создает общий календарь с введенным названием
+func (b *Bot) handleNewCalendarInput(chatID, telegramID int64, text string, lang string) {
+	name := strings.TrimSpace(text)
+	if name == "" || utf8.RuneCountInString(name) > maxCalendarName {
+		b.sendText(chatID, i18n.T(lang, "error.calendar_name"))
+		return
+	}
+
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+		b.ResetUserState(telegramID)
+		return
+	}
+
+	calendarID, err := b.DB.CreateCalendar(name, user.ID)
+	if err == nil {
+		var calendar *models.Calendar
+		calendar, err = b.DB.GetCalendarForUser(calendarID, user.ID)
+		if err == nil {
+			b.ResetUserState(telegramID)
+			b.sendText(chatID, i18n.T(lang, "calendars.created", calendar.Name))
+			b.showCalendar(chatID, user, calendar, lang)
+			return
+		}
+	}
+
+	log.Printf("Ошибка при создании календаря: %v", err)
+	b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+	b.ResetUserState(telegramID)
+}
+
+// joinCalendar добавляет пользователя в календарь по коду из ссылки-приглашения
+func (b *Bot) joinCalendar(chatID, userID int64, code string, lang string) {
+	calendar, joined, err := b.DB.JoinCalendar(code, userID)
+	if err != nil {
+		if !errors.Is(err, db.ErrInviteNotFound) {
+			log.Printf("Ошибка при вступлении в календарь: %v", err)
+		}
+		b.sendText(chatID, i18n.T(lang, "calendars.invite_invalid"))
+		return
+	}
+
+	if !joined {
+		b.sendText(chatID, i18n.T(lang, "calendars.already_member", calendar.Name, roleLabel(lang, calendar.Role)))
+		return
+	}
+	b.sendText(chatID, i18n.T(lang, "calendars.joined", calendar.Name, roleLabel(lang, calendar.Role)))
+}
+
+// sendCalendarChoice предлагает перенести событие в личный список или в
+// общий календарь, куда пользователь может добавлять события
+func (b *Bot) sendCalendarChoice(chatID, telegramID int64, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	var calendars []*models.Calendar
+	if err == nil && user != nil {
+		calendars, err = b.DB.GetCalendarsByUserID(user.ID)
+	}
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении календарей: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+		b.ResetUserState(telegramID)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "calendars.personal"), actSetCalendar, 0),
+		),
+	)
+	for _, calendar := range calendars {
+		if !db.CanEditCalendar(calendar.Role) {
+			continue
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button("📒 "+calendar.Name, actSetCalendar, calendar.ID),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "edit.calendar_prompt"))
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// moveEvent переносит событие в календарь calendarID (0 — личный список автора)
+// и показывает обновленную карточку события
+func (b *Bot) moveEvent(chatID, telegramID, eventID, calendarID int64, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err == nil && user == nil {
+		err = db.ErrAccessDenied
+	}
+	if err == nil {
+		err = b.DB.MoveEventForUser(eventID, user.ID, calendarID)
+	}
+	if err != nil {
+		log.Printf("Ошибка при переносе события %d: %v", eventID, err)
+		if errors.Is(err, db.ErrEventNotFound) {
+			b.sendText(chatID, i18n.T(lang, "error.event_not_found"))
+			return
+		}
+		b.sendText(chatID, calendarErrorText(lang, err))
+		return
+	}
+
+	b.sendText(chatID, i18n.T(lang, "calendars.moved"))
+	b.showEvent(chatID, telegramID, eventID, lang)
+}
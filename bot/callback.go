@@ -0,0 +1,269 @@
+package bot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Действия inline-кнопок. Коды короткие, чтобы уложиться в 64 байта callback_data.
+const (
+	actMenu                   = "m"
+	actAddEvent               = "a"
+	actListEvents             = "l"
+	actSettings               = "s"
+	actHelp                   = "h"
+	actSetNotifyTime          = "nt"
+	actType                   = "t"
+	actEvent                  = "e"
+	actEdit                   = "ed"
+	actEditField              = "ef"
+	actSetType                = "st"
+	actDelete                 = "d"
+	actConfirmDelete          = "cd"
+	actRestore                = "r"
+	actTrash                  = "tr"
+	actHistory                = "hi"
+	actRevert                 = "rv"
+	actConfirmDeleteMe        = "dm"
+	actCancelDeleteMe         = "cm"
+	actLanguage               = "lg"
+	actSetLanguage            = "sl"
+	actNotifyDefault          = "nd"
+	actSearch                 = "sr"
+	actListPage               = "lp"
+	actNoop                   = "x"
+	actAgenda                 = "ag"
+	actDigest                 = "dg"
+	actSnooze                 = "sz"
+	actAcknowledge            = "ak"
+	actSetPriority            = "pr"
+	actSetRule                = "ru"
+	actSetCalendar            = "mv"
+	actCalendars              = "cl"
+	actCalendar               = "cc"
+	actNewCalendar            = "cn"
+	actCalendarAdd            = "ca"
+	actCalendarInvite         = "ci"
+	actCalendarRole           = "cr"
+	actCalendarRemove         = "ck"
+	actCalendarDelete         = "cx"
+	actQuietHours             = "qh"
+	actDND                    = "dn"
+	actEventTypes             = "ts"
+	actEventType              = "tc"
+	actNewEventType           = "tn"
+	actEventTypeField         = "tf"
+	actDeleteEventType        = "td"
+	actConfirmDeleteEventType = "tx"
+	actShare                  = "sh"
+	actUnsubscribe            = "us"
+	actSubscriptionNotify     = "sn"
+	actGreetings              = "gr"
+	actGreeting               = "gc"
+	actNewGreeting            = "gn"
+	actGreetingLibrary        = "gl"
+	actDeleteGreeting         = "gd"
+	actChooseGreeting         = "gs"
+	actSetGreeting            = "sg"
+	actGifts                  = "gf"
+	actGift                   = "gi"
+	actNewGift                = "ga"
+	actGiftStatus             = "gt"
+	actDeleteGift             = "gx"
+	actAttachments            = "at"
+	actAttach                 = "aa"
+	actDeleteAttachment       = "ad"
+	actTasks                  = "ko"
+	actNewTask                = "kn"
+	actToggleTask             = "kt"
+	actDeleteTask             = "kd"
+)
+
+const (
+	// maxCallbackData ограничение Telegram на длину callback_data в байтах
+	maxCallbackData = 64
+	// callbackSigLen длина подписи в символах base64url (48 бит HMAC)
+	callbackSigLen = 8
+	// callbackSep разделитель полей внутри callback_data
+	callbackSep = ":"
+	// storedPrefix отмечает данные, сохраненные на стороне сервера
+	storedPrefix = "~"
+)
+
+var (
+	// ErrCallbackMalformed данные кнопки не удалось разобрать
+	ErrCallbackMalformed = errors.New("неверный формат данных кнопки")
+	// ErrCallbackTampered подпись данных кнопки не совпадает
+	ErrCallbackTampered = errors.New("неверная подпись данных кнопки")
+	// ErrCallbackStale кнопка устарела
+	ErrCallbackStale = errors.New("кнопка устарела")
+)
+
+// PayloadStore хранит данные кнопок, которые не помещаются в callback_data
+type PayloadStore interface {
+	SaveCallbackPayload(payload string) (int64, error)
+	GetCallbackPayload(id int64) (string, error)
+}
+
+// Callback разобранные данные нажатой кнопки
+type Callback struct {
+	Action string
+	Args   []string
+}
+
+// Arg возвращает строковый аргумент по индексу или пустую строку
+func (c *Callback) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+// Int64 возвращает числовой аргумент по индексу
+func (c *Callback) Int64(i int) (int64, error) {
+	if i < 0 || i >= len(c.Args) {
+		return 0, fmt.Errorf("%w: нет аргумента %d", ErrCallbackMalformed, i)
+	}
+
+	value, err := strconv.ParseInt(c.Args[i], 36, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrCallbackMalformed, err)
+	}
+	return value, nil
+}
+
+// CallbackCodec упаковывает и подписывает данные inline-кнопок.
+//
+// Формат callback_data: <подпись><время>:<действие>[:<аргумент>...], где время —
+// минуты Unix в base36, а числа в аргументах тоже записываются в base36.
+// Если данные длиннее 64 байт или аргумент содержит разделитель, они
+// сохраняются в PayloadStore, а в кнопку попадает подписанная ссылка ~<id>.
+type CallbackCodec struct {
+	secret []byte
+	ttl    time.Duration
+	store  PayloadStore
+	now    func() time.Time
+}
+
+// NewCallbackCodec создает кодек с секретом для HMAC и сроком жизни кнопок
+func NewCallbackCodec(secret []byte, ttl time.Duration, store PayloadStore) *CallbackCodec {
+	return &CallbackCodec{
+		secret: secret,
+		ttl:    ttl,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// Encode упаковывает действие и аргументы в callback_data.
+// Аргументы типа int и int64 кодируются в base36, остальные — как строки.
+func (c *CallbackCodec) Encode(action string, args ...interface{}) string {
+	fields := []string{strconv.FormatInt(c.now().Unix()/60, 36), action}
+	needStore := false
+
+	for _, arg := range args {
+		var field string
+		switch v := arg.(type) {
+		case int64:
+			field = strconv.FormatInt(v, 36)
+		case int:
+			field = strconv.FormatInt(int64(v), 36)
+		default:
+			field = fmt.Sprint(v)
+		}
+
+		if strings.Contains(field, callbackSep) {
+			needStore = true
+		}
+		fields = append(fields, field)
+	}
+
+	body := strings.Join(fields, callbackSep)
+	if !needStore && callbackSigLen+len(body) <= maxCallbackData {
+		return c.sign(body) + body
+	}
+
+	// Данные не помещаются в кнопку: сохраняем их на сервере в JSON,
+	// чтобы аргументы могли содержать разделитель
+	payload, err := json.Marshal(fields)
+	var id int64
+	if err == nil {
+		id, err = c.store.SaveCallbackPayload(string(payload))
+	}
+	if err != nil {
+		log.Printf("Ошибка при сохранении данных кнопки: %v", err)
+		fallback := strings.Join([]string{fields[0], actMenu}, callbackSep)
+		return c.sign(fallback) + fallback
+	}
+
+	ref := storedPrefix + strconv.FormatInt(id, 36)
+	return c.sign(ref) + ref
+}
+
+// Decode проверяет подпись и срок жизни callback_data и разбирает их
+func (c *CallbackCodec) Decode(data string) (*Callback, error) {
+	if len(data) <= callbackSigLen {
+		return nil, ErrCallbackMalformed
+	}
+
+	sig, body := data[:callbackSigLen], data[callbackSigLen:]
+	if !hmac.Equal([]byte(sig), []byte(c.sign(body))) {
+		return nil, ErrCallbackTampered
+	}
+
+	var fields []string
+	if strings.HasPrefix(body, storedPrefix) {
+		id, err := strconv.ParseInt(strings.TrimPrefix(body, storedPrefix), 36, 64)
+		if err != nil {
+			return nil, ErrCallbackMalformed
+		}
+
+		payload, err := c.store.GetCallbackPayload(id)
+		if err != nil {
+			return nil, err
+		}
+		if payload == "" {
+			// Сохраненные данные уже удалены при очистке
+			return nil, ErrCallbackStale
+		}
+		if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+			return nil, ErrCallbackMalformed
+		}
+	} else {
+		fields = strings.Split(body, callbackSep)
+	}
+
+	if len(fields) < 2 || fields[1] == "" {
+		return nil, ErrCallbackMalformed
+	}
+
+	minutes, err := strconv.ParseInt(fields[0], 36, 64)
+	if err != nil {
+		return nil, ErrCallbackMalformed
+	}
+
+	issuedAt := time.Unix(minutes*60, 0)
+	if c.ttl > 0 && c.now().Sub(issuedAt) > c.ttl {
+		return nil, ErrCallbackStale
+	}
+
+	return &Callback{
+		Action: fields[1],
+		Args:   fields[2:],
+	}, nil
+}
+
+// sign вычисляет укороченную HMAC-подпись данных
+func (c *CallbackCodec) sign(body string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))[:callbackSigLen]
+}
@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memoryStore хранит данные кнопок в памяти
+type memoryStore struct {
+	payloads []string
+}
+
+func (s *memoryStore) SaveCallbackPayload(payload string) (int64, error) {
+	s.payloads = append(s.payloads, payload)
+	return int64(len(s.payloads)), nil
+}
+
+func (s *memoryStore) GetCallbackPayload(id int64) (string, error) {
+	if id < 1 || id > int64(len(s.payloads)) {
+		return "", nil
+	}
+	return s.payloads[id-1], nil
+}
+
+// newTestCodec создает кодек с фиксированным временем now
+func newTestCodec(store PayloadStore, now time.Time) *CallbackCodec {
+	codec := NewCallbackCodec([]byte("secret"), 24*time.Hour, store)
+	codec.now = func() time.Time { return now }
+	return codec
+}
+
+func TestCallbackRoundTrip(t *testing.T) {
+	now := time.Date(2026, time.May, 12, 9, 0, 0, 0, time.UTC)
+	store := &memoryStore{}
+	codec := newTestCodec(store, now)
+
+	data := codec.Encode(actSnooze, int64(123456789), "2026-05-15", 3)
+	if len(data) > maxCallbackData {
+		t.Fatalf("callback_data длиной %d байт", len(data))
+	}
+	if len(store.payloads) != 0 {
+		t.Errorf("короткие данные сохранены на сервере")
+	}
+
+	cb, err := codec.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if cb.Action != actSnooze {
+		t.Errorf("действие %q, ожидалось %q", cb.Action, actSnooze)
+	}
+	if id, err := cb.Int64(0); err != nil || id != 123456789 {
+		t.Errorf("Int64(0) = %d, %v", id, err)
+	}
+	if cb.Arg(1) != "2026-05-15" {
+		t.Errorf("Arg(1) = %q", cb.Arg(1))
+	}
+	if n, err := cb.Int64(2); err != nil || n != 3 {
+		t.Errorf("Int64(2) = %d, %v", n, err)
+	}
+	if _, err := cb.Int64(3); !errors.Is(err, ErrCallbackMalformed) {
+		t.Errorf("Int64(3): ожидалась ошибка формата, получено: %v", err)
+	}
+}
+
+func TestCallbackTampered(t *testing.T) {
+	now := time.Date(2026, time.May, 12, 9, 0, 0, 0, time.UTC)
+	codec := newTestCodec(&memoryStore{}, now)
+	data := codec.Encode(actEvent, int64(42))
+
+	// Подменяем ID события, оставляя прежнюю подпись
+	forged := data[:len(data)-1] + "z"
+	if _, err := codec.Decode(forged); !errors.Is(err, ErrCallbackTampered) {
+		t.Errorf("подмененные данные: ожидалась ошибка подписи, получено: %v", err)
+	}
+
+	// Подпись другим секретом тоже отклоняется
+	other := NewCallbackCodec([]byte("other"), 24*time.Hour, &memoryStore{})
+	other.now = codec.now
+	if _, err := codec.Decode(other.Encode(actEvent, int64(42))); !errors.Is(err, ErrCallbackTampered) {
+		t.Errorf("чужая подпись: ожидалась ошибка подписи, получено: %v", err)
+	}
+
+	if _, err := codec.Decode("short"); !errors.Is(err, ErrCallbackMalformed) {
+		t.Errorf("короткие данные: ожидалась ошибка формата, получено: %v", err)
+	}
+}
+
+func TestCallbackStale(t *testing.T) {
+	issued := time.Date(2026, time.May, 12, 9, 0, 0, 0, time.UTC)
+	codec := newTestCodec(&memoryStore{}, issued)
+	data := codec.Encode(actEvent, int64(42))
+
+	codec.now = func() time.Time { return issued.Add(23 * time.Hour) }
+	if _, err := codec.Decode(data); err != nil {
+		t.Errorf("кнопка в пределах срока: %v", err)
+	}
+
+	codec.now = func() time.Time { return issued.Add(25 * time.Hour) }
+	if _, err := codec.Decode(data); !errors.Is(err, ErrCallbackStale) {
+		t.Errorf("ожидалась ошибка устаревшей кнопки, получено: %v", err)
+	}
+}
+
+func TestCallbackStoredPayload(t *testing.T) {
+	now := time.Date(2026, time.May, 12, 9, 0, 0, 0, time.UTC)
+	store := &memoryStore{}
+	codec := newTestCodec(store, now)
+
+	query := strings.Repeat("мама ", 20)
+	data := codec.Encode(actSearch, query, "time:15:00")
+	if len(data) > maxCallbackData {
+		t.Fatalf("callback_data длиной %d байт", len(data))
+	}
+	if len(store.payloads) != 1 {
+		t.Fatalf("на сервере сохранено %d записей, ожидалась одна", len(store.payloads))
+	}
+
+	cb, err := codec.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if cb.Action != actSearch || cb.Arg(0) != query || cb.Arg(1) != "time:15:00" {
+		t.Errorf("получено %q %q", cb.Action, cb.Args)
+	}
+
+	// Ссылку на сохраненные данные тоже нельзя подменить
+	forged := data[:len(data)-1] + "2"
+	if _, err := codec.Decode(forged); !errors.Is(err, ErrCallbackTampered) {
+		t.Errorf("подмененная ссылка: ожидалась ошибка подписи, получено: %v", err)
+	}
+
+	// Данные, удаленные при очистке, считаются устаревшими
+	store.payloads = nil
+	if _, err := codec.Decode(data); !errors.Is(err, ErrCallbackStale) {
+		t.Errorf("удаленные данные: ожидалась ошибка устаревшей кнопки, получено: %v", err)
+	}
+}
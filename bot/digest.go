@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Настройки сводки, которые переключаются кнопками
+const (
+	digestDaily  = "daily"
+	digestWeekly = "weekly"
+)
+
+// sendDigests отправляет сводки пользователям, у которых время уведомлений
+// совпадает с now. Еженедельный обзор отправляется по понедельникам.
+func (b *Bot) sendDigests(now time.Time) error {
+	users, err := b.DB.GetUsersForNotification(now.Format("15:04"))
+	if err != nil {
+		return fmt.Errorf("ошибка при получении пользователей для сводки: %w", err)
+	}
+
+	for _, user := range users {
+		weekly := user.WeeklyDigest && now.Weekday() == time.Monday
+		if !user.DailyDigest && !weekly {
+			continue
+		}
+
+		if err := b.sendDigest(user, now, user.DailyDigest, weekly); err != nil {
+			log.Printf("Ошибка при отправке сводки пользователю %d: %v", user.ID, err)
+		}
+		if user.DailyDigest {
+			b.sendDigestTaskReminders(user, now)
+		}
+	}
+	return nil
+}
+
+// sendDigest собирает и отправляет пользователю одно сообщение: события
+// сегодня и в пределах срока напоминания каждого события, а при weekly —
+// обзор событий на ближайшие 7 дней. Пустая сводка не отправляется.
+func (b *Bot) sendDigest(user *models.User, now time.Time, daily, weekly bool) error {
+	lang := i18n.Normalize(user.Language)
+
+	events, err := b.DB.GetEventsByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Подписчику напоминается за его собственный срок, если он его задал
+	notifyDays, err := b.DB.GetSubscriptionsNotifyDays(user.ID)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if days, ok := notifyDays[event.ID]; ok {
+			event.NotifyDays = days
+		}
+	}
+
+	occurrences := upcomingOccurrences(events, now)
+	typeLabels := b.typeLabels(user.ID, lang)
+
+	line := func(occ occurrence) string {
+		typeText, ok := typeLabels[occ.event.TypeID]
+		if !ok {
+			typeText = i18n.EventType(lang, occ.event.Type)
+		}
+		return i18n.T(lang, "agenda.line",
+			i18n.FormatDate(lang, occ.date.Format("2006-01-02")),
+			titleWithMilestone(lang, occ.event, occ.date),
+			typeText,
+			formatDaysLeft(lang, occ.daysLeft))
+	}
+
+	var sections []string
+	var reminded []occurrence
+
+	if daily {
+		var today, upcoming []string
+		for _, occ := range occurrences {
+			if occ.daysLeft > occ.event.NotifyDays {
+				continue
+			}
+			occurrence := occurrenceDate(now, occ.daysLeft)
+			if b.isAcknowledged(user.ID, occ.event.ID, occurrence) {
+				continue
+			}
+
+			// В тихие часы и во время паузы обычные события напоминаются позже отдельно
+			if until, ok := deferUntil(user, now); ok && !isUrgent(occ.event) {
+				if err := b.DB.SnoozeReminder(user.ID, occ.event.ID, occurrence, until); err != nil {
+					log.Printf("Ошибка при переносе уведомления для события %d: %v", occ.event.ID, err)
+				}
+				continue
+			}
+
+			if occ.daysLeft == 0 {
+				today = append(today, line(occ))
+			} else {
+				upcoming = append(upcoming, line(occ))
+			}
+			reminded = append(reminded, occ)
+		}
+
+		if len(today) > 0 {
+			sections = append(sections, i18n.T(lang, "digest.today")+"\n"+strings.Join(today, "\n"))
+		}
+		if len(upcoming) > 0 {
+			sections = append(sections, i18n.T(lang, "digest.upcoming")+"\n"+strings.Join(upcoming, "\n"))
+		}
+	}
+
+	if weekly {
+		var week []string
+		for _, occ := range occurrences {
+			if occ.daysLeft > 7 {
+				break
+			}
+			week = append(week, line(occ))
+		}
+		if len(week) > 0 {
+			sections = append(sections, i18n.T(lang, "digest.week")+"\n"+strings.Join(week, "\n"))
+		}
+	}
+
+	if len(sections) == 0 {
+		return nil
+	}
+
+	// Кнопки для каждого события, о котором напоминает сводка
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, occ := range reminded {
+		occurrence := occurrenceDate(now, occ.daysLeft)
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button("✅ "+occ.event.Title, actAcknowledge, occ.event.ID, occurrence),
+			b.button(i18n.T(lang, "notify.snooze_tomorrow"), actSnooze, occ.event.ID, occurrence, snoozeTomorrow),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(user.TelegramID, i18n.T(lang, "digest.title")+"\n\n"+strings.Join(sections, "\n\n"))
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	if _, err := b.API.Send(msg); err != nil {
+		return fmt.Errorf("ошибка при отправке сводки: %w", err)
+	}
+
+	for _, occ := range reminded {
+		if err := b.DB.LogNotification(user.ID, occ.event, occ.daysLeft); err != nil {
+			log.Printf("Ошибка при сохранении истории уведомлений: %v", err)
+		}
+		b.startNagging(user, occ.event, occurrenceDate(now, occ.daysLeft), now)
+	}
+	return nil
+}
+
+// toggleDigest включает или выключает ежедневную или еженедельную сводку
+func (b *Bot) toggleDigest(chatID, telegramID int64, kind string, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.update_settings"))
+		return
+	}
+
+	daily, weekly := user.DailyDigest, user.WeeklyDigest
+	switch kind {
+	case digestDaily:
+		daily = !daily
+	case digestWeekly:
+		weekly = !weekly
+	}
+
+	if err := b.DB.SetUserDigest(user.ID, daily, weekly); err != nil {
+		log.Printf("Ошибка при сохранении настроек сводки: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+		return
+	}
+
+	b.showSettings(chatID, telegramID, lang)
+}
+
+// digestStatus возвращает «вкл» или «выкл» для настроек сводки
+func digestStatus(lang string, enabled bool) string {
+	if enabled {
+		return i18n.T(lang, "settings.on")
+	}
+	return i18n.T(lang, "settings.off")
+}
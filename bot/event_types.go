@@ -0,0 +1,344 @@
+package bot
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// resetValue сбрасывает необязательное поле типа события к значению по умолчанию
+const resetValue = "-"
+
+// eventTypeLabel возвращает название типа события со значком
+func eventTypeLabel(lang string, eventType *models.EventType) string {
+	name := eventType.Name
+	if name == "" {
+		name = i18n.EventType(lang, eventType.Kind)
+	}
+	if eventType.Emoji != "" {
+		return eventType.Emoji + " " + name
+	}
+	return name
+}
+
+// typeLabel возвращает название типа события на языке пользователя.
+// Если тип удален, используется название встроенного типа kind.
+func (b *Bot) typeLabel(lang string, typeID int64, kind string) string {
+	if typeID != 0 {
+		eventType, err := b.DB.GetEventType(typeID)
+		if err != nil {
+			log.Printf("Ошибка при получении типа события %d: %v", typeID, err)
+		}
+		if eventType != nil {
+			return eventTypeLabel(lang, eventType)
+		}
+	}
+	return i18n.EventType(lang, kind)
+}
+
+// typeLabels возвращает названия типов событий пользователя по их ID
+func (b *Bot) typeLabels(userID int64, lang string) map[int64]string {
+	eventTypes, err := b.DB.GetEventTypesByUserID(userID)
+	if err != nil {
+		log.Printf("Ошибка при получении типов событий: %v", err)
+	}
+
+	labels := make(map[int64]string, len(eventTypes))
+	for _, eventType := range eventTypes {
+		labels[eventType.ID] = eventTypeLabel(lang, eventType)
+	}
+	return labels
+}
+
+// eventTypeKeyboard создает клавиатуру выбора из типов событий пользователя
+func (b *Bot) eventTypeKeyboard(lang string, userID int64, action string) tgbotapi.InlineKeyboardMarkup {
+	eventTypes, err := b.DB.GetEventTypesByUserID(userID)
+	if err != nil {
+		log.Printf("Ошибка при получении типов событий: %v", err)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, eventType := range eventTypes {
+		row := tgbotapi.NewInlineKeyboardRow(
+			b.button(eventTypeLabel(lang, eventType), action, eventType.ID),
+		)
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+	return keyboard
+}
+
+// loadEventType получает тип события пользователя Telegram и сообщает об ошибке в чат
+func (b *Bot) loadEventType(chatID, telegramID, typeID int64, lang string) (*models.User, *models.EventType) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.event_type_not_found"))
+		return nil, nil
+	}
+
+	eventType, err := b.DB.GetEventTypeForUser(typeID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении типа события: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.event_type_not_found"))
+		return nil, nil
+	}
+	return user, eventType
+}
+
+// showEventTypes показывает список типов событий пользователя
+func (b *Bot) showEventTypes(chatID, telegramID int64, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	keyboard := b.eventTypeKeyboard(lang, user.ID, actEventType)
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard,
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "types.new"), actNewEventType),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.back"), actSettings),
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "types.title"))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// showEventType показывает карточку типа события с кнопками редактирования
+func (b *Bot) showEventType(chatID int64, user *models.User, eventType *models.EventType, lang string) {
+	notificationTime := i18n.T(lang, "types.time_default", user.NotificationTime)
+	if eventType.NotificationTime != "" {
+		notificationTime = eventType.NotificationTime
+	}
+
+	text := i18n.T(lang, "types.card",
+		eventTypeLabel(lang, eventType),
+		i18n.N(lang, eventType.NotifyDays, "days"),
+		notificationTime)
+	if eventType.GreetingID != 0 {
+		text += i18n.T(lang, "types.greeting")
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "types.edit_name"), actEventTypeField, eventType.ID, "name"),
+			b.button(i18n.T(lang, "types.edit_emoji"), actEventTypeField, eventType.ID, "emoji"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "types.edit_notify_days"), actEventTypeField, eventType.ID, "notify_days"),
+			b.button(i18n.T(lang, "types.edit_time"), actEventTypeField, eventType.ID, "time"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "types.edit_greeting"), actChooseGreeting, greetingTargetType, eventType.ID),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "event.delete"), actDeleteEventType, eventType.ID),
+			b.button(i18n.T(lang, "button.back"), actEventTypes),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// handleEventTypeCallback обрабатывает кнопки раздела типов событий
+func (b *Bot) handleEventTypeCallback(chatID, userID int64, cb *Callback, lang string) {
+	switch cb.Action {
+	case actEventTypes:
+		b.ResetUserState(userID)
+		b.showEventTypes(chatID, userID, lang)
+
+	case actNewEventType:
+		b.SetUserState(userID, models.StateNewEventType)
+		b.sendText(chatID, i18n.T(lang, "types.name_prompt"))
+
+	case actEventType, actEventTypeField, actDeleteEventType, actConfirmDeleteEventType:
+		typeID, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID типа события: %v", err)
+			return
+		}
+
+		user, eventType := b.loadEventType(chatID, userID, typeID, lang)
+		if eventType == nil {
+			return
+		}
+
+		switch cb.Action {
+		case actEventType:
+			b.showEventType(chatID, user, eventType, lang)
+
+		case actEventTypeField:
+			field := cb.Arg(1)
+			b.SaveUserData(userID, "type_id", eventType.ID)
+			b.SaveUserData(userID, "field", field)
+			b.SetUserState(userID, models.StateEditEventType)
+			b.sendText(chatID, i18n.T(lang, "types.prompt_"+field))
+
+		case actDeleteEventType:
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					b.button(i18n.T(lang, "delete.confirm"), actConfirmDeleteEventType, eventType.ID),
+					b.button(i18n.T(lang, "delete.cancel"), actEventType, eventType.ID),
+				),
+			)
+
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "types.delete_prompt", eventTypeLabel(lang, eventType)))
+			msg.ReplyMarkup = keyboard
+			b.API.Send(msg)
+
+		case actConfirmDeleteEventType:
+			eventTypes, err := b.DB.GetEventTypesByUserID(user.ID)
+			if err != nil {
+				log.Printf("Ошибка при получении типов событий: %v", err)
+				b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+				return
+			}
+			if len(eventTypes) <= 1 {
+				b.sendText(chatID, i18n.T(lang, "types.delete_last"))
+				return
+			}
+
+			if err := b.DB.DeleteEventType(eventType.ID, user.ID); err != nil {
+				log.Printf("Ошибка при удалении типа события: %v", err)
+				b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+				return
+			}
+
+			b.sendText(chatID, i18n.T(lang, "types.deleted"))
+			b.showEventTypes(chatID, userID, lang)
+		}
+	}
+}
+
+// handleEventTypeInput обрабатывает ввод при создании и изменении типа события
+func (b *Bot) handleEventTypeInput(message *tgbotapi.Message, userState *models.UserState, lang string) {
+	userID := accountID(message.Chat, message.From)
+	chatID := message.Chat.ID
+	text := strings.TrimSpace(message.Text)
+
+	if userState.State == models.StateNewEventType {
+		if text == "" || utf8.RuneCountInString(text) > 32 {
+			b.sendText(chatID, i18n.T(lang, "error.type_name"))
+			return
+		}
+
+		user, err := b.DB.GetUserByTelegramID(userID)
+		if err != nil || user == nil {
+			log.Printf("Ошибка при получении пользователя: %v", err)
+			b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+			b.ResetUserState(userID)
+			return
+		}
+
+		eventType := &models.EventType{
+			UserID:     user.ID,
+			Kind:       models.EventTypeOther,
+			Name:       text,
+			NotifyDays: 1,
+		}
+		eventType.ID, err = b.DB.CreateEventType(eventType)
+		if err != nil {
+			log.Printf("Ошибка при создании типа события: %v", err)
+			b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+			b.ResetUserState(userID)
+			return
+		}
+
+		b.ResetUserState(userID)
+		b.showEventType(chatID, user, eventType, lang)
+		return
+	}
+
+	// Изменение поля существующего типа
+	field, _ := userState.CurrentData["field"].(string)
+	typeID, _ := userState.CurrentData["type_id"].(int64)
+
+	user, eventType := b.loadEventType(chatID, userID, typeID, lang)
+	if eventType == nil {
+		b.ResetUserState(userID)
+		return
+	}
+
+	switch field {
+	case "name":
+		if text == resetValue {
+			text = ""
+		}
+		// Без названия используется название встроенного типа
+		if utf8.RuneCountInString(text) > 32 {
+			b.sendText(chatID, i18n.T(lang, "error.type_name"))
+			return
+		}
+		eventType.Name = text
+
+	case "emoji":
+		if text == resetValue {
+			text = ""
+		}
+		if utf8.RuneCountInString(text) > 4 {
+			b.sendText(chatID, i18n.T(lang, "error.type_emoji"))
+			return
+		}
+		eventType.Emoji = text
+
+	case "notify_days":
+		days, err := strconv.Atoi(text)
+		if err != nil || days < 1 || days > 30 {
+			b.sendText(chatID, i18n.T(lang, "error.notify_days"))
+			return
+		}
+		eventType.NotifyDays = days
+
+	case "time":
+		if text == resetValue {
+			eventType.NotificationTime = ""
+			break
+		}
+		formattedTime, err := utils.ValidateTime(text)
+		if err != nil {
+			b.sendText(chatID, i18n.T(lang, "error.retry_time", inputErrorText(lang, err)))
+			return
+		}
+		eventType.NotificationTime = formattedTime
+	}
+
+	if err := b.DB.UpdateEventType(eventType); err != nil {
+		log.Printf("Ошибка при обновлении типа события: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+		b.ResetUserState(userID)
+		return
+	}
+
+	b.ResetUserState(userID)
+	b.showEventType(chatID, user, eventType, lang)
+}
+
+// sendNotifyPrompt спрашивает, за сколько дней напоминать, и предлагает
+// значение по умолчанию из выбранного типа события
+func (b *Bot) sendNotifyPrompt(chatID int64, userState *models.UserState, lang string) {
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "add.notify_prompt"))
+	if days, ok := userState.CurrentData["type_notify_days"].(int); ok && days > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "add.notify_default", i18n.N(lang, days, "days")), actNotifyDefault, days),
+			),
+		)
+	}
+	b.API.Send(msg)
+}
@@ -0,0 +1,292 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxGiftTitle ограничение длины названия подарка в символах
+const maxGiftTitle = 100
+
+// giftStatusEmoji значки статусов идей подарков
+var giftStatusEmoji = map[string]string{
+	models.GiftStatusIdea:   "💡",
+	models.GiftStatusBought: "🛒",
+	models.GiftStatusGiven:  "🎁",
+}
+
+// giftStatuses порядок кнопок смены статуса подарка
+var giftStatuses = []string{models.GiftStatusIdea, models.GiftStatusBought, models.GiftStatusGiven}
+
+// parseGiftInput разбирает идею подарка «Название; цена; ссылка». Цена и
+// ссылка необязательны, ссылка узнается по http:// или https://.
+func parseGiftInput(text string) *models.Gift {
+	gift := &models.Gift{}
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' }) {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+		case strings.HasPrefix(part, "http://") || strings.HasPrefix(part, "https://"):
+			gift.Link = part
+		case gift.Title == "":
+			gift.Title = part
+		case gift.Price == "":
+			gift.Price = part
+		}
+	}
+	return gift
+}
+
+// formatGift возвращает строку подарка: значок статуса, название и цену
+func formatGift(gift *models.Gift) string {
+	line := giftStatusEmoji[gift.Status] + " " + gift.Title
+	if gift.Price != "" {
+		line += " — " + gift.Price
+	}
+	return line
+}
+
+// giftErrorText возвращает сообщение об ошибке действия с подарком
+func giftErrorText(lang string, err error) string {
+	if errors.Is(err, db.ErrEventNotFound) || errors.Is(err, db.ErrAccessDenied) {
+		return i18n.T(lang, "gifts.not_found")
+	}
+	return i18n.T(lang, "error.save_changes")
+}
+
+// showGifts показывает идеи подарков к событию и историю подаренного по годам
+func (b *Bot) showGifts(chatID, telegramID, eventID int64, lang string) {
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionView, lang)
+	if event == nil {
+		return
+	}
+
+	gifts, err := b.DB.GetGiftsForUser(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении идей подарков к событию %d: %v", event.ID, err)
+		b.sendText(chatID, giftErrorText(lang, err))
+		return
+	}
+
+	// Подписчик чужого события только просматривает подарки
+	subscribed, err := b.DB.IsEventSubscriber(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при проверке подписки на событие %d: %v", event.ID, err)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	var active []string
+	given := make(map[int][]string)
+	for _, gift := range gifts {
+		if gift.Status == models.GiftStatusGiven {
+			given[gift.Year] = append(given[gift.Year], gift.Title)
+			continue
+		}
+
+		active = append(active, formatGift(gift))
+		if !subscribed {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+				b.button(formatGift(gift), actGift, gift.ID),
+			))
+		}
+	}
+
+	text := i18n.T(lang, "gifts.title", event.Title)
+	if len(active) == 0 {
+		text += i18n.T(lang, "gifts.empty")
+	} else {
+		text += strings.Join(active, "\n")
+	}
+
+	// История по годам, от последнего к первому
+	if len(given) > 0 {
+		years := make([]int, 0, len(given))
+		for year := range given {
+			years = append(years, year)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(years)))
+
+		var history []string
+		for _, year := range years {
+			history = append(history, i18n.T(lang, "gifts.history_year", year, strings.Join(given[year], ", ")))
+		}
+		text += i18n.T(lang, "gifts.history", strings.Join(history, "\n"))
+	}
+
+	if !subscribed {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "gifts.add"), actNewGift, event.ID),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.back"), actEvent, event.ID),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	msg.DisableWebPagePreview = true
+	b.API.Send(msg)
+}
+
+// showGift показывает идею подарка с кнопками смены статуса и удаления
+func (b *Bot) showGift(chatID int64, gift *models.Gift, lang string) {
+	text := i18n.T(lang, "gifts.card", gift.Title, giftStatusEmoji[gift.Status]+" "+i18n.T(lang, "gifts.status_"+gift.Status))
+	if gift.Price != "" {
+		text += i18n.T(lang, "gifts.price", gift.Price)
+	}
+	if gift.Link != "" {
+		text += i18n.T(lang, "gifts.link", gift.Link)
+	}
+
+	var statusRow []tgbotapi.InlineKeyboardButton
+	for _, status := range giftStatuses {
+		if status == gift.Status {
+			continue
+		}
+		statusRow = append(statusRow, b.button(
+			giftStatusEmoji[status]+" "+i18n.T(lang, "gifts.status_"+status), actGiftStatus, gift.ID, status))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		statusRow,
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "event.delete"), actDeleteGift, gift.ID),
+			b.button(i18n.T(lang, "button.back"), actGifts, gift.EventID),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// handleGiftCallback обрабатывает кнопки раздела подарков
+func (b *Bot) handleGiftCallback(chatID, telegramID int64, cb *Callback, lang string) {
+	id, err := cb.Int64(0)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID: %v", err)
+		return
+	}
+
+	switch cb.Action {
+	case actGifts:
+		b.showGifts(chatID, telegramID, id, lang)
+		return
+
+	case actNewGift:
+		_, event := b.loadEvent(chatID, telegramID, id, db.PermissionEdit, lang)
+		if event == nil {
+			return
+		}
+		b.ResetUserState(telegramID)
+		b.SetUserState(telegramID, models.StateNewGift)
+		b.SaveUserData(telegramID, "event_id", event.ID)
+		b.sendText(chatID, i18n.T(lang, "gifts.prompt", event.Title))
+		return
+	}
+
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err == nil && user == nil {
+		err = db.ErrAccessDenied
+	}
+	var gift *models.Gift
+	if err == nil {
+		gift, err = b.DB.GetGiftForUser(id, user.ID, db.PermissionEdit)
+	}
+	if err != nil {
+		log.Printf("Ошибка при получении подарка %d: %v", id, err)
+		b.sendText(chatID, giftErrorText(lang, err))
+		return
+	}
+
+	switch cb.Action {
+	case actGift:
+		b.showGift(chatID, gift, lang)
+
+	case actGiftStatus:
+		if err := b.DB.SetGiftStatusForUser(gift.ID, user.ID, cb.Arg(1), time.Now()); err != nil {
+			log.Printf("Ошибка при изменении статуса подарка %d: %v", gift.ID, err)
+			b.sendText(chatID, giftErrorText(lang, err))
+			return
+		}
+		b.showGifts(chatID, telegramID, gift.EventID, lang)
+
+	case actDeleteGift:
+		if err := b.DB.DeleteGiftForUser(gift.ID, user.ID); err != nil {
+			log.Printf("Ошибка при удалении подарка %d: %v", gift.ID, err)
+			b.sendText(chatID, giftErrorText(lang, err))
+			return
+		}
+		b.sendText(chatID, i18n.T(lang, "gifts.deleted"))
+		b.showGifts(chatID, telegramID, gift.EventID, lang)
+	}
+}
+
+// handleNewGiftInput добавляет идею подарка к событию и предупреждает,
+// если такой подарок к этому событию уже дарили
+func (b *Bot) handleNewGiftInput(chatID, telegramID int64, userState *models.UserState, text string, lang string) {
+	gift := parseGiftInput(text)
+	if gift.Title == "" || utf8.RuneCountInString(gift.Title) > maxGiftTitle {
+		b.sendText(chatID, i18n.T(lang, "error.gift_title", maxGiftTitle))
+		return
+	}
+
+	eventID, _ := userState.CurrentData["event_id"].(int64)
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionEdit, lang)
+	if event == nil {
+		b.ResetUserState(telegramID)
+		return
+	}
+	gift.EventID = event.ID
+
+	givenYear, err := b.DB.GetGivenGiftYear(event.ID, gift.Title)
+	if err != nil {
+		log.Printf("Ошибка при проверке истории подарков: %v", err)
+	}
+
+	if _, err := b.DB.CreateGiftForUser(gift, user.ID); err != nil {
+		log.Printf("Ошибка при добавлении идеи подарка: %v", err)
+		b.sendText(chatID, giftErrorText(lang, err))
+		b.ResetUserState(telegramID)
+		return
+	}
+
+	b.ResetUserState(telegramID)
+	if givenYear != 0 {
+		b.sendText(chatID, i18n.T(lang, "gifts.already_given", gift.Title, givenYear))
+	}
+	b.showGifts(chatID, telegramID, event.ID, lang)
+}
+
+// giftIdeasText возвращает список еще не купленных идей подарков для
+// напоминания или пустую строку, если идей нет
+func (b *Bot) giftIdeasText(event *models.Event, lang string) string {
+	gifts, err := b.DB.GetGiftIdeas(event.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении идей подарков к событию %d: %v", event.ID, err)
+		return ""
+	}
+	if len(gifts) == 0 {
+		return ""
+	}
+
+	lines := make([]string, 0, len(gifts))
+	for _, gift := range gifts {
+		line := "• " + gift.Title
+		if gift.Price != "" {
+			line += fmt.Sprintf(" (%s)", gift.Price)
+		}
+		lines = append(lines, line)
+	}
+	return i18n.T(lang, "notify.gift_ideas", strings.Join(lines, "\n"))
+}
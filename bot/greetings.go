@@ -0,0 +1,328 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxGreetingText ограничение длины шаблона поздравления в символах
+	maxGreetingText = 1000
+	// greetingPreviewLen длина начала шаблона на кнопках
+	greetingPreviewLen = 40
+)
+
+// Подстановки в шаблонах поздравлений
+const (
+	greetingName          = "{name}"
+	greetingAge           = "{age}"
+	greetingYearsTogether = "{years_together}"
+)
+
+// Объекты, к которым привязывается шаблон поздравления
+const (
+	greetingTargetEvent = "e"
+	greetingTargetType  = "t"
+)
+
+// greetingLibrary возвращает встроенные шаблоны поздравлений на языке пользователя
+func greetingLibrary(lang string) []string {
+	var library []string
+	for i := 1; i18n.Has(lang, fmt.Sprintf("greetings.library_%d", i)); i++ {
+		library = append(library, i18n.T(lang, fmt.Sprintf("greetings.library_%d", i)))
+	}
+	return library
+}
+
+// greetingPreview возвращает начало шаблона для кнопки
+func greetingPreview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= greetingPreviewLen {
+		return text
+	}
+	return string([]rune(text)[:greetingPreviewLen-1]) + "…"
+}
+
+// renderGreeting подставляет в шаблон название события, возраст и число лет
+// вместе на день occurrence. Если шаблону нужен возраст, а год события
+// неизвестен, второе значение равно false.
+func renderGreeting(lang, template string, event *models.Event, occurrence time.Time) (string, bool) {
+	years := ""
+	if m, ok := eventMilestone(event, occurrence); ok {
+		years = i18n.N(lang, m.years, "years")
+	} else if strings.Contains(template, greetingAge) || strings.Contains(template, greetingYearsTogether) {
+		return "", false
+	}
+
+	return strings.NewReplacer(
+		greetingName, event.Title,
+		greetingAge, years,
+		greetingYearsTogether, years,
+	).Replace(template), true
+}
+
+// greetingErrorText возвращает сообщение об ошибке действия с шаблоном поздравления
+func greetingErrorText(lang string, err error) string {
+	switch {
+	case errors.Is(err, db.ErrGreetingNotFound):
+		return i18n.T(lang, "greetings.not_found")
+	case errors.Is(err, db.ErrEventNotFound) || errors.Is(err, db.ErrAccessDenied):
+		return i18n.T(lang, "error.event_not_found")
+	}
+	return i18n.T(lang, "error.save_changes")
+}
+
+// showGreetings показывает шаблоны поздравлений пользователя
+func (b *Bot) showGreetings(chatID int64, user *models.User, lang string) {
+	greetings, err := b.DB.GetGreetingTemplatesByUserID(user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении шаблонов поздравлений: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, greeting := range greetings {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button("💌 "+greetingPreview(greeting.Text), actGreeting, greeting.ID),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard,
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "greetings.new"), actNewGreeting),
+			b.button(i18n.T(lang, "greetings.library"), actGreetingLibrary),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.back"), actSettings),
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
+		),
+	)
+
+	text := i18n.T(lang, "greetings.title")
+	if len(greetings) == 0 {
+		text += i18n.T(lang, "greetings.empty")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// sendGreetingChoice предлагает выбрать шаблон поздравления для события
+// или типа события
+func (b *Bot) sendGreetingChoice(chatID int64, user *models.User, target string, targetID int64, lang string) {
+	greetings, err := b.DB.GetGreetingTemplatesByUserID(user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении шаблонов поздравлений: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	if len(greetings) == 0 {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "greetings.choose_empty"))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "settings.greetings"), actGreetings),
+		))
+		b.API.Send(msg)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, greeting := range greetings {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button("💌 "+greetingPreview(greeting.Text), actSetGreeting, target, targetID, greeting.ID),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "greetings.none"), actSetGreeting, target, targetID, 0),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "greetings.choose"))
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// handleGreetingCallback обрабатывает кнопки раздела шаблонов поздравлений
+func (b *Bot) handleGreetingCallback(chatID, telegramID int64, cb *Callback, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	switch cb.Action {
+	case actGreetings:
+		b.ResetUserState(telegramID)
+		b.showGreetings(chatID, user, lang)
+
+	case actNewGreeting:
+		b.SetUserState(telegramID, models.StateNewGreeting)
+		b.sendText(chatID, i18n.T(lang, "greetings.prompt"))
+
+	case actGreetingLibrary:
+		// Первое нажатие показывает библиотеку, нажатие на шаблон добавляет его
+		library := greetingLibrary(lang)
+		index, err := cb.Int64(0)
+		if err != nil {
+			var lines []string
+			keyboard := tgbotapi.NewInlineKeyboardMarkup()
+			for i, text := range library {
+				lines = append(lines, fmt.Sprintf("%d. %s", i+1, text))
+				keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+					b.button(i18n.T(lang, "greetings.library_add", i+1), actGreetingLibrary, i),
+				))
+			}
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "button.back"), actGreetings),
+			))
+
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "greetings.library_title", strings.Join(lines, "\n\n")))
+			msg.ReplyMarkup = keyboard
+			b.API.Send(msg)
+			return
+		}
+
+		if index < 0 || int(index) >= len(library) {
+			log.Printf("Неизвестный шаблон библиотеки поздравлений: %d", index)
+			return
+		}
+		if _, err := b.DB.CreateGreetingTemplate(user.ID, library[index]); err != nil {
+			log.Printf("Ошибка при создании шаблона поздравления: %v", err)
+			b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+			return
+		}
+		b.sendText(chatID, i18n.T(lang, "greetings.created"))
+		b.showGreetings(chatID, user, lang)
+
+	case actGreeting, actDeleteGreeting:
+		greetingID, err := cb.Int64(0)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID шаблона поздравления: %v", err)
+			return
+		}
+
+		greeting, err := b.DB.GetGreetingTemplateForUser(greetingID, user.ID)
+		if err != nil {
+			log.Printf("Ошибка при получении шаблона поздравления %d: %v", greetingID, err)
+			b.sendText(chatID, greetingErrorText(lang, err))
+			return
+		}
+
+		if cb.Action == actGreeting {
+			msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "greetings.card", greeting.Text))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "event.delete"), actDeleteGreeting, greeting.ID),
+				b.button(i18n.T(lang, "button.back"), actGreetings),
+			))
+			b.API.Send(msg)
+			return
+		}
+
+		if err := b.DB.DeleteGreetingTemplate(greeting.ID, user.ID); err != nil {
+			log.Printf("Ошибка при удалении шаблона поздравления: %v", err)
+			b.sendText(chatID, greetingErrorText(lang, err))
+			return
+		}
+		b.sendText(chatID, i18n.T(lang, "greetings.deleted"))
+		b.showGreetings(chatID, user, lang)
+
+	case actChooseGreeting:
+		targetID, err := cb.Int64(1)
+		if err != nil {
+			log.Printf("Ошибка при парсинге ID для поздравления: %v", err)
+			return
+		}
+		b.sendGreetingChoice(chatID, user, cb.Arg(0), targetID, lang)
+
+	case actSetGreeting:
+		targetID, err := cb.Int64(1)
+		var greetingID int64
+		if err == nil {
+			greetingID, err = cb.Int64(2)
+		}
+		if err != nil {
+			log.Printf("Ошибка при парсинге выбора поздравления: %v", err)
+			return
+		}
+
+		switch cb.Arg(0) {
+		case greetingTargetEvent:
+			b.ResetUserState(telegramID)
+			if err := b.DB.SetEventGreetingForUser(targetID, user.ID, greetingID); err != nil {
+				log.Printf("Ошибка при изменении поздравления события %d: %v", targetID, err)
+				b.sendText(chatID, greetingErrorText(lang, err))
+				return
+			}
+			b.sendText(chatID, i18n.T(lang, "greetings.set"))
+			b.showEvent(chatID, telegramID, targetID, lang)
+
+		case greetingTargetType:
+			if err := b.DB.SetEventTypeGreeting(targetID, user.ID, greetingID); err != nil {
+				log.Printf("Ошибка при изменении поздравления типа события %d: %v", targetID, err)
+				b.sendText(chatID, greetingErrorText(lang, err))
+				return
+			}
+			b.sendText(chatID, i18n.T(lang, "greetings.set"))
+			if _, eventType := b.loadEventType(chatID, telegramID, targetID, lang); eventType != nil {
+				b.showEventType(chatID, user, eventType, lang)
+			}
+
+		default:
+			log.Printf("Неизвестный объект для поздравления: %s", cb.Arg(0))
+		}
+	}
+}
+
+// handleNewGreetingInput создает шаблон поздравления с введенным текстом
+func (b *Bot) handleNewGreetingInput(chatID, telegramID int64, text string, lang string) {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > maxGreetingText {
+		b.sendText(chatID, i18n.T(lang, "error.greeting_text", maxGreetingText))
+		return
+	}
+
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+		b.ResetUserState(telegramID)
+		return
+	}
+
+	if _, err := b.DB.CreateGreetingTemplate(user.ID, text); err != nil {
+		log.Printf("Ошибка при создании шаблона поздравления: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+		b.ResetUserState(telegramID)
+		return
+	}
+
+	b.ResetUserState(telegramID)
+	b.sendText(chatID, i18n.T(lang, "greetings.created"))
+	b.showGreetings(chatID, user, lang)
+}
+
+// eventGreeting возвращает поздравление по шаблону, привязанному к событию
+// или к типу события пользователя, или пустую строку, если шаблона нет
+func (b *Bot) eventGreeting(user *models.User, event *models.Event, occurrence time.Time) string {
+	template, err := b.DB.GetGreetingForEvent(event, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении поздравления для события %d: %v", event.ID, err)
+		return ""
+	}
+	if template == "" {
+		return ""
+	}
+
+	greeting, _ := renderGreeting(i18n.Normalize(user.Language), template, event, occurrence)
+	return greeting
+}
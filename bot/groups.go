@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"log"
+	"strings"
+
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// groupReadOnlyCommands команды, которые в группе доступны всем участникам
+var groupReadOnlyCommands = map[string]bool{
+	"help":      true,
+	"list":      true,
+	"search":    true,
+	agendaToday: true,
+	agendaWeek:  true,
+	agendaMonth: true,
+	agendaNext:  true,
+}
+
+// groupReadOnlyActions кнопки, которые в группе доступны всем участникам
+var groupReadOnlyActions = map[string]bool{
+	actMenu:       true,
+	actHelp:       true,
+	actListEvents: true,
+	actListPage:   true,
+	actAgenda:     true,
+	actEvent:      true,
+	actHistory:    true,
+	actSearch:     true,
+	actNoop:       true,
+	actCalendars:  true,
+	actCalendar:   true,
+	actEventTypes: true,
+	actEventType:  true,
+	actTrash:      true,
+	actSettings:   true,
+	actLanguage:   true,
+}
+
+// isGroupChat проверяет, что чат — группа или супергруппа
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// accountID возвращает Telegram ID владельца событий и настроек: в личном
+// чате это пользователь, а в группе — сама группа. У группы свой список
+// событий, время уведомлений и язык, а напоминания приходят в группу.
+func accountID(chat *tgbotapi.Chat, from *tgbotapi.User) int64 {
+	if isGroupChat(chat) {
+		return chat.ID
+	}
+	return from.ID
+}
+
+// isChatAdmin проверяет, является ли пользователь администратором группы
+func (b *Bot) isChatAdmin(chatID, userID int64) bool {
+	member, err := b.API.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		log.Printf("Ошибка при проверке прав пользователя %d в чате %d: %v", userID, chatID, err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// isGroupAdminMessage проверяет, отправил ли сообщение администратор группы.
+// Анонимные администраторы пишут от имени самой группы.
+func (b *Bot) isGroupAdminMessage(message *tgbotapi.Message) bool {
+	if message.SenderChat != nil && message.SenderChat.ID == message.Chat.ID {
+		return true
+	}
+	return b.isChatAdmin(message.Chat.ID, message.From.ID)
+}
+
+// ensureGroupAccount регистрирует группу как владельца событий при первом обращении
+func (b *Bot) ensureGroupAccount(chat *tgbotapi.Chat, lang string) {
+	if _, err := b.DB.CreateUser(chat.ID, chat.UserName, chat.Title, "", lang); err != nil {
+		log.Printf("Ошибка при регистрации группы %d: %v", chat.ID, err)
+	}
+}
+
+// allowGroupMessage решает, обрабатывать ли сообщение из группы. Команды
+// другим ботам и обычная переписка участников игнорируются, команды, которые
+// что-то меняют, выполняются только для администраторов, а ответы на вопросы
+// бота принимаются только от того, кто начал диалог.
+func (b *Bot) allowGroupMessage(message *tgbotapi.Message) bool {
+	chatID := message.Chat.ID
+
+	if message.IsCommand() {
+		// Команда вида /add@другой_бот адресована не нам
+		command := message.CommandWithAt()
+		if i := strings.Index(command, "@"); i != -1 && !strings.EqualFold(command[i+1:], b.API.Self.UserName) {
+			return false
+		}
+
+		lang := i18n.Detect(message.From.LanguageCode)
+		b.ensureGroupAccount(message.Chat, lang)
+
+		if !groupReadOnlyCommands[message.Command()] && !b.isGroupAdminMessage(message) {
+			b.sendText(chatID, i18n.T(b.userLang(message.Chat, message.From), "group.admin_only"))
+			return false
+		}
+		return true
+	}
+
+	state := b.GetUserState(chatID)
+	if state.State == models.StateDefault {
+		return false
+	}
+	actor, _ := state.CurrentData["actor"].(int64)
+	return actor == message.From.ID
+}
+
+// rememberGroupActor запоминает, кто начал диалог в группе, чтобы принимать
+// ответы только от него. Состояние диалога в группе общее для всех участников,
+// поэтому автор меняется, только когда действие перевело диалог в новое
+// состояние: просмотр списка другим участником не перехватывает диалог.
+func (b *Bot) rememberGroupActor(chatID, userID int64, before string) {
+	state := b.GetUserState(chatID)
+	if state.State == models.StateDefault {
+		return
+	}
+	if _, ok := state.CurrentData["actor"]; ok && state.State == before {
+		return
+	}
+	b.SaveUserData(chatID, "actor", userID)
+}
@@ -0,0 +1,227 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// inlinePageSize число событий в одном ответе на inline-запрос
+	inlinePageSize = 20
+	// inlineCacheTime время в секундах, на которое Telegram кэширует ответ
+	inlineCacheTime = 10
+	// inlineEventPrefix начало ID результата с карточкой события
+	inlineEventPrefix = "e"
+	// inlineNewEventID ID результата, который создает новое событие
+	inlineNewEventID = "new"
+	// inlineStartParameter параметр /start кнопки перехода в чат с ботом
+	inlineStartParameter = "inline"
+)
+
+// parseQuickEvent разбирает быстрое напоминание «Позвонить маме 25.12»:
+// последнее слово — дата, остальное — название события. Быстрое напоминание
+// разовое, поэтому дата без года становится ближайшей такой датой от now.
+func parseQuickEvent(text string, now time.Time) (string, string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return "", "", false
+	}
+
+	date, err := utils.FormatDate(fields[len(fields)-1])
+	if err == nil {
+		date, err = utils.OnceDate(date, now)
+	}
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Join(fields[:len(fields)-1], " "), date, true
+}
+
+// inlineEventCard возвращает карточку события для отправки в любой чат:
+// дату с числом оставшихся дней, возраст или номер годовщины и описание
+func inlineEventCard(lang string, occ occurrence) string {
+	date := occ.date.Format("2006-01-02")
+	card := fmt.Sprintf("🗓 %s\n\n", occ.event.Title) +
+		i18n.T(lang, "inline.date", i18n.FormatDate(lang, date), formatDaysLeft(lang, occ.daysLeft))
+
+	if m, ok := eventMilestone(occ.event, occ.date); ok {
+		card += i18n.T(lang, "event.milestone", i18n.FormatDate(lang, date), m.text(lang))
+	}
+	if occ.event.Description != "" {
+		card += i18n.T(lang, "event.description", occ.event.Description)
+	}
+	return card
+}
+
+// inlineEventResult возвращает inline-результат с карточкой события
+func inlineEventResult(lang string, occ occurrence) tgbotapi.InlineQueryResultArticle {
+	id := inlineEventPrefix + strconv.FormatInt(occ.event.ID, 10)
+	result := tgbotapi.NewInlineQueryResultArticle(id, occ.event.Title, inlineEventCard(lang, occ))
+
+	result.Description = i18n.FormatDate(lang, occ.date.Format("2006-01-02")) + ", " + formatDaysLeft(lang, occ.daysLeft)
+	if m, ok := eventMilestone(occ.event, occ.date); ok {
+		result.Description += " · " + m.short(lang)
+	}
+	return result
+}
+
+// inlineEvents находит события пользователя для inline-запроса. Пустой запрос
+// возвращает ближайшие события, остальные ищутся так же, как в /search.
+// Второе значение — смещение следующей страницы или пустая строка.
+func (b *Bot) inlineEvents(user *models.User, text string, offset int, lang string) ([]occurrence, string, error) {
+	now := time.Now()
+
+	if strings.TrimSpace(text) == "" {
+		events, err := b.DB.GetEventsByUserID(user.ID)
+		if err != nil {
+			return nil, "", err
+		}
+
+		occurrences := upcomingOccurrences(events, now)
+		if offset >= len(occurrences) {
+			return nil, "", nil
+		}
+		occurrences = occurrences[offset:]
+
+		next := ""
+		if len(occurrences) > inlinePageSize {
+			occurrences = occurrences[:inlinePageSize]
+			next = strconv.Itoa(offset + inlinePageSize)
+		}
+		return occurrences, next, nil
+	}
+
+	query := db.ParseSearchQuery(text)
+	if err := b.resolveSearchTypes(&query, user.ID, lang); err != nil {
+		return nil, "", err
+	}
+
+	events, total, err := b.DB.SearchEvents(user.ID, query, inlinePageSize, offset)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if offset+len(events) < total {
+		next = strconv.Itoa(offset + len(events))
+	}
+	return upcomingOccurrences(events, now), next, nil
+}
+
+// handleInlineQuery отвечает на inline-запрос @бот текст: показывает
+// подходящие события с карточками для отправки в чат, а если запрос похож на
+// «название дата», первым предлагает создать быстрое напоминание
+func (b *Bot) handleInlineQuery(inlineQuery *tgbotapi.InlineQuery) {
+	lang := b.userLang(nil, inlineQuery.From)
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID:     inlineQuery.ID,
+		Results:           []interface{}{},
+		CacheTime:         inlineCacheTime,
+		IsPersonal:        true,
+		SwitchPMText:      i18n.T(lang, "inline.open_bot"),
+		SwitchPMParameter: inlineStartParameter,
+	}
+
+	user, err := b.DB.GetUserByTelegramID(inlineQuery.From.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+	}
+	// Незарегистрированному пользователю предлагаем только открыть бота
+	if user == nil {
+		b.answerInlineQuery(answer)
+		return
+	}
+
+	offset, _ := strconv.Atoi(inlineQuery.Offset)
+	if offset == 0 {
+		if title, date, ok := parseQuickEvent(inlineQuery.Query, time.Now()); ok {
+			result := tgbotapi.NewInlineQueryResultArticle(inlineNewEventID,
+				i18n.T(lang, "inline.new_title", title),
+				i18n.T(lang, "inline.new_message", title, i18n.FormatDate(lang, date)))
+			result.Description = i18n.T(lang, "inline.new_description", i18n.FormatDate(lang, date))
+			answer.Results = append(answer.Results, result)
+		}
+	}
+
+	occurrences, next, err := b.inlineEvents(user, inlineQuery.Query, offset, lang)
+	if err != nil {
+		log.Printf("Ошибка при поиске событий для inline-запроса: %v", err)
+	}
+	for _, occ := range occurrences {
+		answer.Results = append(answer.Results, inlineEventResult(lang, occ))
+	}
+	answer.NextOffset = next
+
+	b.answerInlineQuery(answer)
+}
+
+// answerInlineQuery отправляет ответ на inline-запрос
+func (b *Bot) answerInlineQuery(answer tgbotapi.InlineConfig) {
+	if _, err := b.API.Request(answer); err != nil {
+		log.Printf("Ошибка при ответе на inline-запрос: %v", err)
+	}
+}
+
+// handleChosenInlineResult обрабатывает выбранный inline-результат. Telegram
+// присылает его, только если для бота включена обратная связь
+// (/setinlinefeedback в @BotFather). Выбор быстрого напоминания создает
+// разовое событие и сообщает о нем в личном чате с ботом.
+func (b *Bot) handleChosenInlineResult(chosen *tgbotapi.ChosenInlineResult) {
+	if chosen.ResultID != inlineNewEventID {
+		log.Printf("Пользователь %d отправил в чат результат %s", chosen.From.ID, chosen.ResultID)
+		return
+	}
+
+	lang := b.userLang(nil, chosen.From)
+	title, date, ok := parseQuickEvent(chosen.Query, time.Now())
+	if !ok {
+		log.Printf("Не удалось разобрать быстрое напоминание %q", chosen.Query)
+		return
+	}
+
+	user, err := b.DB.GetUserByTelegramID(chosen.From.ID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		return
+	}
+
+	event := &models.Event{
+		UserID:     user.ID,
+		Title:      title,
+		Type:       models.EventTypeOther,
+		EventDate:  date,
+		NotifyDays: 1,
+		Recurrence: models.RecurrenceOnce,
+	}
+
+	// Быстрое напоминание получает встроенный тип «Другое» с его настройками
+	eventTypes, err := b.DB.GetEventTypesByUserID(user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении типов событий: %v", err)
+	}
+	for _, eventType := range eventTypes {
+		if eventType.Kind == models.EventTypeOther && eventType.Name == "" {
+			event.TypeID = eventType.ID
+			event.NotifyDays = eventType.NotifyDays
+			break
+		}
+	}
+
+	event.ID, err = b.DB.CreateEventForUser(event)
+	if err != nil {
+		log.Printf("Ошибка при создании быстрого напоминания: %v", err)
+		b.sendText(chosen.From.ID, i18n.T(lang, "error.save_event"))
+		return
+	}
+
+	b.sendText(chosen.From.ID, i18n.T(lang, "add.success", event.Title)+b.formatEventDetails(lang, event))
+}
@@ -0,0 +1,281 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// listPageSize число событий на одной странице списка
+const listPageSize = 10
+
+// Способы сортировки списка событий
+const (
+	sortNext    = "next"
+	sortName    = "name"
+	sortType    = "type"
+	sortCreated = "created"
+)
+
+// listSorts порядок переключения сортировок
+var listSorts = []string{sortNext, sortName, sortType, sortCreated}
+
+// listWindows фильтры по времени: число дней до события, 0 — без ограничения
+var listWindows = []int{0, 7, 30, 365}
+
+// listOptions состояние списка событий, которое передается в кнопках
+type listOptions struct {
+	Page   int
+	Sort   string
+	TypeID int64
+	Window int
+}
+
+// listOptionsFromCallback восстанавливает состояние списка из данных кнопки
+func listOptionsFromCallback(cb *Callback) listOptions {
+	var opts listOptions
+	if page, err := cb.Int64(0); err == nil {
+		opts.Page = int(page)
+	}
+	opts.Sort = cb.Arg(1)
+	if typeID, err := cb.Int64(2); err == nil {
+		opts.TypeID = typeID
+	}
+	if window, err := cb.Int64(3); err == nil {
+		opts.Window = int(window)
+	}
+	return opts
+}
+
+// button создает кнопку списка с измененным состоянием
+func (o listOptions) button(b *Bot, text string) tgbotapi.InlineKeyboardButton {
+	return b.button(text, actListPage, o.Page, o.Sort, o.TypeID, o.Window)
+}
+
+// nextInCycle возвращает элемент, следующий за current, по кругу
+func nextInCycle(items []string, current string) string {
+	for i, item := range items {
+		if item == current {
+			return items[(i+1)%len(items)]
+		}
+	}
+	return items[0]
+}
+
+// showEventsPage показывает страницу списка событий. Если messageID не равен
+// нулю, сообщение со списком редактируется на месте.
+func (b *Bot) showEventsPage(chatID int64, messageID int, telegramID int64, opts listOptions, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.list_events"))
+		return
+	}
+
+	events, err := b.DB.GetEventsByUserID(user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении событий: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.list_events"))
+		return
+	}
+
+	if len(events) == 0 {
+		// Если событий нет
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "button.add_event"), actAddEvent),
+				b.button(i18n.T(lang, "button.main_menu"), actMenu),
+			),
+		)
+
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "list.empty"))
+		msg.ReplyMarkup = keyboard
+		b.API.Send(msg)
+		return
+	}
+
+	eventTypes, err := b.DB.GetEventTypesByUserID(user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении типов событий: %v", err)
+	}
+	typeLabels := make(map[int64]string)
+	for _, eventType := range eventTypes {
+		typeLabels[eventType.ID] = eventTypeLabel(lang, eventType)
+	}
+	if _, ok := typeLabels[opts.TypeID]; !ok {
+		opts.TypeID = 0
+	}
+	if opts.Sort == "" {
+		opts.Sort = sortNext
+	}
+
+	// Фильтруем события по типу и времени до ближайшего наступления
+	entries := make([]occurrence, 0, len(events))
+	for _, occ := range upcomingOccurrences(events, time.Now()) {
+		if opts.TypeID != 0 && occ.event.TypeID != opts.TypeID {
+			continue
+		}
+		if opts.Window > 0 && occ.daysLeft > opts.Window {
+			continue
+		}
+		entries = append(entries, occ)
+	}
+
+	label := func(event *models.Event) string {
+		if text, ok := typeLabels[event.TypeID]; ok {
+			return text
+		}
+		return i18n.EventType(lang, event.Type)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		x, y := entries[i], entries[j]
+		switch opts.Sort {
+		case sortName:
+			return strings.ToLower(x.event.Title) < strings.ToLower(y.event.Title)
+		case sortType:
+			if lx, ly := label(x.event), label(y.event); lx != ly {
+				return lx < ly
+			}
+			return x.daysLeft < y.daysLeft
+		case sortCreated:
+			return x.event.CreatedAt.After(y.event.CreatedAt)
+		default:
+			return x.daysLeft < y.daysLeft
+		}
+	})
+
+	pages := (len(entries) + listPageSize - 1) / listPageSize
+	if opts.Page >= pages {
+		opts.Page = pages - 1
+	}
+	if opts.Page < 0 {
+		opts.Page = 0
+	}
+
+	// Создаем клавиатуру с кнопками для событий текущей страницы
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	start := opts.Page * listPageSize
+	end := start + listPageSize
+	if end > len(entries) {
+		end = len(entries)
+	}
+	for _, entry := range entries[start:end] {
+		daysInfo := i18n.T(lang, "list.in_days", i18n.N(lang, entry.daysLeft, "days"))
+		if entry.daysLeft == 0 {
+			daysInfo = i18n.T(lang, "list.today")
+		}
+
+		title := entry.event.Title
+		if m, ok := eventMilestone(entry.event, entry.date); ok {
+			title += " (" + m.short(lang) + ")"
+		}
+
+		buttonText := fmt.Sprintf("%s - %s%s", title, i18n.FormatDate(lang, entry.event.EventDate), daysInfo)
+		if entry.event.Priority == models.PriorityHigh {
+			buttonText = "⚡ " + buttonText
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(buttonText, actEvent, entry.event.ID),
+		))
+	}
+
+	// Переключение страниц
+	if pages > 1 {
+		prev, next := opts, opts
+		prev.Page--
+		next.Page++
+
+		var nav []tgbotapi.InlineKeyboardButton
+		if opts.Page > 0 {
+			nav = append(nav, prev.button(b, "◀️"))
+		}
+		nav = append(nav, b.button(fmt.Sprintf("%d / %d", opts.Page+1, pages), actNoop))
+		if opts.Page+1 < pages {
+			nav = append(nav, next.button(b, "▶️"))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, nav)
+	}
+
+	// Сортировка и фильтры переключаются по кругу, страница сбрасывается
+	sorted, byType, byWindow := opts, opts, opts
+	sorted.Page, byType.Page, byWindow.Page = 0, 0, 0
+	sorted.Sort = nextInCycle(listSorts, opts.Sort)
+	byType.TypeID = nextTypeID(eventTypes, opts.TypeID)
+	byWindow.Window = nextWindow(opts.Window)
+
+	typeText := i18n.T(lang, "list.filter_all")
+	if opts.TypeID != 0 {
+		typeText = typeLabels[opts.TypeID]
+	}
+	windowText := i18n.T(lang, "list.filter_all")
+	if opts.Window > 0 {
+		windowText = i18n.N(lang, opts.Window, "days")
+	}
+
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard,
+		tgbotapi.NewInlineKeyboardRow(
+			sorted.button(b, i18n.T(lang, "list.sort", i18n.T(lang, "list.sort_"+opts.Sort))),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			byType.button(b, i18n.T(lang, "list.type", typeText)),
+			byWindow.button(b, i18n.T(lang, "list.window", windowText)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.add_event"), actAddEvent),
+			b.button(i18n.T(lang, "button.trash"), actTrash),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.main_menu"), actMenu),
+		),
+	)
+
+	text := i18n.T(lang, "list.title")
+	if len(entries) == 0 {
+		text = i18n.T(lang, "list.nothing")
+	} else if len(entries) != len(events) {
+		text = i18n.T(lang, "list.filtered", len(entries), len(events))
+	}
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+		b.API.Send(edit)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// nextTypeID возвращает следующий тип для фильтра; после последнего — 0 (все типы)
+func nextTypeID(eventTypes []*models.EventType, current int64) int64 {
+	if current == 0 {
+		if len(eventTypes) > 0 {
+			return eventTypes[0].ID
+		}
+		return 0
+	}
+	for i, eventType := range eventTypes {
+		if eventType.ID == current && i+1 < len(eventTypes) {
+			return eventTypes[i+1].ID
+		}
+	}
+	return 0
+}
+
+// nextWindow возвращает следующий фильтр по времени
+func nextWindow(current int) int {
+	for i, window := range listWindows {
+		if window == current {
+			return listWindows[(i+1)%len(listWindows)]
+		}
+	}
+	return 0
+}
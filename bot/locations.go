@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// eventLocation место встречи из сообщения пользователя
+type eventLocation struct {
+	Latitude  float64
+	Longitude float64
+	Venue     string
+	Address   string
+}
+
+// locationFromMessage возвращает геопозицию или заведение из сообщения
+// пользователя или nil, если места в сообщении нет
+func locationFromMessage(message *tgbotapi.Message) *eventLocation {
+	if message.Venue != nil {
+		return &eventLocation{
+			Latitude:  message.Venue.Location.Latitude,
+			Longitude: message.Venue.Location.Longitude,
+			Venue:     message.Venue.Title,
+			Address:   message.Venue.Address,
+		}
+	}
+	if message.Location != nil {
+		return &eventLocation{Latitude: message.Location.Latitude, Longitude: message.Location.Longitude}
+	}
+	return nil
+}
+
+// descPrompt возвращает приглашение ввести описание события. Для встреч
+// подсказывает, что вместо адреса можно прислать геопозицию или заведение.
+func descPrompt(lang, eventType string) string {
+	prompt := i18n.T(lang, "add.desc_prompt")
+	if eventType == models.EventTypeMeeting {
+		prompt += i18n.T(lang, "add.desc_location_hint")
+	}
+	return prompt
+}
+
+// hasLocation проверяет, указано ли у события место встречи
+func hasLocation(event *models.Event) bool {
+	return event.Latitude != 0 || event.Longitude != 0
+}
+
+// locationLabel возвращает название и адрес места встречи для карточки
+func locationLabel(lang string, event *models.Event) string {
+	var parts []string
+	for _, part := range []string{event.Venue, event.VenueAddress} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return i18n.T(lang, "location.pin")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sendEventLocation отправляет место встречи точкой на карте
+func (b *Bot) sendEventLocation(chatID int64, event *models.Event) {
+	var err error
+	if event.Venue != "" {
+		_, err = b.API.Send(tgbotapi.NewVenue(chatID, event.Venue, event.VenueAddress, event.Latitude, event.Longitude))
+	} else {
+		_, err = b.API.Send(tgbotapi.NewLocation(chatID, event.Latitude, event.Longitude))
+	}
+	if err != nil {
+		log.Printf("Ошибка при отправке места встречи события %d: %v", event.ID, err)
+	}
+}
+
+// parseLeaveBy разбирает время встречи и время в пути в минутах,
+// например «15:00 40»
+func parseLeaveBy(text string) (string, int, error) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 {
+		return "", 0, fmt.Errorf("ожидается время встречи и время в пути: %q", text)
+	}
+
+	meetingTime, err := utils.ValidateTime(fields[0])
+	if err != nil {
+		return "", 0, err
+	}
+
+	travelMinutes, err := strconv.Atoi(fields[1])
+	if err != nil || travelMinutes < 1 || travelMinutes > db.MaxTravelMinutes {
+		return "", 0, fmt.Errorf("недопустимое время в пути: %q", fields[1])
+	}
+	return meetingTime, travelMinutes, nil
+}
+
+// leaveByClock возвращает время выезда ЧЧ:ММ и признак того, что выезжать
+// нужно накануне дня события
+func leaveByClock(event *models.Event) (string, bool) {
+	meeting, err := time.Parse("15:04", event.MeetingTime)
+	if err != nil {
+		return "", false
+	}
+	leave := meeting.Add(-time.Duration(event.TravelMinutes) * time.Minute)
+	return leave.Format("15:04"), leave.Day() != meeting.Day()
+}
+
+// leaveByLabel возвращает описание напоминания о выезде для карточки события
+func leaveByLabel(lang string, event *models.Event) string {
+	leave, _ := leaveByClock(event)
+	return i18n.T(lang, "event.leave_by", leave, event.MeetingTime, i18n.N(lang, event.TravelMinutes, "minutes"))
+}
+
+// handleLocationInput сохраняет место встречи из геопозиции или заведения.
+// «-» удаляет место встречи.
+func (b *Bot) handleLocationInput(chatID, telegramID int64, user *models.User, event *models.Event, message *tgbotapi.Message, lang string) {
+	location := locationFromMessage(message)
+	if location == nil && message.Text != resetValue {
+		b.sendText(chatID, i18n.T(lang, "error.location"))
+		return
+	}
+	if location == nil {
+		location = &eventLocation{}
+	}
+
+	b.ResetUserState(telegramID)
+	err := b.DB.SetEventLocationForUser(event.ID, user.ID, location.Latitude, location.Longitude, location.Venue, location.Address)
+	if err != nil {
+		log.Printf("Ошибка при сохранении места встречи события %d: %v", event.ID, err)
+		b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+		return
+	}
+
+	b.sendText(chatID, i18n.T(lang, "edit.location_saved"))
+	b.showEvent(chatID, telegramID, event.ID, lang)
+}
+
+// handleLeaveByInput сохраняет время встречи и время в пути для напоминания
+// о выезде. «-» отключает напоминание.
+func (b *Bot) handleLeaveByInput(chatID, telegramID int64, user *models.User, event *models.Event, text string, lang string) {
+	var meetingTime string
+	var travelMinutes int
+	if text != resetValue {
+		var err error
+		meetingTime, travelMinutes, err = parseLeaveBy(text)
+		if err != nil {
+			b.sendText(chatID, i18n.T(lang, "error.leave_by", db.MaxTravelMinutes))
+			return
+		}
+	}
+
+	b.ResetUserState(telegramID)
+	if err := b.DB.SetEventLeaveByForUser(event.ID, user.ID, meetingTime, travelMinutes); err != nil {
+		log.Printf("Ошибка при сохранении напоминания о выезде для события %d: %v", event.ID, err)
+		b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+		return
+	}
+
+	b.sendText(chatID, i18n.T(lang, "edit.leave_by_saved"))
+	b.showEvent(chatID, telegramID, event.ID, lang)
+}
+
+// sendLeaveByReminders напоминает о выезде на встречи, на которые пора
+// выезжать в now. Время выезда пользователь выбрал сам, поэтому тихие часы
+// его не откладывают, а во время паузы напоминание не отправляется.
+func (b *Bot) sendLeaveByReminders(now time.Time) {
+	recipients, err := b.DB.GetLeaveByRecipients(now.Format("15:04"))
+	if err != nil {
+		log.Printf("Ошибка при получении напоминаний о выезде: %v", err)
+		return
+	}
+
+	for _, recipient := range recipients {
+		event := recipient.Event
+
+		// Если дорога начинается до полуночи, встреча будет завтра
+		_, previousDay := leaveByClock(event)
+		wantDays := 0
+		if previousDay {
+			wantDays = 1
+		}
+		daysLeft, err := utils.DaysUntilEvent(event.EventDate, occurrenceRule(event))
+		if err != nil {
+			log.Printf("Ошибка при расчете дней до события %d: %v", event.ID, err)
+			continue
+		}
+		if daysLeft != wantDays {
+			continue
+		}
+
+		user, err := b.DB.GetUserByID(recipient.UserID)
+		if err != nil || user == nil {
+			log.Printf("Ошибка при получении пользователя %d: %v", recipient.UserID, err)
+			continue
+		}
+		if user.DNDUntil != nil && user.DNDUntil.After(now) {
+			continue
+		}
+
+		lang := i18n.Normalize(user.Language)
+		text := i18n.T(lang, "notify.leave_by",
+			event.Title, event.MeetingTime, i18n.N(lang, event.TravelMinutes, "minutes"))
+		if _, err := b.API.Send(tgbotapi.NewMessage(user.TelegramID, text)); err != nil {
+			log.Printf("Ошибка при отправке напоминания о выезде для события %d: %v", event.ID, err)
+			continue
+		}
+		if hasLocation(event) {
+			b.sendEventLocation(user.TelegramID, event)
+		}
+	}
+}
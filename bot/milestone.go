@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+)
+
+// milestone возраст именинника или номер годовщины в день наступления события
+type milestone struct {
+	kind  string
+	years int
+}
+
+// eventMilestone рассчитывает возраст или номер годовщины для дня occurrence.
+// Для других типов, ежемесячных и разовых событий и событий без года второе значение равно false.
+func eventMilestone(event *models.Event, occurrence time.Time) (milestone, bool) {
+	if event.Type != models.EventTypeBirthday && event.Type != models.EventTypeAnniversary {
+		return milestone{}, false
+	}
+	if event.Recurrence == models.RecurrenceMonthly || event.Recurrence == models.RecurrenceOnce {
+		return milestone{}, false
+	}
+
+	years, ok := utils.YearsAt(event.EventDate, occurrence)
+	if !ok {
+		return milestone{}, false
+	}
+	return milestone{kind: event.Type, years: years}, true
+}
+
+// isRound проверяет, что дата круглая: каждые пять лет
+func (m milestone) isRound() bool {
+	return m.years%5 == 0
+}
+
+// text возвращает «исполняется 60 лет» или «15-я годовщина»
+func (m milestone) text(lang string) string {
+	text := i18n.T(lang, "milestone.anniversary", i18n.Ordinal(lang, m.years))
+	if m.kind == models.EventTypeBirthday {
+		text = i18n.T(lang, "milestone.birthday", i18n.N(lang, m.years, "years"), m.years)
+	}
+
+	if m.isRound() {
+		return i18n.T(lang, "milestone.round", text)
+	}
+	return text
+}
+
+// short возвращает краткую форму для списков: «60 лет» или «15-я»
+func (m milestone) short(lang string) string {
+	text := i18n.Ordinal(lang, m.years)
+	if m.kind == models.EventTypeBirthday {
+		text = i18n.N(lang, m.years, "years")
+	}
+
+	if m.isRound() {
+		return "🎊 " + text
+	}
+	return text
+}
+
+// titleWithMilestone добавляет к названию события возраст или номер годовщины
+func titleWithMilestone(lang string, event *models.Event, occurrence time.Time) string {
+	if m, ok := eventMilestone(event, occurrence); ok {
+		return event.Title + " — " + m.text(lang)
+	}
+	return event.Title
+}
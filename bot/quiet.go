@@ -0,0 +1,170 @@
+package bot
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Ночные часы для повторных напоминаний, если пользователь не задал тихие часы
+const (
+	defaultQuietStart = "22:00"
+	defaultQuietEnd   = "08:00"
+)
+
+// dndOptions варианты паузы напоминаний в днях
+var dndOptions = []int{1, 3, 5, 7, 14}
+
+// isUrgent проверяет, нужно ли напоминать о событии несмотря на тихие часы и паузу
+func isUrgent(event *models.Event) bool {
+	return event.Priority == models.PriorityHigh
+}
+
+// deferUntil возвращает время, до которого откладывается обычное
+// напоминание: окончание тихих часов или паузы пользователя
+func deferUntil(user *models.User, now time.Time) (time.Time, bool) {
+	until := now
+	// Пауза может закончиться в тихие часы и наоборот, поэтому проверяем
+	// оба окна, пока время не перестанет сдвигаться
+	for i := 0; i < 3; i++ {
+		moved := false
+		if user.DNDUntil != nil && user.DNDUntil.After(until) {
+			until = *user.DNDUntil
+			moved = true
+		}
+		if user.QuietStart != "" && user.QuietEnd != "" {
+			if end, quiet := utils.TimeRangeEnd(user.QuietStart, user.QuietEnd, until.In(now.Location())); quiet {
+				until = end
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+	return until, until.After(now)
+}
+
+// nagQuietUntil возвращает окончание тихих часов для повторных напоминаний.
+// Если тихие часы не заданы, повторы не отправляются ночью.
+func nagQuietUntil(user *models.User, now time.Time) (time.Time, bool) {
+	start, end := user.QuietStart, user.QuietEnd
+	if start == "" || end == "" {
+		start, end = defaultQuietStart, defaultQuietEnd
+	}
+	return utils.TimeRangeEnd(start, end, now)
+}
+
+// quietHoursStatus возвращает тихие часы пользователя для экрана настроек
+func quietHoursStatus(lang string, user *models.User) string {
+	if user.QuietStart == "" || user.QuietEnd == "" {
+		return i18n.T(lang, "settings.off")
+	}
+	return user.QuietStart + "–" + user.QuietEnd
+}
+
+// dndStatus возвращает время окончания паузы для экрана настроек
+func dndStatus(lang string, user *models.User) string {
+	if user.DNDUntil == nil || !user.DNDUntil.After(time.Now()) {
+		return i18n.T(lang, "settings.off")
+	}
+	return i18n.T(lang, "settings.dnd_until", i18n.FormatDateTime(lang, user.DNDUntil.Local()))
+}
+
+// showDNDOptions предлагает варианты паузы напоминаний
+func (b *Bot) showDNDOptions(chatID, telegramID int64, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.settings"))
+		return
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, days := range dndOptions {
+		row = append(row, b.button(i18n.N(lang, days, "days"), actDND, days))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+
+	if user.DNDUntil != nil && user.DNDUntil.After(time.Now()) {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "settings.dnd_resume"), actDND, 0),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.back"), actSettings),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "settings.dnd_prompt"))
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// setDND приостанавливает напоминания на days дней; 0 снимает паузу
+func (b *Bot) setDND(chatID, telegramID int64, days int, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.update_settings"))
+		return
+	}
+
+	var until *time.Time
+	if days > 0 {
+		end := time.Now().AddDate(0, 0, days)
+		until = &end
+	}
+
+	if err := b.DB.SetUserDND(user.ID, until); err != nil {
+		log.Printf("Ошибка при сохранении паузы напоминаний: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+		return
+	}
+
+	if until != nil {
+		b.sendText(chatID, i18n.T(lang, "settings.dnd_set", i18n.FormatDateTime(lang, *until)))
+	} else {
+		b.sendText(chatID, i18n.T(lang, "settings.dnd_cleared"))
+	}
+	b.showSettings(chatID, telegramID, lang)
+}
+
+// handleQuietHoursInput сохраняет тихие часы, введенные пользователем
+func (b *Bot) handleQuietHoursInput(chatID, telegramID int64, text string, lang string) {
+	var start, end string
+	if text = strings.TrimSpace(text); text != resetValue {
+		var err error
+		start, end, err = utils.ParseTimeRange(text)
+		if err != nil {
+			b.sendText(chatID, i18n.T(lang, "error.retry_time_range", inputErrorText(lang, err)))
+			return
+		}
+	}
+
+	b.ResetUserState(telegramID)
+
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.update_settings"))
+		return
+	}
+
+	if err := b.DB.SetUserQuietHours(user.ID, start, end); err != nil {
+		log.Printf("Ошибка при сохранении тихих часов: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.save_settings"))
+		return
+	}
+
+	if start == "" {
+		b.sendText(chatID, i18n.T(lang, "settings.quiet_cleared"))
+	} else {
+		b.sendText(chatID, i18n.T(lang, "settings.quiet_set", start+"–"+end))
+	}
+	b.showSettings(chatID, telegramID, lang)
+}
@@ -0,0 +1,267 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Варианты откладывания напоминания
+const (
+	snoozeHour     = "1h"
+	snoozeHours    = "3h"
+	snoozeTomorrow = "tm"
+)
+
+// Ограничения повторных напоминаний о важных событиях
+const (
+	maxNagAttempts = 6
+	maxNagInterval = 24 * time.Hour
+)
+
+// nagInterval возвращает интервал перед следующим повтором: он удваивается
+// с каждой попыткой, но не превышает суток
+func nagInterval(event *models.Event, attempt int) time.Duration {
+	interval := time.Duration(event.NagHours) * time.Hour
+	for i := 1; i < attempt && interval < maxNagInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxNagInterval {
+		interval = maxNagInterval
+	}
+	return interval
+}
+
+// startNagging начинает повторные напоминания, если событие важное
+// и для него задан интервал повтора
+func (b *Bot) startNagging(user *models.User, event *models.Event, occurrence string, now time.Time) {
+	if event.Priority != models.PriorityHigh || event.NagHours <= 0 {
+		return
+	}
+
+	if err := b.DB.StartNag(user.ID, event.ID, occurrence, now.Add(nagInterval(event, 1))); err != nil {
+		log.Printf("Ошибка при создании повторного напоминания для события %d: %v", event.ID, err)
+	}
+}
+
+// occurrenceDate возвращает дату наступления события, до которого от now
+// осталось daysLeft дней, в формате YYYY-MM-DD
+func occurrenceDate(now time.Time, daysLeft int) string {
+	return utils.StartOfDay(now).AddDate(0, 0, daysLeft).Format("2006-01-02")
+}
+
+// reminderKeyboard создает кнопки «отложить» и «готово» для напоминания
+func (b *Bot) reminderKeyboard(lang string, eventID int64, occurrence string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "notify.snooze_1h"), actSnooze, eventID, occurrence, snoozeHour),
+			b.button(i18n.T(lang, "notify.snooze_3h"), actSnooze, eventID, occurrence, snoozeHours),
+			b.button(i18n.T(lang, "notify.snooze_tomorrow"), actSnooze, eventID, occurrence, snoozeTomorrow),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "notify.done"), actAcknowledge, eventID, occurrence),
+		),
+	)
+}
+
+// isAcknowledged проверяет, отметил ли пользователь наступление события.
+// При ошибке напоминание лучше отправить, поэтому возвращается false.
+func (b *Bot) isAcknowledged(userID, eventID int64, occurrence string) bool {
+	acknowledged, err := b.DB.IsOccurrenceAcknowledged(userID, eventID, occurrence)
+	if err != nil {
+		log.Printf("Ошибка при проверке отметки события %d: %v", eventID, err)
+		return false
+	}
+	return acknowledged
+}
+
+// reminderEvent получает событие для отложенного или повторного напоминания.
+// Если событие удалено или пользователь больше не участвует в его календаре,
+// возвращается nil без ошибки.
+func (b *Bot) reminderEvent(eventID, userID int64) (*models.Event, error) {
+	event, err := b.DB.GetEventForUser(eventID, userID, db.PermissionView)
+	if errors.Is(err, db.ErrEventNotFound) || errors.Is(err, db.ErrAccessDenied) {
+		return nil, nil
+	}
+	return event, err
+}
+
+// snoozeUntil рассчитывает время отложенного напоминания. «Завтра» означает
+// завтра во время уведомлений пользователя.
+func snoozeUntil(now time.Time, option string, user *models.User) time.Time {
+	switch option {
+	case snoozeHour:
+		return now.Add(time.Hour)
+	case snoozeHours:
+		return now.Add(3 * time.Hour)
+	}
+
+	tomorrow := now.AddDate(0, 0, 1)
+	at, err := time.ParseInLocation("15:04", user.NotificationTime, now.Location())
+	if err != nil {
+		at = time.Date(0, 1, 1, 9, 0, 0, 0, now.Location())
+	}
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+}
+
+// sendSnoozedReminders отправляет отложенные напоминания, время которых наступило
+func (b *Bot) sendSnoozedReminders(now time.Time) {
+	snoozes, err := b.DB.GetDueSnoozes(now)
+	if err != nil {
+		log.Printf("Ошибка при получении отложенных напоминаний: %v", err)
+		return
+	}
+
+	for _, snooze := range snoozes {
+		event, err := b.reminderEvent(snooze.EventID, snooze.UserID)
+		if err != nil {
+			log.Printf("Ошибка при получении события %d: %v", snooze.EventID, err)
+			continue
+		}
+		user, err := b.DB.GetUserByID(snooze.UserID)
+		if err != nil || user == nil {
+			log.Printf("Ошибка при получении пользователя %d: %v", snooze.UserID, err)
+			continue
+		}
+
+		// Во время тихих часов и паузы обычное напоминание переносится
+		if event != nil && !isUrgent(event) {
+			if until, ok := deferUntil(user, now); ok {
+				if err := b.DB.SnoozeReminder(user.ID, event.ID, snooze.Occurrence, until); err != nil {
+					log.Printf("Ошибка при переносе напоминания %d: %v", snooze.ID, err)
+				}
+				continue
+			}
+		}
+
+		if err := b.DB.DeleteSnooze(snooze.ID); err != nil {
+			log.Printf("Ошибка при удалении отложенного напоминания %d: %v", snooze.ID, err)
+			continue
+		}
+
+		// Событие удалено в корзину, недоступно или отмечено — напоминать не о чем
+		if event == nil || b.isAcknowledged(user.ID, event.ID, snooze.Occurrence) {
+			continue
+		}
+
+		lang := i18n.Normalize(user.Language)
+		title := event.Title
+		when := i18n.FormatDate(lang, snooze.Occurrence)
+		if date, err := time.Parse("2006-01-02", snooze.Occurrence); err == nil {
+			title = titleWithMilestone(lang, event, date)
+			if daysLeft := int(date.Sub(utils.StartOfDay(now)).Hours() / 24); daysLeft >= 0 {
+				when += ", " + formatDaysLeft(lang, daysLeft)
+			}
+		}
+
+		msg := tgbotapi.NewMessage(user.TelegramID, i18n.T(lang, "notify.snoozed",
+			title, b.typeLabel(lang, event.TypeID, event.Type), when, event.Description))
+		msg.ReplyMarkup = b.reminderKeyboard(lang, event.ID, snooze.Occurrence)
+		if _, err := b.API.Send(msg); err != nil {
+			log.Printf("Ошибка при отправке отложенного напоминания %d: %v", snooze.ID, err)
+		}
+	}
+}
+
+// sendNags повторяет напоминания о важных событиях, пока пользователь
+// не отметит их. В тихие часы повторы откладываются до их окончания.
+func (b *Bot) sendNags(now time.Time) {
+	nags, err := b.DB.GetDueNags(now)
+	if err != nil {
+		log.Printf("Ошибка при получении повторных напоминаний: %v", err)
+		return
+	}
+
+	for _, nag := range nags {
+		event, err := b.reminderEvent(nag.EventID, nag.UserID)
+		if err != nil {
+			log.Printf("Ошибка при получении события %d: %v", nag.EventID, err)
+			continue
+		}
+
+		// Событие удалено или недоступно, перестало быть важным, отмечено или попытки закончились
+		if event == nil || event.Priority != models.PriorityHigh || event.NagHours <= 0 ||
+			nag.Attempt >= maxNagAttempts || b.isAcknowledged(nag.UserID, event.ID, nag.Occurrence) {
+			if err := b.DB.DeleteNag(nag.ID); err != nil {
+				log.Printf("Ошибка при удалении повторного напоминания %d: %v", nag.ID, err)
+			}
+			continue
+		}
+
+		user, err := b.DB.GetUserByID(nag.UserID)
+		if err != nil || user == nil {
+			log.Printf("Ошибка при получении пользователя %d: %v", nag.UserID, err)
+			continue
+		}
+
+		if until, quiet := nagQuietUntil(user, now); quiet {
+			if err := b.DB.UpdateNag(nag.ID, nag.Attempt, until); err != nil {
+				log.Printf("Ошибка при переносе повторного напоминания %d: %v", nag.ID, err)
+			}
+			continue
+		}
+
+		lang := i18n.Normalize(user.Language)
+		title := event.Title
+		if date, err := time.Parse("2006-01-02", nag.Occurrence); err == nil {
+			title = titleWithMilestone(lang, event, date)
+		}
+
+		msg := tgbotapi.NewMessage(user.TelegramID, i18n.T(lang, "notify.nag",
+			title, b.typeLabel(lang, event.TypeID, event.Type),
+			i18n.FormatDate(lang, nag.Occurrence), event.Description))
+		msg.ReplyMarkup = b.reminderKeyboard(lang, event.ID, nag.Occurrence)
+		if _, err := b.API.Send(msg); err != nil {
+			log.Printf("Ошибка при отправке повторного напоминания %d: %v", nag.ID, err)
+		}
+
+		attempt := nag.Attempt + 1
+		if err := b.DB.UpdateNag(nag.ID, attempt, now.Add(nagInterval(event, attempt+1))); err != nil {
+			log.Printf("Ошибка при обновлении повторного напоминания %d: %v", nag.ID, err)
+		}
+	}
+}
+
+// handleReminderCallback обрабатывает кнопки «отложить» и «готово» в напоминаниях
+func (b *Bot) handleReminderCallback(chatID, userID int64, cb *Callback, lang string) {
+	eventID, err := cb.Int64(0)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID события: %v", err)
+		return
+	}
+	occurrence := cb.Arg(1)
+	if _, err := time.Parse("2006-01-02", occurrence); err != nil {
+		log.Printf("Ошибка при парсинге даты наступления события: %v", err)
+		return
+	}
+
+	user, event := b.loadEvent(chatID, userID, eventID, db.PermissionView, lang)
+	if event == nil {
+		return
+	}
+
+	switch cb.Action {
+	case actSnooze:
+		remindAt := snoozeUntil(time.Now(), cb.Arg(2), user)
+		if err := b.DB.SnoozeReminder(user.ID, event.ID, occurrence, remindAt); err != nil {
+			log.Printf("Ошибка при откладывании напоминания: %v", err)
+			b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+			return
+		}
+		b.sendText(chatID, i18n.T(lang, "notify.snoozed_until", event.Title, i18n.FormatDateTime(lang, remindAt)))
+
+	case actAcknowledge:
+		if err := b.DB.AcknowledgeOccurrence(user.ID, event.ID, occurrence); err != nil {
+			log.Printf("Ошибка при отметке события: %v", err)
+			b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+			return
+		}
+		b.sendText(chatID, i18n.T(lang, "notify.acknowledged", event.Title))
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// searchPageSize число событий на одной странице результатов поиска
+const searchPageSize = 8
+
+// formatTags форматирует теги для отображения: #семья #работа
+func formatTags(tags []string) string {
+	return "#" + strings.Join(tags, " #")
+}
+
+// resolveSearchTypes находит типы событий пользователя по фильтрам type:.
+// Сравниваются название типа на языке пользователя и ключ встроенного типа.
+func (b *Bot) resolveSearchTypes(query *db.SearchQuery, userID int64, lang string) error {
+	if len(query.Types) == 0 {
+		return nil
+	}
+
+	eventTypes, err := b.DB.GetEventTypesByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range query.Types {
+		for _, eventType := range eventTypes {
+			label := eventType.Name
+			if label == "" {
+				label = i18n.EventType(lang, eventType.Kind)
+			}
+			if strings.EqualFold(name, label) || (eventType.Name == "" && strings.EqualFold(name, eventType.Kind)) {
+				query.TypeIDs = append(query.TypeIDs, eventType.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// sendSearchResults показывает страницу результатов поиска. Если messageID
+// не равен нулю, сообщение с результатами редактируется на месте.
+func (b *Bot) sendSearchResults(chatID int64, messageID int, telegramID int64, text string, page int, lang string) {
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err != nil || user == nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.search"))
+		return
+	}
+
+	query := db.ParseSearchQuery(text)
+	if query.IsEmpty() {
+		b.SetUserState(telegramID, models.StateSearch)
+		b.sendText(chatID, i18n.T(lang, "search.prompt"))
+		return
+	}
+
+	if err := b.resolveSearchTypes(&query, user.ID, lang); err != nil {
+		log.Printf("Ошибка при получении типов событий: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.search"))
+		return
+	}
+
+	if page < 0 {
+		page = 0
+	}
+	events, total, err := b.DB.SearchEvents(user.ID, query, searchPageSize, page*searchPageSize)
+	if err != nil {
+		log.Printf("Ошибка при поиске событий: %v", err)
+		b.sendText(chatID, i18n.T(lang, "error.search"))
+		return
+	}
+
+	pages := (total + searchPageSize - 1) / searchPageSize
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, event := range events {
+		buttonText := fmt.Sprintf("%s - %s", event.Title, i18n.FormatDate(lang, event.EventDate))
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(buttonText, actEvent, event.ID),
+		))
+	}
+
+	// Кнопки переключения страниц
+	if pages > 1 {
+		var nav []tgbotapi.InlineKeyboardButton
+		if page > 0 {
+			nav = append(nav, b.button("◀️", actSearch, page-1, text))
+		}
+		if page+1 < pages {
+			nav = append(nav, b.button("▶️", actSearch, page+1, text))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, nav)
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.main_menu"), actMenu),
+	))
+
+	resultText := i18n.T(lang, "search.empty", text)
+	if total > 0 {
+		resultText = i18n.T(lang, "search.title", text, total)
+		if pages > 1 {
+			resultText += i18n.T(lang, "search.page", page+1, pages)
+		}
+	}
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, resultText, keyboard)
+		b.API.Send(edit)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, resultText)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// subscriberName возвращает имя подписчика события для отображения
+func subscriberName(subscriber *models.EventSubscriber) string {
+	if subscriber.FirstName != "" {
+		return subscriber.FirstName
+	}
+	if subscriber.Username != "" {
+		return "@" + subscriber.Username
+	}
+	return fmt.Sprintf("#%d", subscriber.UserID)
+}
+
+// showEventShare отправляет ссылку-приглашение подписаться на событие и
+// список подписчиков с кнопками отключения
+func (b *Bot) showEventShare(chatID, telegramID, eventID int64, lang string) {
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionEdit, lang)
+	if event == nil {
+		return
+	}
+
+	code, err := b.DB.CreateEventShareInvite(event.ID, user.ID)
+	var subscribers []*models.EventSubscriber
+	if err == nil {
+		subscribers, err = b.DB.GetEventSubscribers(event.ID, user.ID)
+	}
+	if err != nil {
+		log.Printf("Ошибка при создании приглашения к событию %d: %v", event.ID, err)
+		b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	var lines []string
+	for _, subscriber := range subscribers {
+		lines = append(lines, "• "+subscriberName(subscriber))
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "share.revoke", subscriberName(subscriber)), actUnsubscribe, event.ID, subscriber.UserID),
+		))
+	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		b.button(i18n.T(lang, "button.back"), actEvent, event.ID),
+	))
+
+	subscribersText := i18n.T(lang, "share.no_subscribers")
+	if len(lines) > 0 {
+		subscribersText = strings.Join(lines, "\n")
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s", b.API.Self.UserName, code)
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "share.invite", event.Title, link, subscribersText))
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// unsubscribeEvent отменяет подписку на событие: подписчик отписывается сам
+// или автор события отключает подписчика
+func (b *Bot) unsubscribeEvent(chatID, telegramID int64, cb *Callback, lang string) {
+	eventID, err := cb.Int64(0)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID события: %v", err)
+		return
+	}
+	subscriberID, err := cb.Int64(1)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID подписчика: %v", err)
+		return
+	}
+
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionView, lang)
+	if event == nil {
+		return
+	}
+
+	if err := b.DB.RemoveEventSubscriber(event.ID, user.ID, subscriberID); err != nil {
+		if !errors.Is(err, db.ErrEventNotFound) && !errors.Is(err, db.ErrAccessDenied) {
+			log.Printf("Ошибка при отмене подписки на событие %d: %v", event.ID, err)
+		}
+		b.sendText(chatID, i18n.T(lang, "error.event_not_found"))
+		return
+	}
+
+	if subscriberID == user.ID {
+		b.sendText(chatID, i18n.T(lang, "share.unsubscribed", event.Title))
+		b.sendEventsList(chatID, telegramID, lang)
+		return
+	}
+	b.sendText(chatID, i18n.T(lang, "share.revoked"))
+	b.showEventShare(chatID, telegramID, event.ID, lang)
+}
+
+// subscriptionNotifyText возвращает строку карточки о том, за сколько дней
+// подписчику напомнят о событии
+func (b *Bot) subscriptionNotifyText(lang string, event *models.Event, userID int64) string {
+	days, own, err := b.DB.GetSubscriptionNotifyDays(event.ID, userID)
+	if err != nil {
+		log.Printf("Ошибка при получении настроек подписки на событие %d: %v", event.ID, err)
+		return ""
+	}
+	if !own {
+		return i18n.T(lang, "event.subscription_notify_author", i18n.N(lang, event.NotifyDays, "days"))
+	}
+	return i18n.T(lang, "event.subscription_notify_own", i18n.N(lang, days, "days"))
+}
+
+// askSubscriptionNotify просит подписчика ввести, за сколько дней ему
+// напоминать о событии
+func (b *Bot) askSubscriptionNotify(chatID, telegramID int64, cb *Callback, lang string) {
+	eventID, err := cb.Int64(0)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID события: %v", err)
+		return
+	}
+
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionView, lang)
+	if event == nil {
+		return
+	}
+	if subscribed, err := b.DB.IsEventSubscriber(event.ID, user.ID); err != nil || !subscribed {
+		if err != nil {
+			log.Printf("Ошибка при проверке подписки на событие %d: %v", event.ID, err)
+		}
+		b.sendText(chatID, i18n.T(lang, "error.event_not_found"))
+		return
+	}
+
+	b.ResetUserState(telegramID)
+	b.SetUserState(telegramID, models.StateSubscriptionNotify)
+	b.SaveUserData(telegramID, "event_id", event.ID)
+	b.sendText(chatID, i18n.T(lang, "share.notify_prompt", event.Title, i18n.N(lang, event.NotifyDays, "days")))
+}
+
+// handleSubscriptionNotifyInput сохраняет срок напоминаний подписчика.
+// «-» возвращает срок, указанный автором.
+func (b *Bot) handleSubscriptionNotifyInput(chatID, telegramID int64, userState *models.UserState, text string, lang string) {
+	days := 0
+	if text != resetValue {
+		var err error
+		days, err = strconv.Atoi(text)
+		if err != nil || days < 1 || days > 30 {
+			b.sendText(chatID, i18n.T(lang, "error.notify_days"))
+			return
+		}
+	}
+
+	eventID, _ := userState.CurrentData["event_id"].(int64)
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionView, lang)
+	b.ResetUserState(telegramID)
+	if event == nil {
+		return
+	}
+
+	if err := b.DB.SetSubscriptionNotifyDays(event.ID, user.ID, days); err != nil {
+		if !errors.Is(err, db.ErrEventNotFound) {
+			log.Printf("Ошибка при сохранении настроек подписки на событие %d: %v", event.ID, err)
+		}
+		b.sendText(chatID, i18n.T(lang, "error.save_changes"))
+		return
+	}
+
+	b.sendText(chatID, i18n.T(lang, "share.notify_saved"))
+	b.showEvent(chatID, telegramID, event.ID, lang)
+}
+
+// subscribeToEvent подписывает пользователя на событие по коду из ссылки-приглашения
+func (b *Bot) subscribeToEvent(chatID, userID int64, code string, lang string) {
+	event, subscribed, err := b.DB.SubscribeToEvent(code, userID)
+	if err != nil {
+		if !errors.Is(err, db.ErrInviteNotFound) {
+			log.Printf("Ошибка при подписке на событие: %v", err)
+		}
+		b.sendText(chatID, i18n.T(lang, "share.invite_invalid"))
+		return
+	}
+
+	if !subscribed {
+		b.sendText(chatID, i18n.T(lang, "share.already", event.Title))
+		return
+	}
+	b.sendText(chatID, i18n.T(lang, "share.subscribed", event.Title))
+}
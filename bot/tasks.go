@@ -0,0 +1,391 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/awhatson15/reminder-bot/db"
+	"github.com/awhatson15/reminder-bot/i18n"
+	"github.com/awhatson15/reminder-bot/models"
+	"github.com/awhatson15/reminder-bot/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxTaskTitle ограничение длины названия задачи в символах
+const maxTaskTitle = 100
+
+// Где показан список задач: от этого зависит, как он обновляется после отметки
+const (
+	taskViewList     = "l"
+	taskViewReminder = "r"
+)
+
+// parseTaskInput разбирает задачу вида «7: забронировать столик» или
+// «7 дней до: забронировать столик». Без числа перед двоеточием задача
+// добавляется без напоминания.
+func parseTaskInput(text string) (*models.Task, bool) {
+	task := &models.Task{Title: strings.TrimSpace(text), DueDays: models.TaskNoDue}
+	if i := strings.Index(text, ":"); i > 0 {
+		if fields := strings.Fields(text[:i]); len(fields) > 0 {
+			if days, err := strconv.Atoi(fields[0]); err == nil {
+				if days < 0 || days > db.MaxTaskDueDays {
+					return nil, false
+				}
+				task.DueDays = days
+				task.Title = strings.TrimSpace(text[i+1:])
+			}
+		}
+	}
+
+	if task.Title == "" || utf8.RuneCountInString(task.Title) > maxTaskTitle {
+		return nil, false
+	}
+	return task, true
+}
+
+// taskOccurrence возвращает ближайшее наступление события (YYYY-MM-DD),
+// к которому выполняются задачи
+func taskOccurrence(event *models.Event, now time.Time) string {
+	next, err := nextOccurrence(event, now)
+	if err != nil {
+		log.Printf("Ошибка при расчете даты события %d: %v", event.ID, err)
+		return ""
+	}
+	return next.Format("2006-01-02")
+}
+
+// taskDone проверяет, выполнена ли задача к наступлению occurrence
+func taskDone(task *models.Task, occurrence string) bool {
+	return task.DoneFor != "" && task.DoneFor == occurrence
+}
+
+// taskLabel возвращает текст кнопки задачи: отметку, название и срок
+func taskLabel(lang string, task *models.Task, occurrence string) string {
+	label := "⬜ " + task.Title
+	if taskDone(task, occurrence) {
+		label = "✅ " + task.Title
+	}
+
+	switch {
+	case task.DueDays == 0:
+		label += " · " + i18n.T(lang, "tasks.due_today")
+	case task.DueDays > 0:
+		label += " · " + i18n.T(lang, "tasks.due", i18n.N(lang, task.DueDays, "days"))
+	}
+	return label
+}
+
+// taskErrorText возвращает сообщение об ошибке действия с задачей
+func taskErrorText(lang string, err error) string {
+	switch {
+	case errors.Is(err, db.ErrTaskLimit):
+		return i18n.T(lang, "tasks.limit", db.MaxTasks)
+	case errors.Is(err, db.ErrEventNotFound) || errors.Is(err, db.ErrAccessDenied):
+		return i18n.T(lang, "tasks.not_found")
+	}
+	return i18n.T(lang, "error.save_changes")
+}
+
+// tasksKeyboard создает кнопки отметки задач. В списке задач редактор
+// события также может удалять и добавлять задачи.
+func (b *Bot) tasksKeyboard(lang string, eventID int64, tasks []*models.Task, occurrence, view string, canEdit bool) tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup()
+	for _, task := range tasks {
+		row := tgbotapi.NewInlineKeyboardRow(b.button(taskLabel(lang, task, occurrence), actToggleTask, task.ID, view))
+		if view == taskViewList && canEdit {
+			row = append(row, b.button("🗑", actDeleteTask, task.ID))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+
+	if view == taskViewList {
+		if canEdit && len(tasks) < db.MaxTasks {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+				b.button(i18n.T(lang, "tasks.add"), actNewTask, eventID),
+			))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			b.button(i18n.T(lang, "button.back"), actEvent, eventID),
+		))
+	}
+	return keyboard
+}
+
+// showTasks показывает задачи подготовки к событию. Если messageID не равен
+// нулю, список редактируется на месте.
+func (b *Bot) showTasks(chatID int64, messageID int, telegramID, eventID int64, lang string) {
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionView, lang)
+	if event == nil {
+		return
+	}
+
+	tasks, err := b.DB.GetTasksForUser(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении задач события %d: %v", event.ID, err)
+		b.sendText(chatID, taskErrorText(lang, err))
+		return
+	}
+
+	// Подписчик чужого события только отмечает задачи
+	subscribed, err := b.DB.IsEventSubscriber(event.ID, user.ID)
+	if err != nil {
+		log.Printf("Ошибка при проверке подписки на событие %d: %v", event.ID, err)
+	}
+
+	occurrence := taskOccurrence(event, time.Now())
+	text := i18n.T(lang, "tasks.title", event.Title, i18n.FormatDate(lang, occurrence))
+	if len(tasks) == 0 {
+		text += i18n.T(lang, "tasks.empty")
+	} else {
+		done := 0
+		for _, task := range tasks {
+			if taskDone(task, occurrence) {
+				done++
+			}
+		}
+		text += i18n.T(lang, "tasks.progress", done, len(tasks))
+	}
+	keyboard := b.tasksKeyboard(lang, event.ID, tasks, occurrence, taskViewList, !subscribed)
+
+	if messageID != 0 {
+		b.API.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	b.API.Send(msg)
+}
+
+// handleTaskCallback обрабатывает кнопки задач подготовки к событию
+func (b *Bot) handleTaskCallback(chatID int64, messageID int, telegramID int64, cb *Callback, lang string) {
+	id, err := cb.Int64(0)
+	if err != nil {
+		log.Printf("Ошибка при парсинге ID: %v", err)
+		return
+	}
+
+	switch cb.Action {
+	case actTasks:
+		b.showTasks(chatID, 0, telegramID, id, lang)
+		return
+
+	case actNewTask:
+		_, event := b.loadEvent(chatID, telegramID, id, db.PermissionEdit, lang)
+		if event == nil {
+			return
+		}
+		b.ResetUserState(telegramID)
+		b.SetUserState(telegramID, models.StateNewTask)
+		b.SaveUserData(telegramID, "event_id", event.ID)
+		b.sendText(chatID, i18n.T(lang, "tasks.prompt", event.Title))
+		return
+	}
+
+	user, err := b.DB.GetUserByTelegramID(telegramID)
+	if err == nil && user == nil {
+		err = db.ErrAccessDenied
+	}
+	if err != nil {
+		log.Printf("Ошибка при получении пользователя: %v", err)
+		b.sendText(chatID, taskErrorText(lang, err))
+		return
+	}
+
+	switch cb.Action {
+	case actToggleTask:
+		task, err := b.DB.GetTaskForUser(id, user.ID, db.PermissionView)
+		if err != nil {
+			log.Printf("Ошибка при получении задачи %d: %v", id, err)
+			b.sendText(chatID, taskErrorText(lang, err))
+			return
+		}
+		_, event := b.loadEvent(chatID, telegramID, task.EventID, db.PermissionView, lang)
+		if event == nil {
+			return
+		}
+
+		occurrence := taskOccurrence(event, time.Now())
+		doneFor := occurrence
+		if taskDone(task, occurrence) {
+			doneFor = ""
+		}
+		if err := b.DB.SetTaskDoneForUser(task.ID, user.ID, doneFor); err != nil {
+			log.Printf("Ошибка при отметке задачи %d: %v", task.ID, err)
+			b.sendText(chatID, taskErrorText(lang, err))
+			return
+		}
+
+		if cb.Arg(1) == taskViewList {
+			b.showTasks(chatID, messageID, telegramID, event.ID, lang)
+			return
+		}
+
+		// В напоминании текст остается прежним, обновляются только отметки
+		tasks, err := b.DB.GetEventTasks(event.ID)
+		if err != nil {
+			log.Printf("Ошибка при получении задач события %d: %v", event.ID, err)
+			return
+		}
+		keyboard := b.tasksKeyboard(lang, event.ID, tasks, occurrence, taskViewReminder, false)
+		b.API.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard))
+
+	case actDeleteTask:
+		task, err := b.DB.DeleteTaskForUser(id, user.ID)
+		if err != nil {
+			log.Printf("Ошибка при удалении задачи %d: %v", id, err)
+			b.sendText(chatID, taskErrorText(lang, err))
+			return
+		}
+		b.showTasks(chatID, messageID, telegramID, task.EventID, lang)
+	}
+}
+
+// handleNewTaskInput добавляет задачу подготовки к событию
+func (b *Bot) handleNewTaskInput(chatID, telegramID int64, userState *models.UserState, text string, lang string) {
+	task, ok := parseTaskInput(text)
+	if !ok {
+		b.sendText(chatID, i18n.T(lang, "error.task", maxTaskTitle, db.MaxTaskDueDays))
+		return
+	}
+
+	eventID, _ := userState.CurrentData["event_id"].(int64)
+	user, event := b.loadEvent(chatID, telegramID, eventID, db.PermissionEdit, lang)
+	if event == nil {
+		b.ResetUserState(telegramID)
+		return
+	}
+	task.EventID = event.ID
+
+	b.ResetUserState(telegramID)
+	if _, err := b.DB.CreateTaskForUser(task, user.ID); err != nil {
+		log.Printf("Ошибка при добавлении задачи к событию %d: %v", event.ID, err)
+		b.sendText(chatID, taskErrorText(lang, err))
+		return
+	}
+	b.showTasks(chatID, 0, telegramID, event.ID, lang)
+}
+
+// dueTasks возвращает задачи события и строки открытых задач со сроком
+// dueDays к наступлению occurrence
+func (b *Bot) dueTasks(event *models.Event, occurrence string, dueDays int) ([]*models.Task, []string) {
+	tasks, err := b.DB.GetEventTasks(event.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении задач события %d: %v", event.ID, err)
+		return nil, nil
+	}
+
+	var due []string
+	for _, task := range tasks {
+		if task.DueDays == dueDays && !taskDone(task, occurrence) {
+			due = append(due, "• "+task.Title)
+		}
+	}
+	return tasks, due
+}
+
+// sendTaskReminders напоминает об открытых задачах, срок которых наступает
+// за daysLeft дней до события. В тихие часы и во время паузы напоминание
+// о задачах обычного события откладывается, как и основное напоминание.
+func (b *Bot) sendTaskReminders(user *models.User, event *models.Event, daysLeft int, now time.Time) {
+	occurrence := occurrenceDate(now, daysLeft)
+	tasks, due := b.dueTasks(event, occurrence, daysLeft)
+	if len(due) == 0 {
+		return
+	}
+
+	if until, ok := deferUntil(user, now); ok && !isUrgent(event) {
+		if err := b.DB.SnoozeTaskReminder(user.ID, event.ID, occurrence, daysLeft, until); err != nil {
+			log.Printf("Ошибка при переносе напоминания о задачах события %d: %v", event.ID, err)
+		}
+		return
+	}
+	b.sendDueTasks(user, event, tasks, due, occurrence, daysLeft)
+}
+
+// sendSnoozedTaskReminders отправляет отложенные напоминания о задачах,
+// время которых наступило
+func (b *Bot) sendSnoozedTaskReminders(now time.Time) {
+	snoozes, err := b.DB.GetDueTaskSnoozes(now)
+	if err != nil {
+		log.Printf("Ошибка при получении отложенных напоминаний о задачах: %v", err)
+		return
+	}
+
+	for _, snooze := range snoozes {
+		event, err := b.reminderEvent(snooze.EventID, snooze.UserID)
+		if err != nil {
+			log.Printf("Ошибка при получении события %d: %v", snooze.EventID, err)
+			continue
+		}
+		user, err := b.DB.GetUserByID(snooze.UserID)
+		if err != nil || user == nil {
+			log.Printf("Ошибка при получении пользователя %d: %v", snooze.UserID, err)
+			continue
+		}
+
+		// Тихие часы или пауза могли начаться снова
+		if event != nil && !isUrgent(event) {
+			if until, ok := deferUntil(user, now); ok {
+				if err := b.DB.SnoozeTaskReminder(user.ID, event.ID, snooze.Occurrence, snooze.DueDays, until); err != nil {
+					log.Printf("Ошибка при переносе напоминания о задачах %d: %v", snooze.ID, err)
+				}
+				continue
+			}
+		}
+
+		if err := b.DB.DeleteTaskSnooze(snooze.ID); err != nil {
+			log.Printf("Ошибка при удалении отложенного напоминания о задачах %d: %v", snooze.ID, err)
+			continue
+		}
+
+		// Событие удалено в корзину, недоступно или уже прошло — готовиться не к чему
+		if event == nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", snooze.Occurrence)
+		if err != nil {
+			continue
+		}
+		daysLeft := int(date.Sub(utils.StartOfDay(now)).Hours() / 24)
+		if daysLeft < 0 {
+			continue
+		}
+
+		tasks, due := b.dueTasks(event, snooze.Occurrence, snooze.DueDays)
+		if len(due) == 0 {
+			continue
+		}
+		b.sendDueTasks(user, event, tasks, due, snooze.Occurrence, daysLeft)
+	}
+}
+
+// sendDueTasks отправляет напоминание об открытых задачах due с кнопками
+// отметки задач
+func (b *Bot) sendDueTasks(user *models.User, event *models.Event, tasks []*models.Task, due []string, occurrence string, daysLeft int) {
+	lang := i18n.Normalize(user.Language)
+	msg := tgbotapi.NewMessage(user.TelegramID, i18n.T(lang, "notify.tasks",
+		event.Title, formatDaysLeft(lang, daysLeft), strings.Join(due, "\n")))
+	msg.ReplyMarkup = b.tasksKeyboard(lang, event.ID, tasks, occurrence, taskViewReminder, false)
+	if _, err := b.API.Send(msg); err != nil {
+		log.Printf("Ошибка при отправке напоминания о задачах события %d: %v", event.ID, err)
+	}
+}
+
+// sendDigestTaskReminders напоминает о задачах пользователю со сводкой: его
+// события не проходят через обычные напоминания
+func (b *Bot) sendDigestTaskReminders(user *models.User, now time.Time) {
+	events, err := b.DB.GetEventsByUserID(user.ID)
+	if err != nil {
+		log.Printf("Ошибка при получении событий пользователя %d: %v", user.ID, err)
+		return
+	}
+
+	for _, occ := range upcomingOccurrences(events, now) {
+		b.sendTaskReminders(user, occ.event, occ.daysLeft, now)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strconv"
 )
 
@@ -11,6 +12,20 @@ type Config struct {
 	DatabasePath     string
 	LogLevel         string
 	DefaultNotifyTime string
+
+	// Резервное копирование
+	BackupDir         string
+	BackupSchedule    string
+	BackupKeepDaily   int
+	BackupKeepWeekly  int
+	BackupKeepMonthly int
+
+	// Сколько дней удаленные события хранятся в корзине
+	TrashRetentionDays int
+
+	// Секрет для подписи данных inline-кнопок и срок их жизни
+	CallbackSecret   string
+	CallbackTTLHours int
 }
 
 // LoadConfig загружает конфигурацию из переменных окружения
@@ -19,12 +34,26 @@ func LoadConfig() *Config {
 	databasePath := getEnv("DATABASE_PATH", "./data/reminder.db")
 	logLevel := getEnv("LOG_LEVEL", "info")
 	defaultNotifyTime := getEnv("DEFAULT_NOTIFY_TIME", "09:00")
+	backupDir := getEnv("BACKUP_DIR", filepath.Join(filepath.Dir(databasePath), "backups"))
+	// Пустое расписание отключает автоматическое резервное копирование
+	backupSchedule := os.Getenv("BACKUP_SCHEDULE")
+	if _, set := os.LookupEnv("BACKUP_SCHEDULE"); !set {
+		backupSchedule = "0 3 * * *"
+	}
 
 	return &Config{
 		BotToken:         botToken,
 		DatabasePath:     databasePath,
 		LogLevel:         logLevel,
 		DefaultNotifyTime: defaultNotifyTime,
+		BackupDir:          backupDir,
+		BackupSchedule:     backupSchedule,
+		BackupKeepDaily:    GetEnvInt("BACKUP_KEEP_DAILY", 7),
+		BackupKeepWeekly:   GetEnvInt("BACKUP_KEEP_WEEKLY", 4),
+		BackupKeepMonthly:  GetEnvInt("BACKUP_KEEP_MONTHLY", 12),
+		TrashRetentionDays: GetEnvInt("TRASH_RETENTION_DAYS", 30),
+		CallbackSecret:     getEnv("CALLBACK_SECRET", ""),
+		CallbackTTLHours:   GetEnvInt("CALLBACK_TTL_HOURS", 720),
 	}
 }
 
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+var (
+	// ErrEventNotFound событие не существует или уже удалено
+	ErrEventNotFound = errors.New("событие не найдено")
+	// ErrAccessDenied у пользователя нет прав на событие
+	ErrAccessDenied = errors.New("нет доступа к событию")
+)
+
+// Permission уровень доступа к событию
+type Permission int
+
+const (
+	// PermissionView просмотр события
+	PermissionView Permission = iota
+	// PermissionEdit изменение и удаление события
+	PermissionEdit
+)
+
+// checkAccess проверяет, может ли пользователь выполнить действие с событием.
+// Личным событием распоряжается его автор, событием общего календаря — участники
+// календаря: зрители только просматривают его, редакторы и владелец изменяют.
+// Подписчики события только просматривают его.
+func (db *DB) checkAccess(event *models.Event, userID int64, perm Permission) error {
+	if event.CalendarID == 0 && event.UserID == userID {
+		return nil
+	}
+
+	if perm == PermissionView {
+		subscribed, err := db.IsEventSubscriber(event.ID, userID)
+		if err != nil {
+			return err
+		}
+		if subscribed {
+			return nil
+		}
+	}
+
+	if event.CalendarID != 0 {
+		role, err := db.calendarRole(event.CalendarID, userID)
+		if err != nil {
+			return err
+		}
+		if role != "" && (perm == PermissionView || CanEditCalendar(role)) {
+			return nil
+		}
+	}
+
+	log.Printf("Отказано в доступе: пользователь %d, событие %d, уровень %d", userID, event.ID, perm)
+	return ErrAccessDenied
+}
+
+// CreateEventForUser создает событие от имени пользователя event.UserID.
+// В общий календарь событие может добавить только его редактор или владелец.
+func (db *DB) CreateEventForUser(event *models.Event) (int64, error) {
+	if event.CalendarID != 0 {
+		role, err := db.calendarRole(event.CalendarID, event.UserID)
+		if err != nil {
+			return 0, err
+		}
+		if !CanEditCalendar(role) {
+			return 0, fmt.Errorf("календарь %d: %w", event.CalendarID, ErrAccessDenied)
+		}
+	}
+	return db.CreateEvent(event)
+}
+
+// MoveEventForUser переносит событие в общий календарь или, если calendarID
+// равен 0, в личный список автора события. Убрать событие из общего
+// календаря может только его автор или владелец календаря, иначе редактор
+// отнимал бы событие у остальных участников.
+func (db *DB) MoveEventForUser(eventID, userID, calendarID int64) error {
+	event, err := db.GetEventForUser(eventID, userID, PermissionEdit)
+	if err != nil {
+		return err
+	}
+
+	if calendarID == 0 {
+		if event.CalendarID != 0 && event.UserID != userID {
+			role, err := db.calendarRole(event.CalendarID, userID)
+			if err != nil {
+				return err
+			}
+			if role != models.CalendarRoleOwner {
+				return fmt.Errorf("календарь %d: %w", event.CalendarID, ErrAccessDenied)
+			}
+		}
+
+		_, err := db.Exec("UPDATE events SET calendar_id = NULL WHERE id = ?", eventID)
+		if err != nil {
+			return fmt.Errorf("ошибка при переносе события: %w", err)
+		}
+		return nil
+	}
+
+	role, err := db.calendarRole(calendarID, userID)
+	if err != nil {
+		return err
+	}
+	if !CanEditCalendar(role) {
+		return fmt.Errorf("календарь %d: %w", calendarID, ErrAccessDenied)
+	}
+
+	if _, err := db.Exec("UPDATE events SET calendar_id = ? WHERE id = ?", calendarID, eventID); err != nil {
+		return fmt.Errorf("ошибка при переносе события: %w", err)
+	}
+	return nil
+}
+
+// GetEventForUser получает событие, если у пользователя есть нужные права
+func (db *DB) GetEventForUser(eventID, userID int64, perm Permission) (*models.Event, error) {
+	event, err := db.GetEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEventNotFound
+	}
+
+	if err := db.checkAccess(event, userID, perm); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// UpdateEventForUser обновляет событие от имени пользователя.
+// Права проверяются по сохраненной записи, а не по полям переданного события.
+func (db *DB) UpdateEventForUser(event *models.Event, userID int64) (int64, error) {
+	stored, err := db.GetEventForUser(event.ID, userID, PermissionEdit)
+	if err != nil {
+		return 0, err
+	}
+
+	event.UserID = stored.UserID
+	event.CalendarID = stored.CalendarID
+	return db.UpdateEvent(event)
+}
+
+// DeleteEventForUser перемещает событие пользователя в корзину
+func (db *DB) DeleteEventForUser(eventID, userID int64) error {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+		return err
+	}
+	return db.DeleteEvent(eventID)
+}
+
+// RestoreEventForUser восстанавливает событие пользователя из корзины
+func (db *DB) RestoreEventForUser(eventID, userID int64, retentionDays int) error {
+	event, err := db.GetDeletedEventByID(eventID)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return ErrEventNotFound
+	}
+
+	if err := db.checkAccess(event, userID, PermissionEdit); err != nil {
+		return err
+	}
+	return db.RestoreEvent(eventID, retentionDays)
+}
+
+// SetEventTagsForUser заменяет теги события, которое пользователь может изменять
+func (db *DB) SetEventTagsForUser(eventID, userID int64, tags []string) error {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+		return err
+	}
+	return db.SetEventTags(eventID, tags)
+}
+
+// GetEventRevisionsForUser получает историю изменений события, доступного пользователю
+func (db *DB) GetEventRevisionsForUser(eventID, userID int64) ([]*models.EventRevision, error) {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionView); err != nil {
+		return nil, err
+	}
+	return db.GetEventRevisions(eventID)
+}
+
+// RevertEventForUser откатывает событие пользователя к ревизии и возвращает
+// обновленное событие вместе с ID ревизии для отмены отката
+func (db *DB) RevertEventForUser(revisionID, userID int64) (*models.Event, int64, error) {
+	revision, err := db.GetEventRevision(revisionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if revision == nil {
+		return nil, 0, fmt.Errorf("ревизия %d: %w", revisionID, ErrEventNotFound)
+	}
+
+	if _, err := db.GetEventForUser(revision.EventID, userID, PermissionEdit); err != nil {
+		return nil, 0, err
+	}
+
+	newRevisionID, err := db.RevertEvent(revisionID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	event, err := db.GetEventByID(revision.EventID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return event, newRevisionID, nil
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// newTestDB создает базу данных во временном файле со схемой
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.InitSchema(); err != nil {
+		t.Fatalf("InitSchema: %v", err)
+	}
+	return db
+}
+
+// newTestUser создает пользователя и возвращает его внутренний ID
+func newTestUser(t *testing.T, db *DB, telegramID int64) int64 {
+	t.Helper()
+
+	userID, err := db.CreateUser(telegramID, "", "User", "", "ru")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return userID
+}
+
+// newTestEvent создает событие пользователя, при ненулевом calendarID — в общем календаре
+func newTestEvent(t *testing.T, db *DB, userID int64, calendarID int64, title string) int64 {
+	t.Helper()
+
+	eventID, err := db.CreateEventForUser(&models.Event{
+		UserID:     userID,
+		CalendarID: calendarID,
+		Title:      title,
+		Type:       models.EventTypeBirthday,
+		EventDate:  "1990-05-15",
+		NotifyDays: 3,
+	})
+	if err != nil {
+		t.Fatalf("CreateEventForUser: %v", err)
+	}
+	return eventID
+}
+
+// newTestCalendar создает общий календарь владельца и добавляет в него редакторов
+func newTestCalendar(t *testing.T, db *DB, ownerID int64, editorIDs ...int64) int64 {
+	t.Helper()
+
+	calendarID, err := db.CreateCalendar("Семья", ownerID)
+	if err != nil {
+		t.Fatalf("CreateCalendar: %v", err)
+	}
+	for _, editorID := range editorIDs {
+		code, err := db.CreateCalendarInvite(calendarID, ownerID, models.CalendarRoleEditor)
+		if err != nil {
+			t.Fatalf("CreateCalendarInvite: %v", err)
+		}
+		if _, _, err := db.JoinCalendar(code, editorID); err != nil {
+			t.Fatalf("JoinCalendar: %v", err)
+		}
+	}
+	return calendarID
+}
+
+// isDenied проверяет, что чужое событие недоступно
+func isDenied(err error) bool {
+	return errors.Is(err, ErrAccessDenied) || errors.Is(err, ErrEventNotFound)
+}
+
+func TestCrossUserAccessDenied(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	stranger := newTestUser(t, db, 2)
+	eventID := newTestEvent(t, db, owner, 0, "Мама")
+
+	// Ревизия, к которой посторонний попробует откатить событие
+	event, err := db.GetEventForUser(eventID, owner, PermissionEdit)
+	if err != nil {
+		t.Fatalf("GetEventForUser владельцем: %v", err)
+	}
+	event.Title = "Мама (новое)"
+	revisionID, err := db.UpdateEventForUser(event, owner)
+	if err != nil {
+		t.Fatalf("UpdateEventForUser владельцем: %v", err)
+	}
+
+	checks := []struct {
+		name string
+		call func() error
+	}{
+		{"GetEventForUser view", func() error {
+			_, err := db.GetEventForUser(eventID, stranger, PermissionView)
+			return err
+		}},
+		{"GetEventForUser edit", func() error {
+			_, err := db.GetEventForUser(eventID, stranger, PermissionEdit)
+			return err
+		}},
+		{"UpdateEventForUser", func() error {
+			_, err := db.UpdateEventForUser(&models.Event{
+				ID: eventID, UserID: stranger, Title: "Чужое", Type: models.EventTypeOther,
+				EventDate: "2000-01-01", NotifyDays: 1,
+			}, stranger)
+			return err
+		}},
+		{"DeleteEventForUser", func() error {
+			return db.DeleteEventForUser(eventID, stranger)
+		}},
+		{"SetEventTagsForUser", func() error {
+			return db.SetEventTagsForUser(eventID, stranger, []string{"чужое"})
+		}},
+		{"GetEventRevisionsForUser", func() error {
+			_, err := db.GetEventRevisionsForUser(eventID, stranger)
+			return err
+		}},
+		{"RevertEventForUser", func() error {
+			_, _, err := db.RevertEventForUser(revisionID, stranger)
+			return err
+		}},
+	}
+
+	for _, check := range checks {
+		t.Run(check.name, func(t *testing.T) {
+			if err := check.call(); !isDenied(err) {
+				t.Errorf("ожидался отказ в доступе, получено: %v", err)
+			}
+		})
+	}
+
+	// Событие владельца не изменилось и не удалено
+	stored, err := db.GetEventByID(eventID)
+	if err != nil || stored == nil {
+		t.Fatalf("событие владельца пропало: %v", err)
+	}
+	if stored.Title != "Мама (новое)" {
+		t.Errorf("название изменено посторонним: %q", stored.Title)
+	}
+	tags, err := db.GetEventTags(eventID)
+	if err != nil {
+		t.Fatalf("GetEventTags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("теги изменены посторонним: %v", tags)
+	}
+
+	// Восстановить из корзины чужое событие тоже нельзя
+	if err := db.DeleteEventForUser(eventID, owner); err != nil {
+		t.Fatalf("DeleteEventForUser владельцем: %v", err)
+	}
+	if err := db.RestoreEventForUser(eventID, stranger, 30); !isDenied(err) {
+		t.Errorf("RestoreEventForUser: ожидался отказ в доступе, получено: %v", err)
+	}
+	if restored, _ := db.GetEventByID(eventID); restored != nil {
+		t.Error("событие восстановлено посторонним")
+	}
+}
+
+func TestMoveEventOutOfCalendar(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	editor := newTestUser(t, db, 2)
+	calendarID := newTestCalendar(t, db, owner, editor)
+
+	// Редактор не может забрать чужое событие календаря себе
+	ownerEventID := newTestEvent(t, db, owner, calendarID, "Бабушка")
+	if err := db.MoveEventForUser(ownerEventID, editor, 0); !isDenied(err) {
+		t.Errorf("MoveEventForUser редактором: ожидался отказ в доступе, получено: %v", err)
+	}
+	event, err := db.GetEventByID(ownerEventID)
+	if err != nil || event == nil {
+		t.Fatalf("событие календаря пропало: %v", err)
+	}
+	if event.UserID != owner || event.CalendarID != calendarID {
+		t.Errorf("событие у пользователя %d в календаре %d, ожидалось %d и %d",
+			event.UserID, event.CalendarID, owner, calendarID)
+	}
+
+	// Владелец календаря убирает событие редактора в личный список автора
+	editorEventID := newTestEvent(t, db, editor, calendarID, "Дедушка")
+	if err := db.MoveEventForUser(editorEventID, owner, 0); err != nil {
+		t.Fatalf("MoveEventForUser владельцем: %v", err)
+	}
+	event, err = db.GetEventByID(editorEventID)
+	if err != nil || event == nil {
+		t.Fatalf("событие пропало: %v", err)
+	}
+	if event.UserID != editor || event.CalendarID != 0 {
+		t.Errorf("событие у пользователя %d в календаре %d, ожидалось %d без календаря",
+			event.UserID, event.CalendarID, editor)
+	}
+}
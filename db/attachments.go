@@ -0,0 +1,134 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// MaxAttachments наибольшее число вложений у события: столько файлов
+// Telegram позволяет отправить одной группой
+const MaxAttachments = 10
+
+// ErrAttachmentLimit у события уже максимальное число вложений
+var ErrAttachmentLimit = errors.New("достигнуто максимальное число вложений")
+
+// attachmentColumns список полей вложения для выборок
+const attachmentColumns = "id, event_id, kind, file_id, created_at"
+
+// queryAttachments выполняет выборку вложений
+func (db *DB) queryAttachments(query string, args ...interface{}) ([]*models.Attachment, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вложений: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []*models.Attachment{}
+	for rows.Next() {
+		attachment := &models.Attachment{}
+		err := rows.Scan(&attachment.ID, &attachment.EventID, &attachment.Kind,
+			&attachment.FileID, &attachment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании вложения: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по вложениям: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// GetEventAttachments получает вложения события в порядке добавления
+func (db *DB) GetEventAttachments(eventID int64) ([]*models.Attachment, error) {
+	return db.queryAttachments(
+		"SELECT "+attachmentColumns+" FROM event_attachments WHERE event_id = ? ORDER BY id",
+		eventID,
+	)
+}
+
+// GetAttachmentsForUser получает вложения события, доступного пользователю
+func (db *DB) GetAttachmentsForUser(eventID, userID int64) ([]*models.Attachment, error) {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionView); err != nil {
+		return nil, err
+	}
+	return db.GetEventAttachments(eventID)
+}
+
+// GetAttachmentsByUserID получает вложения всех событий, автором которых
+// является пользователь
+func (db *DB) GetAttachmentsByUserID(userID int64) ([]*models.Attachment, error) {
+	return db.queryAttachments(
+		"SELECT "+attachmentColumns+" FROM event_attachments WHERE event_id IN (SELECT id FROM events WHERE user_id = ?) ORDER BY event_id, id",
+		userID,
+	)
+}
+
+// CountEventAttachments возвращает число вложений события
+func (db *DB) CountEventAttachments(eventID int64) (int, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM event_attachments WHERE event_id = ?", eventID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете вложений: %w", err)
+	}
+	return count, nil
+}
+
+// CreateAttachmentForUser прикрепляет фото или файл к событию, которое
+// пользователь может изменять
+func (db *DB) CreateAttachmentForUser(attachment *models.Attachment, userID int64) (int64, error) {
+	if attachment.Kind != models.AttachmentPhoto && attachment.Kind != models.AttachmentDocument {
+		return 0, fmt.Errorf("недопустимый вид вложения: %s", attachment.Kind)
+	}
+	if _, err := db.GetEventForUser(attachment.EventID, userID, PermissionEdit); err != nil {
+		return 0, err
+	}
+
+	count, err := db.CountEventAttachments(attachment.EventID)
+	if err != nil {
+		return 0, err
+	}
+	if count >= MaxAttachments {
+		return 0, ErrAttachmentLimit
+	}
+
+	result, err := db.Exec(
+		"INSERT INTO event_attachments (event_id, kind, file_id) VALUES (?, ?, ?)",
+		attachment.EventID, attachment.Kind, attachment.FileID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении вложения: %w", err)
+	}
+
+	attachmentID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID нового вложения: %w", err)
+	}
+	return attachmentID, nil
+}
+
+// DeleteAttachmentForUser удаляет вложение события, которое пользователь может изменять
+func (db *DB) DeleteAttachmentForUser(attachmentID, userID int64) (*models.Attachment, error) {
+	attachments, err := db.queryAttachments(
+		"SELECT "+attachmentColumns+" FROM event_attachments WHERE id = ?", attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(attachments) == 0 {
+		return nil, fmt.Errorf("вложение %d: %w", attachmentID, ErrEventNotFound)
+	}
+
+	attachment := attachments[0]
+	if _, err := db.GetEventForUser(attachment.EventID, userID, PermissionEdit); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec("DELETE FROM event_attachments WHERE id = ?", attachmentID); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении вложения: %w", err)
+	}
+	return attachment, nil
+}
@@ -0,0 +1,293 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Формат имени файла резервной копии: reminder-20060102-150405.db
+const (
+	backupPrefix     = "reminder-"
+	backupSuffix     = ".db"
+	backupTimeLayout = "20060102-150405"
+)
+
+// RetentionPolicy задает, сколько резервных копий хранить
+type RetentionPolicy struct {
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+}
+
+// BackupInfo описывает файл резервной копии
+type BackupInfo struct {
+	Path      string
+	CreatedAt time.Time
+}
+
+// Snapshot создает согласованный снимок базы данных с помощью VACUUM INTO
+func (db *DB) Snapshot(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("файл снимка %s уже существует", path)
+	}
+
+	if _, err := db.Exec("VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("ошибка при создании снимка базы данных: %w", err)
+	}
+	return nil
+}
+
+// Backup создает проверенную резервную копию в каталоге dir и применяет ротацию
+func (db *DB) Backup(dir string, policy RetentionPolicy) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("не удалось создать каталог резервных копий: %w", err)
+	}
+
+	name := backupPrefix + time.Now().Format(backupTimeLayout) + backupSuffix
+	path := filepath.Join(dir, name)
+	// Пишем во временный файл, чтобы незавершенный снимок не попал в ротацию
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+
+	if err := db.Snapshot(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+
+	if err := CheckIntegrity(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("резервная копия не прошла проверку: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("не удалось сохранить резервную копию: %w", err)
+	}
+
+	if err := RotateBackups(dir, policy); err != nil {
+		log.Printf("Ошибка при ротации резервных копий: %v", err)
+	}
+
+	return path, nil
+}
+
+// CheckIntegrity проверяет целостность файла базы данных
+func CheckIntegrity(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("файл базы данных недоступен: %w", err)
+	}
+
+	conn, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("не удалось открыть базу данных: %w", err)
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке целостности: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return fmt.Errorf("ошибка при чтении результата проверки: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при проверке целостности: %w", err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("база данных повреждена: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ListBackups возвращает резервные копии из каталога, начиная с самой новой
+func ListBackups(dir string) ([]BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("не удалось прочитать каталог резервных копий: %w", err)
+	}
+
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+		createdAt, err := time.ParseInLocation(backupTimeLayout, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, BackupInfo{
+			Path:      filepath.Join(dir, name),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+
+	return backups, nil
+}
+
+// RotateBackups удаляет резервные копии, не попадающие под правила хранения.
+// Для каждого из последних KeepDaily дней, KeepWeekly недель и KeepMonthly месяцев
+// сохраняется самая новая копия.
+func RotateBackups(dir string, policy RetentionPolicy) error {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool)
+	// Самую свежую копию храним всегда
+	if len(backups) > 0 {
+		keep[backups[0].Path] = true
+	}
+
+	markNewest := func(limit int, bucket func(time.Time) string) {
+		seen := make(map[string]bool)
+		for _, backup := range backups {
+			key := bucket(backup.CreatedAt)
+			if seen[key] {
+				continue
+			}
+			if len(seen) >= limit {
+				break
+			}
+			seen[key] = true
+			keep[backup.Path] = true
+		}
+	}
+
+	markNewest(policy.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	markNewest(policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	markNewest(policy.KeepMonthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+
+	for _, backup := range backups {
+		if keep[backup.Path] {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			log.Printf("Не удалось удалить старую резервную копию %s: %v", backup.Path, err)
+			continue
+		}
+		log.Printf("Удалена старая резервная копия %s", backup.Path)
+	}
+
+	return nil
+}
+
+// RestoreBackup заменяет базу данных dbPath снимком snapshotPath.
+// Бот должен быть остановлен: текущая база сохраняется рядом с суффиксом
+// .before-restore-<время>, а новая подменяется атомарным переименованием.
+// При ошибке уже отложенные файлы возвращаются на место.
+func RestoreBackup(dbPath, snapshotPath string) error {
+	if err := CheckIntegrity(snapshotPath); err != nil {
+		return fmt.Errorf("резервная копия не прошла проверку: %w", err)
+	}
+
+	dbDir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dbDir, 0755); err != nil {
+		return fmt.Errorf("не удалось создать директорию для БД: %w", err)
+	}
+
+	// Копируем снимок рядом с базой, чтобы переименование было атомарным
+	tmpPath := dbPath + ".restore-tmp"
+	if err := copyFile(snapshotPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("не удалось скопировать резервную копию: %w", err)
+	}
+
+	if err := CheckIntegrity(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("скопированная резервная копия повреждена: %w", err)
+	}
+
+	// Откладываем текущую базу вместе с WAL-файлами, чтобы их можно было вернуть
+	suffix := ".before-restore-" + time.Now().Format(backupTimeLayout)
+	var moved []string
+	for _, ext := range []string{"", "-wal", "-shm"} {
+		current := dbPath + ext
+		if _, err := os.Stat(current); err != nil {
+			continue
+		}
+		if err := os.Rename(current, current+suffix); err != nil {
+			os.Remove(tmpPath)
+			unmoveFiles(moved, suffix)
+			return fmt.Errorf("не удалось сохранить текущую базу данных: %w", err)
+		}
+		moved = append(moved, current)
+	}
+
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		os.Remove(tmpPath)
+		unmoveFiles(moved, suffix)
+		return fmt.Errorf("не удалось заменить базу данных: %w", err)
+	}
+
+	log.Printf("База данных восстановлена из %s, прежняя версия сохранена как %s", snapshotPath, dbPath+suffix)
+	return nil
+}
+
+// unmoveFiles возвращает на место файлы, отложенные с суффиксом suffix
+func unmoveFiles(paths []string, suffix string) {
+	for i := len(paths) - 1; i >= 0; i-- {
+		if err := os.Rename(paths[i]+suffix, paths[i]); err != nil {
+			log.Printf("Не удалось вернуть файл %s: %v", paths[i], err)
+		}
+	}
+}
+
+// copyFile копирует файл и сбрасывает его содержимое на диск
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// touchBackup создает пустой файл резервной копии с временем createdAt
+func touchBackup(t *testing.T, dir string, createdAt time.Time) string {
+	t.Helper()
+
+	path := filepath.Join(dir, backupPrefix+createdAt.Format(backupTimeLayout)+backupSuffix)
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestRotateBackups(t *testing.T) {
+	dir := t.TempDir()
+	at := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+	}
+
+	// 10 марта 2026 — вторник 11-й ISO-недели, 8 марта — воскресенье 10-й
+	kept := []string{
+		touchBackup(t, dir, at(2026, time.March, 10, 12)),    // день, неделя и месяц
+		touchBackup(t, dir, at(2026, time.March, 9, 12)),     // день
+		touchBackup(t, dir, at(2026, time.March, 8, 12)),     // день и неделя
+		touchBackup(t, dir, at(2026, time.February, 15, 12)), // месяц
+	}
+	removed := []string{
+		touchBackup(t, dir, at(2026, time.March, 10, 6)), // не самая новая за день
+		touchBackup(t, dir, at(2026, time.March, 7, 12)),
+		touchBackup(t, dir, at(2026, time.March, 1, 12)),
+		touchBackup(t, dir, at(2026, time.January, 15, 12)),
+		touchBackup(t, dir, at(2025, time.December, 15, 12)),
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	policy := RetentionPolicy{KeepDaily: 3, KeepWeekly: 2, KeepMonthly: 2}
+	if err := RotateBackups(dir, policy); err != nil {
+		t.Fatalf("RotateBackups: %v", err)
+	}
+
+	for _, path := range append(kept, other) {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("удален файл, который нужно хранить: %s", filepath.Base(path))
+		}
+	}
+	for _, path := range removed {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("не удалена старая копия: %s", filepath.Base(path))
+		}
+	}
+}
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "data", "reminder.db")
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	if err := db.InitSchema(); err != nil {
+		t.Fatalf("InitSchema: %v", err)
+	}
+	newTestUser(t, db, 1)
+
+	backupPath, err := db.Backup(filepath.Join(dir, "backups"), RetentionPolicy{KeepDaily: 7})
+	if err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+
+	// Пользователь, добавленный после копии, после восстановления пропадает
+	newTestUser(t, db, 2)
+	db.Close()
+
+	if err := RestoreBackup(dbPath, backupPath); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+
+	restored, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB после восстановления: %v", err)
+	}
+	defer restored.Close()
+
+	if user, err := restored.GetUserByTelegramID(1); err != nil || user == nil {
+		t.Errorf("пользователь из копии не найден: %v", err)
+	}
+	if user, err := restored.GetUserByTelegramID(2); err != nil || user != nil {
+		t.Errorf("пользователь, добавленный после копии, остался: %v, %v", user, err)
+	}
+
+	previous, err := filepath.Glob(dbPath + ".before-restore-*")
+	if err != nil || len(previous) == 0 {
+		t.Errorf("прежняя база не сохранена рядом: %v", err)
+	}
+}
+
+func TestRestoreBackupRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "reminder.db")
+
+	db, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB: %v", err)
+	}
+	if err := db.InitSchema(); err != nil {
+		t.Fatalf("InitSchema: %v", err)
+	}
+	snapshotPath := filepath.Join(dir, "snapshot.db")
+	if err := db.Snapshot(snapshotPath); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	newTestUser(t, db, 1)
+	db.Close()
+
+	// WAL-файл нельзя отложить: на его новом месте уже лежит непустой каталог.
+	// Основной файл к этому моменту уже отложен и должен вернуться на место.
+	if err := os.WriteFile(dbPath+"-wal", nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		blocker := dbPath + "-wal.before-restore-" + now.Add(time.Duration(i)*time.Second).Format(backupTimeLayout)
+		if err := os.MkdirAll(filepath.Join(blocker, "x"), 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+
+	if err := RestoreBackup(dbPath, snapshotPath); err == nil {
+		t.Fatal("RestoreBackup: ожидалась ошибка")
+	}
+
+	if _, err := os.Stat(dbPath + "-wal"); err != nil {
+		t.Errorf("WAL-файл пропал: %v", err)
+	}
+	if _, err := os.Stat(dbPath + ".restore-tmp"); !os.IsNotExist(err) {
+		t.Errorf("временный файл не удален: %v", err)
+	}
+
+	current, err := NewDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewDB после неудачного восстановления: %v", err)
+	}
+	defer current.Close()
+	if user, err := current.GetUserByTelegramID(1); err != nil || user == nil {
+		t.Errorf("прежняя база не возвращена на место: %v", err)
+	}
+}
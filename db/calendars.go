@@ -0,0 +1,325 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+var (
+	// ErrCalendarNotFound календарь не существует или пользователь в нем не участвует
+	ErrCalendarNotFound = errors.New("календарь не найден")
+	// ErrInviteNotFound приглашение не существует или календарь удален
+	ErrInviteNotFound = errors.New("приглашение не найдено")
+)
+
+// InvitePrefix начало кода приглашения в ссылке /start, по которому
+// приглашение в календарь отличается от других параметров
+const InvitePrefix = "cal_"
+
+// visibleEvents условие выборки событий, доступных пользователю: его личные
+// события, события общих календарей, где он участвует, и события, на которые
+// он подписан. ID пользователя передается в запрос трижды.
+const visibleEvents = "((calendar_id IS NULL AND user_id = ?) OR " +
+	"calendar_id IN (SELECT calendar_id FROM calendar_members WHERE user_id = ?) OR " +
+	"id IN (SELECT event_id FROM event_subscriptions WHERE user_id = ?))"
+
+// CanEditCalendar проверяет, может ли участник с ролью role изменять события календаря
+func CanEditCalendar(role string) bool {
+	return role == models.CalendarRoleOwner || role == models.CalendarRoleEditor
+}
+
+// calendarRole возвращает роль пользователя в календаре или пустую строку,
+// если пользователь в нем не участвует
+func (db *DB) calendarRole(calendarID, userID int64) (string, error) {
+	var role string
+	err := db.QueryRow(
+		"SELECT role FROM calendar_members WHERE calendar_id = ? AND user_id = ?",
+		calendarID, userID,
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("ошибка при получении роли в календаре: %w", err)
+	}
+	return role, nil
+}
+
+// CreateCalendar создает общий календарь, владельцем которого становится пользователь
+func (db *DB) CreateCalendar(name string, ownerID int64) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании календаря: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO calendars (name) VALUES (?)", name)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании календаря: %w", err)
+	}
+
+	calendarID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID нового календаря: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO calendar_members (calendar_id, user_id, role) VALUES (?, ?, ?)",
+		calendarID, ownerID, models.CalendarRoleOwner,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании календаря: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при создании календаря: %w", err)
+	}
+	return calendarID, nil
+}
+
+// queryCalendars выполняет выборку календарей вместе с ролью пользователя
+func (db *DB) queryCalendars(query string, args ...interface{}) ([]*models.Calendar, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении календарей: %w", err)
+	}
+	defer rows.Close()
+
+	calendars := []*models.Calendar{}
+	for rows.Next() {
+		calendar := &models.Calendar{}
+		if err := rows.Scan(&calendar.ID, &calendar.Name, &calendar.Role, &calendar.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании календаря: %w", err)
+		}
+		calendars = append(calendars, calendar)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по календарям: %w", err)
+	}
+
+	return calendars, nil
+}
+
+// GetCalendarsByUserID получает календари, в которых участвует пользователь
+func (db *DB) GetCalendarsByUserID(userID int64) ([]*models.Calendar, error) {
+	return db.queryCalendars(`
+		SELECT c.id, c.name, m.role, c.created_at FROM calendars c
+		JOIN calendar_members m ON m.calendar_id = c.id
+		WHERE m.user_id = ? ORDER BY c.name, c.id`,
+		userID,
+	)
+}
+
+// GetCalendarForUser получает календарь с ролью пользователя, если он в нем участвует
+func (db *DB) GetCalendarForUser(calendarID, userID int64) (*models.Calendar, error) {
+	calendars, err := db.queryCalendars(`
+		SELECT c.id, c.name, m.role, c.created_at FROM calendars c
+		JOIN calendar_members m ON m.calendar_id = c.id
+		WHERE c.id = ? AND m.user_id = ?`,
+		calendarID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(calendars) == 0 {
+		return nil, ErrCalendarNotFound
+	}
+	return calendars[0], nil
+}
+
+// GetCalendarMembers получает участников календаря, начиная с владельца
+func (db *DB) GetCalendarMembers(calendarID int64) ([]*models.CalendarMember, error) {
+	rows, err := db.Query(`
+		SELECT m.calendar_id, m.user_id, m.role, COALESCE(u.first_name, ''), COALESCE(u.username, ''), m.joined_at
+		FROM calendar_members m JOIN users u ON u.id = m.user_id
+		WHERE m.calendar_id = ?
+		ORDER BY m.role = ? DESC, m.joined_at, m.user_id`,
+		calendarID, models.CalendarRoleOwner,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении участников календаря: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*models.CalendarMember{}
+	for rows.Next() {
+		member := &models.CalendarMember{}
+		err := rows.Scan(&member.CalendarID, &member.UserID, &member.Role,
+			&member.FirstName, &member.Username, &member.JoinedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании участника календаря: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по участникам календаря: %w", err)
+	}
+
+	return members, nil
+}
+
+// requireOwner проверяет, что пользователь владеет календарем
+func (db *DB) requireOwner(calendarID, userID int64) error {
+	role, err := db.calendarRole(calendarID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrCalendarNotFound
+	}
+	if role != models.CalendarRoleOwner {
+		return fmt.Errorf("календарь %d: %w", calendarID, ErrAccessDenied)
+	}
+	return nil
+}
+
+// CreateCalendarInvite создает код приглашения в календарь с ролью role.
+// Приглашать может только владелец календаря.
+func (db *DB) CreateCalendarInvite(calendarID, userID int64, role string) (string, error) {
+	if role != models.CalendarRoleEditor && role != models.CalendarRoleViewer {
+		return "", fmt.Errorf("недопустимая роль приглашения: %s", role)
+	}
+	if err := db.requireOwner(calendarID, userID); err != nil {
+		return "", err
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("ошибка при создании кода приглашения: %w", err)
+	}
+	code := InvitePrefix + hex.EncodeToString(random)
+
+	_, err := db.Exec(
+		"INSERT INTO calendar_invites (code, calendar_id, role) VALUES (?, ?, ?)",
+		code, calendarID, role,
+	)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при сохранении приглашения: %w", err)
+	}
+	return code, nil
+}
+
+// JoinCalendar добавляет пользователя в календарь по коду приглашения.
+// Второе значение равно false, если пользователь уже участвует в календаре:
+// его роль при этом не меняется.
+func (db *DB) JoinCalendar(code string, userID int64) (*models.Calendar, bool, error) {
+	var calendarID int64
+	var role string
+	err := db.QueryRow(
+		"SELECT calendar_id, role FROM calendar_invites WHERE code = ?",
+		code,
+	).Scan(&calendarID, &role)
+	if err == sql.ErrNoRows {
+		return nil, false, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при получении приглашения: %w", err)
+	}
+
+	result, err := db.Exec(
+		"INSERT OR IGNORE INTO calendar_members (calendar_id, user_id, role) VALUES (?, ?, ?)",
+		calendarID, userID, role,
+	)
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при добавлении участника календаря: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при добавлении участника календаря: %w", err)
+	}
+
+	calendar, err := db.GetCalendarForUser(calendarID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return calendar, affected > 0, nil
+}
+
+// SetCalendarMemberRole меняет роль участника календаря. Роль меняет только
+// владелец, а владелец календаря остается владельцем.
+func (db *DB) SetCalendarMemberRole(calendarID, ownerID, memberID int64, role string) error {
+	if role != models.CalendarRoleEditor && role != models.CalendarRoleViewer {
+		return fmt.Errorf("недопустимая роль участника: %s", role)
+	}
+	if err := db.requireOwner(calendarID, ownerID); err != nil {
+		return err
+	}
+
+	result, err := db.Exec(
+		"UPDATE calendar_members SET role = ? WHERE calendar_id = ? AND user_id = ? AND role != ?",
+		role, calendarID, memberID, models.CalendarRoleOwner,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении роли участника: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении роли участника: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("участник %d календаря %d не найден", memberID, calendarID)
+	}
+	return nil
+}
+
+// RemoveCalendarMember исключает участника из календаря. Владелец может
+// исключить любого другого участника, остальные — только выйти сами.
+// Владелец не может выйти из календаря, он может только удалить его.
+func (db *DB) RemoveCalendarMember(calendarID, userID, memberID int64) error {
+	role, err := db.calendarRole(calendarID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrCalendarNotFound
+	}
+
+	if userID == memberID && role == models.CalendarRoleOwner {
+		return fmt.Errorf("владелец не может выйти из календаря %d: %w", calendarID, ErrAccessDenied)
+	}
+	if userID != memberID && role != models.CalendarRoleOwner {
+		return fmt.Errorf("календарь %d: %w", calendarID, ErrAccessDenied)
+	}
+
+	_, err = db.Exec(
+		"DELETE FROM calendar_members WHERE calendar_id = ? AND user_id = ? AND role != ?",
+		calendarID, memberID, models.CalendarRoleOwner,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при исключении участника календаря: %w", err)
+	}
+	return nil
+}
+
+// DeleteCalendar удаляет календарь. События календаря не удаляются,
+// а возвращаются в личные списки их авторов.
+func (db *DB) DeleteCalendar(calendarID, userID int64) error {
+	if err := db.requireOwner(calendarID, userID); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec("DELETE FROM calendars WHERE id = ?", calendarID); err != nil {
+		return fmt.Errorf("ошибка при удалении календаря: %w", err)
+	}
+	return nil
+}
+
+// GetCalendarName получает название календаря по его ID
+func (db *DB) GetCalendarName(calendarID int64) (string, error) {
+	var name string
+	err := db.QueryRow("SELECT name FROM calendars WHERE id = ?", calendarID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", ErrCalendarNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("ошибка при получении календаря: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import "testing"
+
+func TestDeleteUserKeepsCalendarEvents(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	editor := newTestUser(t, db, 2)
+
+	calendarID := newTestCalendar(t, db, owner, editor)
+
+	eventID := newTestEvent(t, db, editor, calendarID, "Бабушка")
+	personalID := newTestEvent(t, db, editor, 0, "Личное")
+
+	if err := db.DeleteUser(editor); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	event, err := db.GetEventByID(eventID)
+	if err != nil || event == nil {
+		t.Fatalf("событие общего календаря пропало: %v", err)
+	}
+	if event.UserID != owner || event.CalendarID != calendarID {
+		t.Errorf("событие у пользователя %d в календаре %d, ожидалось %d и %d",
+			event.UserID, event.CalendarID, owner, calendarID)
+	}
+
+	// Личные события удаленного пользователя удаляются вместе с ним
+	if personal, _ := db.GetEventByID(personalID); personal != nil {
+		t.Error("личное событие удаленного пользователя осталось")
+	}
+}
+
+func TestDeleteUserRollsBackOnError(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	editor := newTestUser(t, db, 2)
+
+	calendarID := newTestCalendar(t, db, owner, editor)
+	ownCalendarID := newTestCalendar(t, db, editor)
+	eventID := newTestEvent(t, db, editor, calendarID, "Бабушка")
+
+	// Без владельца календаря событие некому передать, и передача срывается
+	// уже после удаления собственного календаря пользователя
+	_, err := db.Exec("DELETE FROM calendar_members WHERE calendar_id = ? AND user_id = ?", calendarID, owner)
+	if err != nil {
+		t.Fatalf("удаление владельца календаря: %v", err)
+	}
+
+	if err := db.DeleteUser(editor); err == nil {
+		t.Fatal("DeleteUser: ожидалась ошибка")
+	}
+
+	if user, err := db.GetUserByID(editor); err != nil || user == nil {
+		t.Fatalf("пользователь удален, несмотря на ошибку: %v", err)
+	}
+	if _, err := db.GetCalendarForUser(ownCalendarID, editor); err != nil {
+		t.Errorf("календарь пользователя удален, несмотря на ошибку: %v", err)
+	}
+	event, err := db.GetEventByID(eventID)
+	if err != nil || event == nil {
+		t.Fatalf("событие пропало: %v", err)
+	}
+	if event.UserID != editor {
+		t.Errorf("событие передано пользователю %d, ожидалось %d", event.UserID, editor)
+	}
+}
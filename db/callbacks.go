@@ -0,0 +1,51 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SaveCallbackPayload сохраняет данные inline-кнопки, не поместившиеся в callback_data
+func (db *DB) SaveCallbackPayload(payload string) (int64, error) {
+	result, err := db.Exec("INSERT INTO callback_payloads (payload) VALUES (?)", payload)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении данных кнопки: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID данных кнопки: %w", err)
+	}
+	return id, nil
+}
+
+// GetCallbackPayload получает сохраненные данные кнопки.
+// Если данные уже удалены, возвращает пустую строку.
+func (db *DB) GetCallbackPayload(id int64) (string, error) {
+	var payload string
+	err := db.QueryRow("SELECT payload FROM callback_payloads WHERE id = ?", id).Scan(&payload)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("ошибка при получении данных кнопки: %w", err)
+	}
+	return payload, nil
+}
+
+// PurgeCallbackPayloads удаляет данные кнопок старше maxAgeHours часов
+func (db *DB) PurgeCallbackPayloads(maxAgeHours int) (int64, error) {
+	result, err := db.Exec(
+		"DELETE FROM callback_payloads WHERE created_at < datetime('now', ?)",
+		fmt.Sprintf("-%d hours", maxAgeHours),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке данных кнопок: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке данных кнопок: %w", err)
+	}
+	return purged, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -25,7 +26,9 @@ func NewDB(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("не удалось создать директорию для БД: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// Внешние ключи в SQLite выключены по умолчанию и включаются для каждого
+	// соединения отдельно, поэтому передаем прагму через DSN всего пула
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("не удалось открыть базу данных: %w", err)
 	}
@@ -34,6 +37,15 @@ func NewDB(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
 	}
 
+	// Без внешних ключей не сработает ON DELETE CASCADE
+	var foreignKeys int
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return nil, fmt.Errorf("не удалось проверить поддержку внешних ключей: %w", err)
+	}
+	if foreignKeys != 1 {
+		return nil, fmt.Errorf("не удалось включить внешние ключи SQLite")
+	}
+
 	return &DB{db}, nil
 }
 
@@ -71,12 +83,403 @@ func (db *DB) InitSchema() error {
 		return fmt.Errorf("не удалось создать таблицу events: %w", err)
 	}
 
+	// Создаем таблицу типов событий. Каждый пользователь получает встроенные
+	// типы и может добавлять свои категории.
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS event_types (
+		id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL DEFAULT 'other',
+		name TEXT NOT NULL DEFAULT '',
+		emoji TEXT NOT NULL DEFAULT '',
+		notify_days INTEGER NOT NULL DEFAULT 1,
+		notification_time TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу event_types: %w", err)
+	}
+
+	// Создаем таблицу истории уведомлений
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		event_id INTEGER,
+		event_title TEXT NOT NULL,
+		days_left INTEGER NOT NULL,
+		sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE SET NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу notifications: %w", err)
+	}
+
+	// Создаем таблицу истории изменений событий
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS event_revisions (
+		id INTEGER PRIMARY KEY,
+		event_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		type TEXT NOT NULL,
+		event_date TEXT NOT NULL,
+		notify_days INTEGER NOT NULL,
+		description TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу event_revisions: %w", err)
+	}
+
+	// Создаем таблицу тегов событий
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS event_tags (
+		event_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (event_id, tag),
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу event_tags: %w", err)
+	}
+
+	// Создаем таблицы отложенных и повторных напоминаний и отметок «Готово»
+	for _, table := range reminderTables {
+		if _, err := db.Exec(table.schema); err != nil {
+			return fmt.Errorf("не удалось создать таблицу %s: %w", table.name, err)
+		}
+	}
+
+	// Создаем таблицы общих календарей, их участников и приглашений
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS calendars (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу calendars: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS calendar_members (
+		calendar_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (calendar_id, user_id),
+		FOREIGN KEY (calendar_id) REFERENCES calendars(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу calendar_members: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS calendar_invites (
+		code TEXT PRIMARY KEY,
+		calendar_id INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (calendar_id) REFERENCES calendars(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу calendar_invites: %w", err)
+	}
+
+	// Создаем таблицы подписок на отдельные события и приглашений к ним
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS event_subscriptions (
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		subscribed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (event_id, user_id),
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу event_subscriptions: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS event_share_invites (
+		code TEXT PRIMARY KEY,
+		event_id INTEGER NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу event_share_invites: %w", err)
+	}
+
+	// Создаем таблицу шаблонов поздравлений
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS greeting_templates (
+		id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу greeting_templates: %w", err)
+	}
+
+	// Создаем таблицу идей подарков к событиям
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS gifts (
+		id INTEGER PRIMARY KEY,
+		event_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'idea',
+		price TEXT NOT NULL DEFAULT '',
+		link TEXT NOT NULL DEFAULT '',
+		year INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу gifts: %w", err)
+	}
+
+	// Создаем таблицу вложений событий: фото и файлы хранятся в Telegram,
+	// в БД только их file_id
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS event_attachments (
+		id INTEGER PRIMARY KEY,
+		event_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		file_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу event_attachments: %w", err)
+	}
+
+	// Создаем таблицу задач подготовки к событиям. due_days за сколько дней
+	// до события напомнить (-1 без напоминания), done_for дата наступления,
+	// к которому задача выполнена
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS event_tasks (
+		id INTEGER PRIMARY KEY,
+		event_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		due_days INTEGER NOT NULL DEFAULT -1,
+		done_for TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу event_tasks: %w", err)
+	}
+
+	// Создаем таблицу напоминаний о задачах, отложенных на конец тихих часов
+	// или паузы. due_days срок задач, о которых нужно напомнить.
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS task_snoozes (
+		id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		occurrence TEXT NOT NULL,
+		due_days INTEGER NOT NULL,
+		remind_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, event_id, occurrence, due_days),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу task_snoozes: %w", err)
+	}
+
+	// Создаем таблицу данных inline-кнопок, не поместившихся в callback_data
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS callback_payloads (
+		id INTEGER PRIMARY KEY,
+		payload TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу callback_payloads: %w", err)
+	}
+
+	// Добавляем новые колонки в таблицы, созданные прежними версиями
+	if err := db.addColumn("events", "deleted_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := db.addColumn("users", "language", "TEXT DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := db.addColumn("events", "type_id", "INTEGER REFERENCES event_types(id) ON DELETE SET NULL"); err != nil {
+		return err
+	}
+	if err := db.addColumn("event_revisions", "type_id", "INTEGER"); err != nil {
+		return err
+	}
+	if err := db.addColumn("events", "recurrence", "TEXT NOT NULL DEFAULT 'yearly'"); err != nil {
+		return err
+	}
+	if err := db.addColumn("events", "leap_policy", "TEXT NOT NULL DEFAULT 'feb28'"); err != nil {
+		return err
+	}
+	// Ревизии до появления правила повторения откатываются без его изменения
+	if err := db.addColumn("event_revisions", "recurrence", "TEXT"); err != nil {
+		return err
+	}
+	if err := db.addColumn("event_revisions", "leap_policy", "TEXT"); err != nil {
+		return err
+	}
+	if err := db.addColumn("events", "priority", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.addColumn("events", "nag_hours", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	// В ревизиях, сохраненных до появления полей, они остаются NULL,
+	// и откат к таким ревизиям не меняет текущие значения
+	if err := db.addColumn("event_revisions", "priority", "INTEGER"); err != nil {
+		return err
+	}
+	if err := db.addColumn("event_revisions", "nag_hours", "INTEGER"); err != nil {
+		return err
+	}
+	// NULL — подписчику напоминают за столько дней, сколько указал автор
+	if err := db.addColumn("event_subscriptions", "notify_days", "INTEGER"); err != nil {
+		return err
+	}
+	if err := db.addColumn("users", "daily_digest", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.addColumn("users", "weekly_digest", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := db.addColumn("users", "quiet_start", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := db.addColumn("users", "quiet_end", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := db.addColumn("users", "dnd_until", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := db.addColumn("events", "calendar_id", "INTEGER REFERENCES calendars(id) ON DELETE SET NULL"); err != nil {
+		return err
+	}
+	if err := db.addColumn("events", "greeting_id", "INTEGER REFERENCES greeting_templates(id) ON DELETE SET NULL"); err != nil {
+		return err
+	}
+	if err := db.addColumn("event_types", "greeting_id", "INTEGER REFERENCES greeting_templates(id) ON DELETE SET NULL"); err != nil {
+		return err
+	}
+	// Место встречи и напоминание о выезде
+	for _, column := range []struct{ name, definition string }{
+		{"location_lat", "REAL NOT NULL DEFAULT 0"},
+		{"location_lon", "REAL NOT NULL DEFAULT 0"},
+		{"venue", "TEXT NOT NULL DEFAULT ''"},
+		{"venue_address", "TEXT NOT NULL DEFAULT ''"},
+		{"meeting_time", "TEXT NOT NULL DEFAULT ''"},
+		{"travel_minutes", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		if err := db.addColumn("events", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	if err := db.migrateReminderTables(); err != nil {
+		return err
+	}
+
+	// Переводим типы событий из русских названий в ключи
+	for legacy, key := range models.LegacyEventTypes {
+		for _, table := range []string{"events", "event_revisions"} {
+			_, err := db.Exec(fmt.Sprintf("UPDATE %s SET type = ? WHERE type = ?", table), key, legacy)
+			if err != nil {
+				return fmt.Errorf("не удалось обновить типы событий в %s: %w", table, err)
+			}
+		}
+	}
+
+	if err := db.migrateEventTypes(); err != nil {
+		return err
+	}
+
+	if err := db.initSearchIndex(); err != nil {
+		return err
+	}
+
 	log.Println("Схема базы данных успешно инициализирована")
 	return nil
 }
 
-// CreateUser создает нового пользователя
-func (db *DB) CreateUser(telegramID int64, username, firstName, lastName string) (int64, error) {
+// addColumn добавляет колонку в таблицу, если ее еще нет
+func (db *DB) addColumn(table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("не удалось получить структуру таблицы %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			columnType string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return fmt.Errorf("не удалось прочитать структуру таблицы %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("не удалось прочитать структуру таблицы %s: %w", table, err)
+	}
+	rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("не удалось добавить колонку %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// userColumns список полей пользователя для выборок
+const userColumns = "id, telegram_id, username, first_name, last_name, notification_time, language, " +
+	"daily_digest, weekly_digest, quiet_start, quiet_end, dnd_until, created_at"
+
+// scanUser считывает пользователя из строки выборки по userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	var dndUntil sql.NullString
+
+	err := row.Scan(
+		&user.ID, &user.TelegramID, &user.Username, &user.FirstName,
+		&user.LastName, &user.NotificationTime, &user.Language,
+		&user.DailyDigest, &user.WeeklyDigest, &user.QuietStart, &user.QuietEnd,
+		&dndUntil, &user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if dndUntil.Valid {
+		if until, err := time.Parse(sqliteTimeLayout, dndUntil.String); err == nil {
+			user.DNDUntil = &until
+		}
+	}
+	return user, nil
+}
+
+// CreateUser создает нового пользователя с языком интерфейса language
+func (db *DB) CreateUser(telegramID int64, username, firstName, lastName, language string) (int64, error) {
 	// Проверяем, существует ли пользователь
 	var userID int64
 	err := db.QueryRow(
@@ -95,8 +498,8 @@ func (db *DB) CreateUser(telegramID int64, username, firstName, lastName string)
 
 	// Создаем нового пользователя
 	result, err := db.Exec(
-		"INSERT INTO users (telegram_id, username, first_name, last_name) VALUES (?, ?, ?, ?)",
-		telegramID, username, firstName, lastName,
+		"INSERT INTO users (telegram_id, username, first_name, last_name, language) VALUES (?, ?, ?, ?, ?)",
+		telegramID, username, firstName, lastName, language,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при создании пользователя: %w", err)
@@ -107,17 +510,36 @@ func (db *DB) CreateUser(telegramID int64, username, firstName, lastName string)
 		return 0, fmt.Errorf("ошибка при получении ID нового пользователя: %w", err)
 	}
 
+	if err := db.seedEventTypes(userID); err != nil {
+		return 0, err
+	}
+
 	return userID, nil
 }
 
+// GetUserByID получает пользователя по его внутреннему ID
+func (db *DB) GetUserByID(userID int64) (*models.User, error) {
+	user, err := scanUser(db.QueryRow(
+		"SELECT "+userColumns+" FROM users WHERE id = ?",
+		userID,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении пользователя: %w", err)
+	}
+
+	return user, nil
+}
+
 // GetUserByTelegramID получает пользователя по его Telegram ID
 func (db *DB) GetUserByTelegramID(telegramID int64) (*models.User, error) {
-	user := &models.User{}
-
-	err := db.QueryRow(
-		"SELECT id, telegram_id, username, first_name, last_name, notification_time, created_at FROM users WHERE telegram_id = ?",
+	user, err := scanUser(db.QueryRow(
+		"SELECT "+userColumns+" FROM users WHERE telegram_id = ?",
 		telegramID,
-	).Scan(&user.ID, &user.TelegramID, &user.Username, &user.FirstName, &user.LastName, &user.NotificationTime, &user.CreatedAt)
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -141,11 +563,116 @@ func (db *DB) SetUserNotificationTime(userID int64, notificationTime string) err
 	return nil
 }
 
+// SetUserLanguage устанавливает язык интерфейса пользователя
+func (db *DB) SetUserLanguage(userID int64, language string) error {
+	_, err := db.Exec(
+		"UPDATE users SET language = ? WHERE id = ?",
+		language, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении языка: %w", err)
+	}
+	return nil
+}
+
+// SetUserDigest включает или выключает ежедневную и еженедельную сводки
+func (db *DB) SetUserDigest(userID int64, daily, weekly bool) error {
+	_, err := db.Exec(
+		"UPDATE users SET daily_digest = ?, weekly_digest = ? WHERE id = ?",
+		daily, weekly, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении настроек сводки: %w", err)
+	}
+	return nil
+}
+
+// SetUserQuietHours устанавливает тихие часы пользователя в формате ЧЧ:ММ.
+// Пустые значения отключают тихие часы.
+func (db *DB) SetUserQuietHours(userID int64, start, end string) error {
+	_, err := db.Exec(
+		"UPDATE users SET quiet_start = ?, quiet_end = ? WHERE id = ?",
+		start, end, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении тихих часов: %w", err)
+	}
+	return nil
+}
+
+// SetUserDND приостанавливает напоминания до времени until. nil снимает паузу.
+func (db *DB) SetUserDND(userID int64, until *time.Time) error {
+	var value interface{}
+	if until != nil {
+		value = until.UTC().Format(sqliteTimeLayout)
+	}
+
+	_, err := db.Exec("UPDATE users SET dnd_until = ? WHERE id = ?", value, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении паузы напоминаний: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser удаляет пользователя вместе со всеми его данными.
+// События и история уведомлений удаляются каскадно через внешние ключи.
+// Календари, которыми владел пользователь, удаляются, а их события остаются
+// у авторов. События пользователя в чужих календарях переходят к владельцам
+// этих календарей, чтобы не пропасть у остальных участников. Все изменения
+// выполняются в одной транзакции: при ошибке данные пользователя не меняются.
+func (db *DB) DeleteUser(userID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении пользователя: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"DELETE FROM calendars WHERE id IN (SELECT calendar_id FROM calendar_members WHERE user_id = ? AND role = ?)",
+		userID, models.CalendarRoleOwner,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении календарей пользователя: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE events SET user_id = (
+			SELECT m.user_id FROM calendar_members m
+			WHERE m.calendar_id = events.calendar_id AND m.role = ?
+		)
+		WHERE calendar_id IS NOT NULL AND user_id = ?`,
+		models.CalendarRoleOwner, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при передаче событий владельцам календарей: %w", err)
+	}
+
+	result, err := tx.Exec("DELETE FROM users WHERE id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении пользователя: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении пользователя: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("пользователь %d не найден", userID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при удалении пользователя: %w", err)
+	}
+	return nil
+}
+
 // CreateEvent создает новое событие
 func (db *DB) CreateEvent(event *models.Event) (int64, error) {
 	result, err := db.Exec(
-		"INSERT INTO events (user_id, title, type, event_date, notify_days, description) VALUES (?, ?, ?, ?, ?, ?)",
-		event.UserID, event.Title, event.Type, event.EventDate, event.NotifyDays, event.Description,
+		"INSERT INTO events (user_id, calendar_id, title, type, type_id, event_date, notify_days, description, recurrence, leap_policy, priority, nag_hours) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		event.UserID, nullableID(event.CalendarID), event.Title, event.Type, nullableID(event.TypeID), event.EventDate, event.NotifyDays, event.Description,
+		recurrenceOrDefault(event.Recurrence), leapPolicyOrDefault(event.LeapPolicy), event.Priority, event.NagHours,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при создании события: %w", err)
@@ -159,24 +686,91 @@ func (db *DB) CreateEvent(event *models.Event) (int64, error) {
 	return eventID, nil
 }
 
-// GetEventsByUserID получает все события пользователя
-func (db *DB) GetEventsByUserID(userID int64) ([]*models.Event, error) {
-	rows, err := db.Query(
-		"SELECT id, user_id, title, type, event_date, notify_days, description, created_at FROM events WHERE user_id = ? ORDER BY event_date",
-		userID,
+// eventColumns список полей события для выборок
+const eventColumns = "id, user_id, calendar_id, title, type, type_id, event_date, notify_days, description, " +
+	"recurrence, leap_policy, priority, nag_hours, greeting_id, location_lat, location_lon, venue, venue_address, " +
+	"meeting_time, travel_minutes, created_at, deleted_at"
+
+// qualifiedEventColumns поля события с псевдонимом таблицы e для выборок с JOIN
+var qualifiedEventColumns = "e." + strings.ReplaceAll(eventColumns, ", ", ", e.")
+
+// rowScanner общий интерфейс для *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// extraScanner считывает дополнительные поля, выбранные после полей события
+type extraScanner struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// scanEvent считывает событие из строки выборки по eventColumns
+func scanEvent(row rowScanner) (*models.Event, error) {
+	event := &models.Event{}
+	var typeID, calendarID, greetingID sql.NullInt64
+	var deletedAt sql.NullTime
+
+	err := row.Scan(
+		&event.ID, &event.UserID, &calendarID, &event.Title, &event.Type, &typeID,
+		&event.EventDate, &event.NotifyDays, &event.Description,
+		&event.Recurrence, &event.LeapPolicy, &event.Priority, &event.NagHours,
+		&greetingID, &event.Latitude, &event.Longitude, &event.Venue, &event.VenueAddress,
+		&event.MeetingTime, &event.TravelMinutes, &event.CreatedAt, &deletedAt,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении событий пользователя: %w", err)
+		return nil, err
+	}
+
+	event.TypeID = typeID.Int64
+	event.CalendarID = calendarID.Int64
+	event.GreetingID = greetingID.Int64
+
+	if deletedAt.Valid {
+		event.DeletedAt = &deletedAt.Time
+	}
+	return event, nil
+}
+
+// recurrenceOrDefault возвращает повторение события, по умолчанию ежегодное
+func recurrenceOrDefault(recurrence string) string {
+	if recurrence == "" {
+		return models.RecurrenceYearly
+	}
+	return recurrence
+}
+
+// leapPolicyOrDefault возвращает правило для 29 февраля, по умолчанию 28 февраля
+func leapPolicyOrDefault(leapPolicy string) string {
+	if leapPolicy == "" {
+		return models.LeapPolicyFeb28
+	}
+	return leapPolicy
+}
+
+// nullableID возвращает NULL для нулевого ID, чтобы не нарушать внешние ключи
+func nullableID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// queryEvents выполняет выборку событий и считывает результат
+func (db *DB) queryEvents(query string, args ...interface{}) ([]*models.Event, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении событий: %w", err)
 	}
 	defer rows.Close()
 
 	events := []*models.Event{}
 	for rows.Next() {
-		event := &models.Event{}
-		err := rows.Scan(
-			&event.ID, &event.UserID, &event.Title, &event.Type, 
-			&event.EventDate, &event.NotifyDays, &event.Description, &event.CreatedAt,
-		)
+		event, err := scanEvent(rows)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка при сканировании данных события: %w", err)
 		}
@@ -190,14 +784,21 @@ func (db *DB) GetEventsByUserID(userID int64) ([]*models.Event, error) {
 	return events, nil
 }
 
-// GetEventByID получает событие по его ID
-func (db *DB) GetEventByID(eventID int64) (*models.Event, error) {
-	event := &models.Event{}
+// GetEventsByUserID получает все события пользователя и его общих календарей,
+// кроме удаленных в корзину
+func (db *DB) GetEventsByUserID(userID int64) ([]*models.Event, error) {
+	return db.queryEvents(
+		"SELECT "+eventColumns+" FROM events WHERE "+visibleEvents+" AND deleted_at IS NULL ORDER BY event_date",
+		userID, userID, userID,
+	)
+}
 
-	err := db.QueryRow(
-		"SELECT id, user_id, title, type, event_date, notify_days, description, created_at FROM events WHERE id = ?",
+// GetEventByID получает событие по его ID, если оно не в корзине
+func (db *DB) GetEventByID(eventID int64) (*models.Event, error) {
+	event, err := scanEvent(db.QueryRow(
+		"SELECT "+eventColumns+" FROM events WHERE id = ? AND deleted_at IS NULL",
 		eventID,
-	).Scan(&event.ID, &event.UserID, &event.Title, &event.Type, &event.EventDate, &event.NotifyDays, &event.Description, &event.CreatedAt)
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -209,83 +810,49 @@ func (db *DB) GetEventByID(eventID int64) (*models.Event, error) {
 	return event, nil
 }
 
-// UpdateEvent обновляет событие
-func (db *DB) UpdateEvent(event *models.Event) error {
-	_, err := db.Exec(
-		"UPDATE events SET title = ?, type = ?, event_date = ?, notify_days = ?, description = ? WHERE id = ?",
-		event.Title, event.Type, event.EventDate, event.NotifyDays, event.Description, event.ID,
-	)
+// UpdateEvent обновляет событие, сохраняя прежние значения в истории изменений.
+// Возвращает ID созданной ревизии, по которой изменение можно откатить.
+func (db *DB) UpdateEvent(event *models.Event) (int64, error) {
+	tx, err := db.Begin()
 	if err != nil {
-		return fmt.Errorf("ошибка при обновлении события: %w", err)
+		return 0, fmt.Errorf("ошибка при обновлении события: %w", err)
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-// DeleteEvent удаляет событие
-func (db *DB) DeleteEvent(eventID int64) error {
-	_, err := db.Exec("DELETE FROM events WHERE id = ?", eventID)
+	revisionID, err := saveRevision(tx, event.ID)
 	if err != nil {
-		return fmt.Errorf("ошибка при удалении события: %w", err)
+		return 0, err
 	}
-	return nil
-}
 
-// GetUpcomingEvents получает предстоящие события для отправки уведомлений
-func (db *DB) GetUpcomingEvents() ([]*models.Event, error) {
-	// Текущая дата
-	//currentYear := time.Now().Year()
-	currentMonth := time.Now().Month()
-	currentDay := time.Now().Day()
-	
-	// SQL запрос для выборки предстоящих событий
-	rows, err := db.Query(`
-		SELECT e.id, e.user_id, e.title, e.type, e.event_date, e.notify_days, e.description, 
-		       u.telegram_id, u.notification_time
-		FROM events e
-		JOIN users u ON e.user_id = u.id
-		WHERE (
-			CAST(strftime('%m', e.event_date) AS INTEGER) = ? AND
-			CAST(strftime('%d', e.event_date) AS INTEGER) - e.notify_days = ?
-		) OR (
-			CAST(strftime('%m', e.event_date) AS INTEGER) = ? AND
-			CAST(strftime('%d', e.event_date) AS INTEGER) = ?
-		)`,
-		currentMonth, currentDay, currentMonth, currentDay)
-	
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении предстоящих событий: %w", err)
+	_, err = tx.Exec(
+		"UPDATE events SET title = ?, type = ?, type_id = ?, event_date = ?, notify_days = ?, description = ?, "+
+			"recurrence = ?, leap_policy = ?, priority = ?, nag_hours = ? WHERE id = ?",
+		event.Title, event.Type, nullableID(event.TypeID), event.EventDate, event.NotifyDays, event.Description,
+		recurrenceOrDefault(event.Recurrence), leapPolicyOrDefault(event.LeapPolicy), event.Priority, event.NagHours, event.ID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при обновлении события: %w", err)
 	}
-	defer rows.Close()
 
-	events := []*models.Event{}
-	for rows.Next() {
-		event := &models.Event{}
-		var telegramID int64
-		var notificationTime string
-		
-		err := rows.Scan(
-			&event.ID, &event.UserID, &event.Title, &event.Type, 
-			&event.EventDate, &event.NotifyDays, &event.Description,
-			&telegramID, notificationTime,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("ошибка при сканировании данных предстоящего события: %w", err)
-		}
-		
-		events = append(events, event)
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при обновлении события: %w", err)
 	}
+	return revisionID, nil
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка при итерации по предстоящим событиям: %w", err)
+// DeleteEvent перемещает событие в корзину
+func (db *DB) DeleteEvent(eventID int64) error {
+	_, err := db.Exec("UPDATE events SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL", eventID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении события: %w", err)
 	}
-
-	return events, nil
+	return nil
 }
 
 // GetUsersForNotification получает пользователей для уведомлений в указанное время
 func (db *DB) GetUsersForNotification(notificationTime string) ([]*models.User, error) {
 	rows, err := db.Query(
-		"SELECT id, telegram_id, username, first_name, last_name, notification_time, created_at FROM users WHERE notification_time = ?",
+		"SELECT "+userColumns+" FROM users WHERE notification_time = ?",
 		notificationTime,
 	)
 	if err != nil {
@@ -295,11 +862,7 @@ func (db *DB) GetUsersForNotification(notificationTime string) ([]*models.User,
 
 	users := []*models.User{}
 	for rows.Next() {
-		user := &models.User{}
-		err := rows.Scan(
-			&user.ID, &user.TelegramID, &user.Username, &user.FirstName, 
-			&user.LastName, &user.NotificationTime, &user.CreatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("ошибка при сканировании данных пользователя: %w", err)
 		}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// eventTypeColumns список полей типа события для выборок
+const eventTypeColumns = "id, user_id, kind, name, emoji, notify_days, notification_time, greeting_id, created_at"
+
+// scanEventType считывает тип события из строки выборки по eventTypeColumns
+func scanEventType(row rowScanner) (*models.EventType, error) {
+	eventType := &models.EventType{}
+	var greetingID sql.NullInt64
+	err := row.Scan(
+		&eventType.ID, &eventType.UserID, &eventType.Kind, &eventType.Name,
+		&eventType.Emoji, &eventType.NotifyDays, &eventType.NotificationTime, &greetingID, &eventType.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	eventType.GreetingID = greetingID.Int64
+	return eventType, nil
+}
+
+// seedEventTypes создает пользователю встроенные типы событий
+func (db *DB) seedEventTypes(userID int64) error {
+	for _, kind := range models.EventTypes {
+		_, err := db.Exec(
+			"INSERT INTO event_types (user_id, kind, emoji) VALUES (?, ?, ?)",
+			userID, kind, models.EventTypeEmoji[kind],
+		)
+		if err != nil {
+			return fmt.Errorf("ошибка при создании встроенных типов событий: %w", err)
+		}
+	}
+	return nil
+}
+
+// migrateEventTypes создает типы событий пользователям из прежних версий
+// и связывает с ними существующие события
+func (db *DB) migrateEventTypes() error {
+	rows, err := db.Query(
+		"SELECT id FROM users WHERE NOT EXISTS (SELECT 1 FROM event_types t WHERE t.user_id = users.id)",
+	)
+	if err != nil {
+		return fmt.Errorf("не удалось получить пользователей без типов событий: %w", err)
+	}
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return fmt.Errorf("не удалось прочитать пользователя: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("не удалось получить пользователей без типов событий: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		if err := db.seedEventTypes(userID); err != nil {
+			return err
+		}
+	}
+
+	// Неизвестные типы, записанные текстом, превращаем в пользовательские категории
+	_, err = db.Exec(`
+		INSERT INTO event_types (user_id, kind, name)
+		SELECT DISTINCT e.user_id, ?, e.type FROM events e
+		WHERE e.type_id IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM event_types t WHERE t.user_id = e.user_id AND t.kind = e.type AND t.name = '')
+		  AND NOT EXISTS (SELECT 1 FROM event_types t WHERE t.user_id = e.user_id AND t.name = e.type)`,
+		models.EventTypeOther,
+	)
+	if err != nil {
+		return fmt.Errorf("не удалось перенести типы событий: %w", err)
+	}
+
+	_, err = db.Exec(`
+		UPDATE events SET
+			type_id = t.id, type = t.kind
+		FROM event_types t
+		WHERE events.type_id IS NULL AND t.user_id = events.user_id
+		  AND ((t.kind = events.type AND t.name = '') OR t.name = events.type)`,
+	)
+	if err != nil {
+		return fmt.Errorf("не удалось связать события с типами: %w", err)
+	}
+	return nil
+}
+
+// GetEventTypesByUserID получает типы событий пользователя
+func (db *DB) GetEventTypesByUserID(userID int64) ([]*models.EventType, error) {
+	rows, err := db.Query(
+		"SELECT "+eventTypeColumns+" FROM event_types WHERE user_id = ? ORDER BY id",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении типов событий: %w", err)
+	}
+	defer rows.Close()
+
+	eventTypes := []*models.EventType{}
+	for rows.Next() {
+		eventType, err := scanEventType(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании типа события: %w", err)
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по типам событий: %w", err)
+	}
+
+	return eventTypes, nil
+}
+
+// GetEventType получает тип события по его ID
+func (db *DB) GetEventType(typeID int64) (*models.EventType, error) {
+	eventType, err := scanEventType(db.QueryRow(
+		"SELECT "+eventTypeColumns+" FROM event_types WHERE id = ?",
+		typeID,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении типа события: %w", err)
+	}
+
+	return eventType, nil
+}
+
+// GetEventTypeForUser получает тип события, если он принадлежит пользователю
+func (db *DB) GetEventTypeForUser(typeID, userID int64) (*models.EventType, error) {
+	eventType, err := db.GetEventType(typeID)
+	if err != nil {
+		return nil, err
+	}
+	if eventType == nil {
+		return nil, fmt.Errorf("тип события %d не найден", typeID)
+	}
+	if eventType.UserID != userID {
+		return nil, fmt.Errorf("тип события %d: %w", typeID, ErrAccessDenied)
+	}
+	return eventType, nil
+}
+
+// CreateEventType создает пользовательский тип события
+func (db *DB) CreateEventType(eventType *models.EventType) (int64, error) {
+	result, err := db.Exec(
+		"INSERT INTO event_types (user_id, kind, name, emoji, notify_days, notification_time) VALUES (?, ?, ?, ?, ?, ?)",
+		eventType.UserID, eventType.Kind, eventType.Name, eventType.Emoji, eventType.NotifyDays, eventType.NotificationTime,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании типа события: %w", err)
+	}
+
+	typeID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID нового типа события: %w", err)
+	}
+
+	return typeID, nil
+}
+
+// UpdateEventType обновляет тип события
+func (db *DB) UpdateEventType(eventType *models.EventType) error {
+	_, err := db.Exec(
+		"UPDATE event_types SET name = ?, emoji = ?, notify_days = ?, notification_time = ? WHERE id = ? AND user_id = ?",
+		eventType.Name, eventType.Emoji, eventType.NotifyDays, eventType.NotificationTime, eventType.ID, eventType.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении типа события: %w", err)
+	}
+	return nil
+}
+
+// DeleteEventType удаляет тип события пользователя.
+// События этого типа остаются, их type_id обнуляется внешним ключом.
+func (db *DB) DeleteEventType(typeID, userID int64) error {
+	result, err := db.Exec("DELETE FROM event_types WHERE id = ? AND user_id = ?", typeID, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении типа события: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении типа события: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("тип события %d не найден", typeID)
+	}
+	return nil
+}
+
+// GetEventsForNotification получает события и получателей уведомлений, которые
+// отправляются в указанное время: время берется из типа события, а если оно не
+// задано — из настроек получателя. О событии общего календаря напоминается
+// каждому участнику, а о событии с подписчиками — и каждому подписчику, всем
+// по их собственным настройкам. Подписчик может задать, за сколько дней ему
+// напоминать, иначе напоминание приходит за столько дней, сколько указал автор.
+// Пользователи с ежедневной сводкой не попадают в выборку: их события
+// собираются в одно сообщение.
+func (db *DB) GetEventsForNotification(notificationTime string) ([]*models.EventRecipient, error) {
+	rows, err := db.Query(`
+		SELECT `+qualifiedEventColumns+`, u.id, COALESCE(sub.notify_days, e.notify_days) FROM events e
+		JOIN users u ON (e.calendar_id IS NULL AND u.id = e.user_id)
+		  OR u.id IN (SELECT m.user_id FROM calendar_members m WHERE m.calendar_id = e.calendar_id)
+		  OR u.id IN (SELECT s.user_id FROM event_subscriptions s WHERE s.event_id = e.id)
+		LEFT JOIN event_types t ON t.id = e.type_id AND t.user_id = u.id
+		LEFT JOIN event_subscriptions sub ON sub.event_id = e.id AND sub.user_id = u.id
+		WHERE e.deleted_at IS NULL AND u.daily_digest = 0
+		  AND COALESCE(NULLIF(t.notification_time, ''), u.notification_time) = ?
+		ORDER BY u.id, e.event_date`,
+		notificationTime,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении событий для уведомлений: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []*models.EventRecipient{}
+	for rows.Next() {
+		recipient := &models.EventRecipient{}
+		recipient.Event, err = scanEvent(extraScanner{rows, []interface{}{&recipient.UserID, &recipient.NotifyDays}})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании данных события: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по событиям: %w", err)
+	}
+
+	return recipients, nil
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// giftColumns список полей идеи подарка для выборок
+const giftColumns = "id, event_id, title, status, price, link, year, created_at"
+
+// queryGifts выполняет выборку идей подарков
+func (db *DB) queryGifts(query string, args ...interface{}) ([]*models.Gift, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении идей подарков: %w", err)
+	}
+	defer rows.Close()
+
+	gifts := []*models.Gift{}
+	for rows.Next() {
+		gift := &models.Gift{}
+		err := rows.Scan(&gift.ID, &gift.EventID, &gift.Title, &gift.Status,
+			&gift.Price, &gift.Link, &gift.Year, &gift.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании идеи подарка: %w", err)
+		}
+		gifts = append(gifts, gift)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по идеям подарков: %w", err)
+	}
+
+	return gifts, nil
+}
+
+// GetGiftsForUser получает идеи подарков к событию, доступному пользователю.
+// Подаренные идут последними, от новых к старым.
+func (db *DB) GetGiftsForUser(eventID, userID int64) ([]*models.Gift, error) {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionView); err != nil {
+		return nil, err
+	}
+	return db.queryGifts(
+		"SELECT "+giftColumns+" FROM gifts WHERE event_id = ? ORDER BY status = ?, year DESC, id",
+		eventID, models.GiftStatusGiven,
+	)
+}
+
+// GetGiftIdeas получает еще не купленные идеи подарков к событию для напоминания
+func (db *DB) GetGiftIdeas(eventID int64) ([]*models.Gift, error) {
+	return db.queryGifts(
+		"SELECT "+giftColumns+" FROM gifts WHERE event_id = ? AND status = ? ORDER BY id",
+		eventID, models.GiftStatusIdea,
+	)
+}
+
+// GetGiftsByUserID получает идеи подарков ко всем событиям, автором которых
+// является пользователь
+func (db *DB) GetGiftsByUserID(userID int64) ([]*models.Gift, error) {
+	return db.queryGifts(
+		"SELECT "+giftColumns+" FROM gifts WHERE event_id IN (SELECT id FROM events WHERE user_id = ?) ORDER BY event_id, id",
+		userID,
+	)
+}
+
+// GetGiftForUser получает идею подарка, если у пользователя есть нужные права на ее событие
+func (db *DB) GetGiftForUser(giftID, userID int64, perm Permission) (*models.Gift, error) {
+	gifts, err := db.queryGifts("SELECT "+giftColumns+" FROM gifts WHERE id = ?", giftID)
+	if err != nil {
+		return nil, err
+	}
+	if len(gifts) == 0 {
+		return nil, fmt.Errorf("подарок %d: %w", giftID, ErrEventNotFound)
+	}
+
+	if _, err := db.GetEventForUser(gifts[0].EventID, userID, perm); err != nil {
+		return nil, err
+	}
+	return gifts[0], nil
+}
+
+// CreateGiftForUser добавляет идею подарка к событию, которое пользователь может изменять
+func (db *DB) CreateGiftForUser(gift *models.Gift, userID int64) (int64, error) {
+	if _, err := db.GetEventForUser(gift.EventID, userID, PermissionEdit); err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(
+		"INSERT INTO gifts (event_id, title, status, price, link) VALUES (?, ?, ?, ?, ?)",
+		gift.EventID, gift.Title, models.GiftStatusIdea, gift.Price, gift.Link,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении идеи подарка: %w", err)
+	}
+
+	giftID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID новой идеи подарка: %w", err)
+	}
+	return giftID, nil
+}
+
+// SetGiftStatusForUser меняет статус идеи подарка. Подаренный подарок
+// попадает в историю года now, при возврате к другому статусу год сбрасывается.
+func (db *DB) SetGiftStatusForUser(giftID, userID int64, status string, now time.Time) error {
+	if status != models.GiftStatusIdea && status != models.GiftStatusBought && status != models.GiftStatusGiven {
+		return fmt.Errorf("недопустимый статус подарка: %s", status)
+	}
+	if _, err := db.GetGiftForUser(giftID, userID, PermissionEdit); err != nil {
+		return err
+	}
+
+	year := 0
+	if status == models.GiftStatusGiven {
+		year = now.Year()
+	}
+
+	if _, err := db.Exec("UPDATE gifts SET status = ?, year = ? WHERE id = ?", status, year, giftID); err != nil {
+		return fmt.Errorf("ошибка при изменении статуса подарка: %w", err)
+	}
+	return nil
+}
+
+// DeleteGiftForUser удаляет идею подарка к событию, которое пользователь может изменять
+func (db *DB) DeleteGiftForUser(giftID, userID int64) error {
+	if _, err := db.GetGiftForUser(giftID, userID, PermissionEdit); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec("DELETE FROM gifts WHERE id = ?", giftID); err != nil {
+		return fmt.Errorf("ошибка при удалении идеи подарка: %w", err)
+	}
+	return nil
+}
+
+// GetGivenGiftYear возвращает последний год, в котором к событию уже дарили
+// подарок с таким названием, или 0, если не дарили
+func (db *DB) GetGivenGiftYear(eventID int64, title string) (int, error) {
+	gifts, err := db.queryGifts(
+		"SELECT "+giftColumns+" FROM gifts WHERE event_id = ? AND status = ? ORDER BY year DESC",
+		eventID, models.GiftStatusGiven,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, gift := range gifts {
+		if sameGift(gift.Title, title) {
+			return gift.Year, nil
+		}
+	}
+	return 0, nil
+}
+
+// sameGift сравнивает названия подарков без учета регистра, пробелов и «ё»
+func sameGift(a, b string) bool {
+	normalize := func(s string) string {
+		return foldSearchText(strings.ToLower(strings.Join(strings.Fields(s), " ")))
+	}
+	return normalize(a) == normalize(b)
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// ErrGreetingNotFound шаблон поздравления не существует или принадлежит другому пользователю
+var ErrGreetingNotFound = errors.New("шаблон поздравления не найден")
+
+// GetGreetingTemplatesByUserID получает шаблоны поздравлений пользователя
+func (db *DB) GetGreetingTemplatesByUserID(userID int64) ([]*models.GreetingTemplate, error) {
+	rows, err := db.Query(
+		"SELECT id, user_id, text, created_at FROM greeting_templates WHERE user_id = ? ORDER BY id",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении шаблонов поздравлений: %w", err)
+	}
+	defer rows.Close()
+
+	greetings := []*models.GreetingTemplate{}
+	for rows.Next() {
+		greeting := &models.GreetingTemplate{}
+		if err := rows.Scan(&greeting.ID, &greeting.UserID, &greeting.Text, &greeting.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании шаблона поздравления: %w", err)
+		}
+		greetings = append(greetings, greeting)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по шаблонам поздравлений: %w", err)
+	}
+
+	return greetings, nil
+}
+
+// GetGreetingTemplateForUser получает шаблон поздравления, если он принадлежит пользователю
+func (db *DB) GetGreetingTemplateForUser(greetingID, userID int64) (*models.GreetingTemplate, error) {
+	greeting := &models.GreetingTemplate{}
+	err := db.QueryRow(
+		"SELECT id, user_id, text, created_at FROM greeting_templates WHERE id = ? AND user_id = ?",
+		greetingID, userID,
+	).Scan(&greeting.ID, &greeting.UserID, &greeting.Text, &greeting.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrGreetingNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении шаблона поздравления: %w", err)
+	}
+	return greeting, nil
+}
+
+// CreateGreetingTemplate создает шаблон поздравления пользователя
+func (db *DB) CreateGreetingTemplate(userID int64, text string) (int64, error) {
+	result, err := db.Exec("INSERT INTO greeting_templates (user_id, text) VALUES (?, ?)", userID, text)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании шаблона поздравления: %w", err)
+	}
+
+	greetingID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID нового шаблона поздравления: %w", err)
+	}
+	return greetingID, nil
+}
+
+// DeleteGreetingTemplate удаляет шаблон поздравления пользователя. У событий
+// и типов, к которым он был привязан, поздравление отключается.
+func (db *DB) DeleteGreetingTemplate(greetingID, userID int64) error {
+	result, err := db.Exec("DELETE FROM greeting_templates WHERE id = ? AND user_id = ?", greetingID, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении шаблона поздравления: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении шаблона поздравления: %w", err)
+	}
+	if affected == 0 {
+		return ErrGreetingNotFound
+	}
+	return nil
+}
+
+// checkGreeting проверяет, что пользователь может привязать шаблон. Нулевой
+// ID означает отключение поздравления.
+func (db *DB) checkGreeting(greetingID, userID int64) error {
+	if greetingID == 0 {
+		return nil
+	}
+	_, err := db.GetGreetingTemplateForUser(greetingID, userID)
+	return err
+}
+
+// SetEventGreetingForUser привязывает шаблон поздравления к событию, которое
+// пользователь может изменять. Нулевой greetingID отвязывает шаблон.
+func (db *DB) SetEventGreetingForUser(eventID, userID, greetingID int64) error {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+		return err
+	}
+	if err := db.checkGreeting(greetingID, userID); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec("UPDATE events SET greeting_id = ? WHERE id = ?", nullableID(greetingID), eventID); err != nil {
+		return fmt.Errorf("ошибка при изменении поздравления события: %w", err)
+	}
+	return nil
+}
+
+// SetEventTypeGreeting привязывает шаблон поздравления к типу события
+// пользователя. Нулевой greetingID отвязывает шаблон.
+func (db *DB) SetEventTypeGreeting(typeID, userID, greetingID int64) error {
+	if err := db.checkGreeting(greetingID, userID); err != nil {
+		return err
+	}
+
+	result, err := db.Exec(
+		"UPDATE event_types SET greeting_id = ? WHERE id = ? AND user_id = ?",
+		nullableID(greetingID), typeID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении поздравления типа события: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении поздравления типа события: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("тип события %d: %w", typeID, ErrAccessDenied)
+	}
+	return nil
+}
+
+// GetGreetingForEvent получает текст шаблона поздравления для напоминания
+// пользователю о событии: шаблон самого события, а если его нет — шаблон
+// типа события пользователя. Если шаблона нет, возвращается пустая строка.
+func (db *DB) GetGreetingForEvent(event *models.Event, userID int64) (string, error) {
+	var text string
+	err := db.QueryRow(`
+		SELECT text FROM greeting_templates WHERE id = COALESCE(
+			NULLIF(?, 0),
+			(SELECT greeting_id FROM event_types WHERE id = ? AND user_id = ?)
+		)`,
+		event.GreetingID, event.TypeID, userID,
+	).Scan(&text)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("ошибка при получении шаблона поздравления: %w", err)
+	}
+	return text, nil
+}
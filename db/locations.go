@@ -0,0 +1,87 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// MaxTravelMinutes наибольшее время в пути для напоминания о выезде
+const MaxTravelMinutes = 12 * 60
+
+// SetEventLocationForUser сохраняет место встречи события, которое
+// пользователь может изменять. Нулевые координаты удаляют место.
+func (db *DB) SetEventLocationForUser(eventID, userID int64, latitude, longitude float64, venue, address string) error {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(
+		"UPDATE events SET location_lat = ?, location_lon = ?, venue = ?, venue_address = ? WHERE id = ?",
+		latitude, longitude, venue, address, eventID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении места встречи: %w", err)
+	}
+	return nil
+}
+
+// SetEventLeaveByForUser сохраняет время встречи и время в пути для
+// напоминания о выезде. Пустое время встречи отключает напоминание.
+func (db *DB) SetEventLeaveByForUser(eventID, userID int64, meetingTime string, travelMinutes int) error {
+	if meetingTime != "" && (travelMinutes < 1 || travelMinutes > MaxTravelMinutes) {
+		return fmt.Errorf("недопустимое время в пути: %d", travelMinutes)
+	}
+	if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+		return err
+	}
+
+	if meetingTime == "" {
+		travelMinutes = 0
+	}
+	_, err := db.Exec(
+		"UPDATE events SET meeting_time = ?, travel_minutes = ? WHERE id = ?",
+		meetingTime, travelMinutes, eventID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении напоминания о выезде: %w", err)
+	}
+	return nil
+}
+
+// GetLeaveByRecipients получает события, из дома на которые нужно выезжать
+// в leaveTime (ЧЧ:ММ), и пользователей, которым о них нужно напомнить.
+// День события проверяет вызывающий: если дорога начинается до полуночи,
+// событие наступает на следующий день.
+func (db *DB) GetLeaveByRecipients(leaveTime string) ([]*models.EventRecipient, error) {
+	rows, err := db.Query(`
+		SELECT `+qualifiedEventColumns+`, u.id FROM events e
+		JOIN users u ON (e.calendar_id IS NULL AND u.id = e.user_id)
+		  OR u.id IN (SELECT m.user_id FROM calendar_members m WHERE m.calendar_id = e.calendar_id)
+		  OR u.id IN (SELECT s.user_id FROM event_subscriptions s WHERE s.event_id = e.id)
+		WHERE e.deleted_at IS NULL AND e.meeting_time != '' AND e.travel_minutes > 0
+		  AND strftime('%H:%M', e.meeting_time, '-' || e.travel_minutes || ' minutes') = ?
+		ORDER BY u.id, e.id`,
+		leaveTime,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении событий для напоминания о выезде: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []*models.EventRecipient{}
+	for rows.Next() {
+		recipient := &models.EventRecipient{}
+		recipient.Event, err = scanEvent(extraScanner{rows, []interface{}{&recipient.UserID}})
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании данных события: %w", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по событиям: %w", err)
+	}
+
+	return recipients, nil
+}
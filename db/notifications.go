@@ -0,0 +1,127 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// LogNotification сохраняет запись об отправленном уведомлении
+func (db *DB) LogNotification(userID int64, event *models.Event, daysLeft int) error {
+	_, err := db.Exec(
+		"INSERT INTO notifications (user_id, event_id, event_title, days_left) VALUES (?, ?, ?, ?)",
+		userID, event.ID, event.Title, daysLeft,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении истории уведомлений: %w", err)
+	}
+	return nil
+}
+
+// GetNotificationsByUserID получает историю уведомлений пользователя
+func (db *DB) GetNotificationsByUserID(userID int64) ([]*models.Notification, error) {
+	rows, err := db.Query(
+		"SELECT id, user_id, event_id, event_title, days_left, sent_at FROM notifications WHERE user_id = ? ORDER BY sent_at",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории уведомлений: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		notification := &models.Notification{}
+		err := rows.Scan(
+			&notification.ID, &notification.UserID, &notification.EventID,
+			&notification.EventTitle, &notification.DaysLeft, &notification.SentAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании истории уведомлений: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по истории уведомлений: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// ExportUserData собирает все данные пользователя для выгрузки
+func (db *DB) ExportUserData(telegramID int64) (*models.UserExport, error) {
+	user, err := db.GetUserByTelegramID(telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("пользователь не найден")
+	}
+
+	events, err := db.GetEventsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Добавляем события из корзины, они тоже принадлежат пользователю
+	deleted, err := db.queryEvents(
+		"SELECT "+eventColumns+" FROM events WHERE user_id = ? AND deleted_at IS NOT NULL ORDER BY deleted_at",
+		user.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, deleted...)
+
+	if err := db.attachTags(events); err != nil {
+		return nil, err
+	}
+
+	notifications, err := db.GetNotificationsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := db.GetEventTypesByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	greetings, err := db.GetGreetingTemplatesByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	gifts, err := db.GetGiftsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := db.GetAttachmentsByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := db.GetTasksByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserExport{
+		ExportedAt: time.Now(),
+		User:       user,
+		Settings: models.UserSettings{
+			NotificationTime: user.NotificationTime,
+			Language:         user.Language,
+		},
+		EventTypes:        eventTypes,
+		GreetingTemplates: greetings,
+		Events:            events,
+		Gifts:             gifts,
+		Attachments:       attachments,
+		Tasks:             tasks,
+		Notifications:     notifications,
+	}, nil
+}
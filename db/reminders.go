@@ -0,0 +1,283 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// sqliteTimeLayout формат времени, в котором SQLite хранит CURRENT_TIMESTAMP.
+// Время записывается в UTC, чтобы его можно было сравнивать как строки.
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// reminderTable таблица состояния напоминаний. Отложенные и повторные
+// напоминания и отметки «Готово» хранятся для каждого пользователя отдельно:
+// о событии общего календаря напоминается всем его участникам.
+type reminderTable struct {
+	name   string
+	schema string
+	// columns и legacySelect переносят данные из таблицы прежней версии,
+	// где состояние было общим для события
+	columns      string
+	legacySelect string
+}
+
+var reminderTables = []reminderTable{
+	{
+		name: "snoozes",
+		schema: `
+	CREATE TABLE IF NOT EXISTS snoozes (
+		id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		occurrence TEXT NOT NULL,
+		remind_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, event_id, occurrence),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`,
+		columns:      "user_id, event_id, occurrence, remind_at, created_at",
+		legacySelect: "SELECT user_id, event_id, occurrence, remind_at, created_at FROM snoozes_legacy",
+	},
+	{
+		name: "nags",
+		schema: `
+	CREATE TABLE IF NOT EXISTS nags (
+		id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		occurrence TEXT NOT NULL,
+		attempt INTEGER NOT NULL DEFAULT 0,
+		next_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, event_id, occurrence),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`,
+		columns:      "user_id, event_id, occurrence, attempt, next_at, created_at",
+		legacySelect: "SELECT user_id, event_id, occurrence, attempt, next_at, created_at FROM nags_legacy",
+	},
+	{
+		name: "acknowledgements",
+		schema: `
+	CREATE TABLE IF NOT EXISTS acknowledgements (
+		user_id INTEGER NOT NULL,
+		event_id INTEGER NOT NULL,
+		occurrence TEXT NOT NULL,
+		acknowledged_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, event_id, occurrence),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+	)`,
+		columns: "user_id, event_id, occurrence, acknowledged_at",
+		// Прежние отметки принадлежали автору события
+		legacySelect: "SELECT e.user_id, a.event_id, a.occurrence, a.acknowledged_at " +
+			"FROM acknowledgements_legacy a JOIN events e ON e.id = a.event_id",
+	},
+}
+
+// migrateReminderTables пересоздает таблицы напоминаний прежних версий,
+// в которых состояние напоминания было общим для всех пользователей
+func (db *DB) migrateReminderTables() error {
+	for _, table := range reminderTables {
+		var schema string
+		err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table.name).Scan(&schema)
+		if err != nil {
+			return fmt.Errorf("не удалось получить структуру таблицы %s: %w", table.name, err)
+		}
+		if strings.Contains(schema, "(user_id, event_id, occurrence)") {
+			continue
+		}
+
+		if err := db.rebuildReminderTable(table); err != nil {
+			return err
+		}
+		log.Printf("Таблица %s переведена на напоминания для каждого пользователя", table.name)
+	}
+	return nil
+}
+
+// rebuildReminderTable создает таблицу заново и переносит в нее данные
+func (db *DB) rebuildReminderTable(table reminderTable) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("не удалось обновить таблицу %s: %w", table.name, err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s_legacy", table.name, table.name),
+		table.schema,
+		fmt.Sprintf("INSERT OR IGNORE INTO %s (%s) %s", table.name, table.columns, table.legacySelect),
+		fmt.Sprintf("DROP TABLE %s_legacy", table.name),
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("не удалось обновить таблицу %s: %w", table.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось обновить таблицу %s: %w", table.name, err)
+	}
+	return nil
+}
+
+// SnoozeReminder откладывает напоминание о наступлении события occurrence
+// (дата в формате YYYY-MM-DD) до времени remindAt. Прежнее отложенное
+// напоминание о том же наступлении заменяется.
+func (db *DB) SnoozeReminder(userID, eventID int64, occurrence string, remindAt time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO snoozes (user_id, event_id, occurrence, remind_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (user_id, event_id, occurrence) DO UPDATE SET remind_at = excluded.remind_at`,
+		userID, eventID, occurrence, remindAt.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при откладывании напоминания: %w", err)
+	}
+	return nil
+}
+
+// GetDueSnoozes получает отложенные напоминания, время которых наступило к now
+func (db *DB) GetDueSnoozes(now time.Time) ([]*models.Snooze, error) {
+	rows, err := db.Query(
+		"SELECT id, user_id, event_id, occurrence, remind_at FROM snoozes WHERE remind_at <= ? ORDER BY remind_at",
+		now.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении отложенных напоминаний: %w", err)
+	}
+	defer rows.Close()
+
+	snoozes := []*models.Snooze{}
+	for rows.Next() {
+		snooze := &models.Snooze{}
+		err := rows.Scan(&snooze.ID, &snooze.UserID, &snooze.EventID, &snooze.Occurrence, &snooze.RemindAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании отложенного напоминания: %w", err)
+		}
+		snoozes = append(snoozes, snooze)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по отложенным напоминаниям: %w", err)
+	}
+
+	return snoozes, nil
+}
+
+// DeleteSnooze удаляет отложенное напоминание после отправки
+func (db *DB) DeleteSnooze(snoozeID int64) error {
+	if _, err := db.Exec("DELETE FROM snoozes WHERE id = ?", snoozeID); err != nil {
+		return fmt.Errorf("ошибка при удалении отложенного напоминания: %w", err)
+	}
+	return nil
+}
+
+// AcknowledgeOccurrence отмечает наступление события как выполненное для
+// пользователя и отменяет его отложенные и повторные напоминания
+func (db *DB) AcknowledgeOccurrence(userID, eventID int64, occurrence string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при отметке события: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT OR IGNORE INTO acknowledgements (user_id, event_id, occurrence) VALUES (?, ?, ?)",
+		userID, eventID, occurrence,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при отметке события: %w", err)
+	}
+
+	for _, table := range []string{"snoozes", "nags"} {
+		_, err = tx.Exec("DELETE FROM "+table+" WHERE user_id = ? AND event_id = ? AND occurrence = ?", userID, eventID, occurrence)
+		if err != nil {
+			return fmt.Errorf("ошибка при отметке события: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при отметке события: %w", err)
+	}
+	return nil
+}
+
+// IsOccurrenceAcknowledged проверяет, отметил ли пользователь наступление события
+func (db *DB) IsOccurrenceAcknowledged(userID, eventID int64, occurrence string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM acknowledgements WHERE user_id = ? AND event_id = ? AND occurrence = ?)",
+		userID, eventID, occurrence,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке отметки события: %w", err)
+	}
+	return exists, nil
+}
+
+// StartNag начинает повторные напоминания о наступлении события. Если повторы
+// для этого наступления уже идут, они не сбрасываются.
+func (db *DB) StartNag(userID, eventID int64, occurrence string, nextAt time.Time) error {
+	_, err := db.Exec(
+		"INSERT OR IGNORE INTO nags (user_id, event_id, occurrence, next_at) VALUES (?, ?, ?, ?)",
+		userID, eventID, occurrence, nextAt.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании повторного напоминания: %w", err)
+	}
+	return nil
+}
+
+// GetDueNags получает повторные напоминания, время которых наступило к now
+func (db *DB) GetDueNags(now time.Time) ([]*models.Nag, error) {
+	rows, err := db.Query(
+		"SELECT id, user_id, event_id, occurrence, attempt, next_at FROM nags WHERE next_at <= ? ORDER BY next_at",
+		now.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении повторных напоминаний: %w", err)
+	}
+	defer rows.Close()
+
+	nags := []*models.Nag{}
+	for rows.Next() {
+		nag := &models.Nag{}
+		err := rows.Scan(&nag.ID, &nag.UserID, &nag.EventID, &nag.Occurrence, &nag.Attempt, &nag.NextAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании повторного напоминания: %w", err)
+		}
+		nags = append(nags, nag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по повторным напоминаниям: %w", err)
+	}
+
+	return nags, nil
+}
+
+// UpdateNag сохраняет номер попытки и время следующего повторного напоминания
+func (db *DB) UpdateNag(nagID int64, attempt int, nextAt time.Time) error {
+	_, err := db.Exec(
+		"UPDATE nags SET attempt = ?, next_at = ? WHERE id = ?",
+		attempt, nextAt.UTC().Format(sqliteTimeLayout), nagID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении повторного напоминания: %w", err)
+	}
+	return nil
+}
+
+// DeleteNag прекращает повторные напоминания
+func (db *DB) DeleteNag(nagID int64) error {
+	if _, err := db.Exec("DELETE FROM nags WHERE id = ?", nagID); err != nil {
+		return fmt.Errorf("ошибка при удалении повторного напоминания: %w", err)
+	}
+	return nil
+}
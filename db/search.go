@@ -0,0 +1,284 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// SearchQuery разобранный поисковый запрос
+type SearchQuery struct {
+	// Terms слова для полнотекстового поиска по названию, описанию и тегам
+	Terms []string
+	// Tags теги из фильтров #тег, событие должно иметь их все
+	Tags []string
+	// Types названия типов из фильтров type:
+	Types []string
+	// TypeIDs типы событий, найденные по Types. Заполняется вызывающим кодом,
+	// потому что названия встроенных типов зависят от языка.
+	TypeIDs []int64
+}
+
+// IsEmpty проверяет, что в запросе нет ни слов, ни фильтров
+func (q *SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Tags) == 0 && len(q.Types) == 0
+}
+
+// ParseSearchQuery разбирает строку поиска. Поддерживаются фильтры #тег и
+// type:Название; название типа с пробелами записывается в кавычках:
+// type:"День рождения".
+func ParseSearchQuery(input string) SearchQuery {
+	var query SearchQuery
+
+	rest := strings.TrimSpace(input)
+	for rest != "" {
+		var token string
+		if strings.HasPrefix(strings.ToLower(rest), "type:\"") {
+			// Название типа в кавычках может содержать пробелы
+			value := rest[len("type:\""):]
+			end := strings.Index(value, "\"")
+			if end < 0 {
+				end = len(value)
+				rest = ""
+			} else {
+				rest = value[end+1:]
+			}
+			if name := strings.TrimSpace(value[:end]); name != "" {
+				query.Types = append(query.Types, name)
+			}
+			rest = strings.TrimSpace(rest)
+			continue
+		}
+
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			token, rest = rest[:i], strings.TrimSpace(rest[i:])
+		} else {
+			token, rest = rest, ""
+		}
+
+		switch {
+		case strings.HasPrefix(token, "#"):
+			if tag := NormalizeTag(token); tag != "" {
+				query.Tags = append(query.Tags, tag)
+			}
+		case strings.HasPrefix(strings.ToLower(token), "type:"):
+			if name := token[len("type:"):]; name != "" {
+				query.Types = append(query.Types, name)
+			}
+		default:
+			query.Terms = append(query.Terms, token)
+		}
+	}
+
+	return query
+}
+
+// NormalizeTag приводит тег к виду, в котором он хранится в БД:
+// без решетки и знаков препинания по краям, в нижнем регистре
+func NormalizeTag(tag string) string {
+	tag = strings.TrimFunc(tag, func(r rune) bool {
+		return r == '#' || unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+	return foldSearchText(strings.ToLower(tag))
+}
+
+// maxTags ограничение числа тегов у одного события
+const maxTags = 10
+
+// SplitTags разбирает строку с тегами, разделенными пробелами или запятыми.
+// Повторы отбрасываются, длина тега ограничена 32 символами.
+func SplitTags(input string) []string {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, field := range fields {
+		tag := NormalizeTag(field)
+		if tag == "" || seen[tag] || len([]rune(tag)) > 32 {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxTags {
+			break
+		}
+	}
+	return tags
+}
+
+// foldSearchText заменяет «ё» на «е», чтобы поиск не зависел от их написания.
+// Та же замена выполняется триггерами при индексации.
+func foldSearchText(text string) string {
+	return strings.NewReplacer("ё", "е", "Ё", "Е").Replace(text)
+}
+
+// ftsMatchExpr строит выражение FTS5 MATCH: каждое слово ищется по префиксу,
+// все слова должны встретиться в событии
+func ftsMatchExpr(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.TrimFunc(foldSearchText(term), unicode.IsPunct)
+		if term == "" {
+			continue
+		}
+		parts = append(parts, "\""+strings.ReplaceAll(term, "\"", "\"\"")+"\"*")
+	}
+	return strings.Join(parts, " ")
+}
+
+// initSearchIndex создает полнотекстовый индекс событий и триггеры, которые
+// поддерживают его в актуальном состоянии
+func (db *DB) initSearchIndex() error {
+	statements := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS events_fts USING fts5(
+			title, description, tags,
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS events_fts_insert AFTER INSERT ON events BEGIN
+			INSERT INTO events_fts (rowid, title, description, tags)
+			VALUES (new.id, replace(replace(new.title, 'ё', 'е'), 'Ё', 'Е'),
+				replace(replace(COALESCE(new.description, ''), 'ё', 'е'), 'Ё', 'Е'), '');
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS events_fts_update AFTER UPDATE OF title, description ON events BEGIN
+			UPDATE events_fts SET
+				title = replace(replace(new.title, 'ё', 'е'), 'Ё', 'Е'),
+				description = replace(replace(COALESCE(new.description, ''), 'ё', 'е'), 'Ё', 'Е')
+			WHERE rowid = new.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS events_fts_delete AFTER DELETE ON events BEGIN
+			DELETE FROM events_fts WHERE rowid = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS event_tags_fts_insert AFTER INSERT ON event_tags BEGIN
+			UPDATE events_fts SET tags = (SELECT group_concat(tag, ' ') FROM event_tags WHERE event_id = new.event_id)
+			WHERE rowid = new.event_id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS event_tags_fts_delete AFTER DELETE ON event_tags BEGIN
+			UPDATE events_fts SET tags = COALESCE((SELECT group_concat(tag, ' ') FROM event_tags WHERE event_id = old.event_id), '')
+			WHERE rowid = old.event_id;
+		END`,
+		// Индексируем события, созданные до появления поиска
+		`INSERT INTO events_fts (rowid, title, description, tags)
+		SELECT id, replace(replace(title, 'ё', 'е'), 'Ё', 'Е'),
+			replace(replace(COALESCE(description, ''), 'ё', 'е'), 'Ё', 'Е'),
+			COALESCE((SELECT group_concat(tag, ' ') FROM event_tags WHERE event_id = events.id), '')
+		FROM events WHERE id NOT IN (SELECT rowid FROM events_fts)`,
+	}
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("не удалось создать поисковый индекс: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetEventTags получает теги события
+func (db *DB) GetEventTags(eventID int64) ([]string, error) {
+	rows, err := db.Query("SELECT tag FROM event_tags WHERE event_id = ? ORDER BY tag", eventID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении тегов: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании тега: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по тегам: %w", err)
+	}
+
+	return tags, nil
+}
+
+// SetEventTags заменяет теги события
+func (db *DB) SetEventTags(eventID int64, tags []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении тегов: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM event_tags WHERE event_id = ?", eventID); err != nil {
+		return fmt.Errorf("ошибка при сохранении тегов: %w", err)
+	}
+
+	for _, tag := range tags {
+		if tag = NormalizeTag(tag); tag == "" {
+			continue
+		}
+		_, err := tx.Exec("INSERT OR IGNORE INTO event_tags (event_id, tag) VALUES (?, ?)", eventID, tag)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении тегов: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при сохранении тегов: %w", err)
+	}
+	return nil
+}
+
+// attachTags загружает теги для списка событий
+func (db *DB) attachTags(events []*models.Event) error {
+	for _, event := range events {
+		tags, err := db.GetEventTags(event.ID)
+		if err != nil {
+			return err
+		}
+		event.Tags = tags
+	}
+	return nil
+}
+
+// SearchEvents ищет события пользователя и его общих календарей и возвращает
+// страницу результатов вместе с общим числом найденных событий
+func (db *DB) SearchEvents(userID int64, query SearchQuery, limit, offset int) ([]*models.Event, int, error) {
+	where := visibleEvents + " AND deleted_at IS NULL"
+	args := []interface{}{userID, userID, userID}
+
+	if match := ftsMatchExpr(query.Terms); match != "" {
+		where += " AND id IN (SELECT rowid FROM events_fts WHERE events_fts MATCH ?)"
+		args = append(args, match)
+	}
+
+	for _, tag := range query.Tags {
+		where += " AND id IN (SELECT event_id FROM event_tags WHERE tag = ?)"
+		args = append(args, tag)
+	}
+
+	if len(query.Types) > 0 {
+		if len(query.TypeIDs) == 0 {
+			// Ни один тип не подошел под фильтр
+			return []*models.Event{}, 0, nil
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(query.TypeIDs)), ", ")
+		where += " AND type_id IN (" + placeholders + ")"
+		for _, typeID := range query.TypeIDs {
+			args = append(args, typeID)
+		}
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM events WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("ошибка при поиске событий: %w", err)
+	}
+
+	events, err := db.queryEvents(
+		"SELECT "+eventColumns+" FROM events WHERE "+where+" ORDER BY event_date, id LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
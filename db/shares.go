@@ -0,0 +1,240 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// ShareInvitePrefix начало кода приглашения подписаться на событие в ссылке
+// /start, по которому оно отличается от приглашения в календарь
+const ShareInvitePrefix = "evt_"
+
+// IsEventSubscriber проверяет, подписан ли пользователь на событие
+func (db *DB) IsEventSubscriber(eventID, userID int64) (bool, error) {
+	var exists bool
+	err := db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM event_subscriptions WHERE event_id = ? AND user_id = ?)",
+		eventID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке подписки на событие: %w", err)
+	}
+	return exists, nil
+}
+
+// GetSubscriptionNotifyDays возвращает, за сколько дней напоминать подписчику
+// о событии. Второе значение равно false, если подписчик не задал своего
+// значения и напоминание приходит за столько дней, сколько указал автор.
+func (db *DB) GetSubscriptionNotifyDays(eventID, userID int64) (int, bool, error) {
+	var days sql.NullInt64
+	err := db.QueryRow(
+		"SELECT notify_days FROM event_subscriptions WHERE event_id = ? AND user_id = ?",
+		eventID, userID,
+	).Scan(&days)
+	if err == sql.ErrNoRows {
+		return 0, false, fmt.Errorf("подписка пользователя %d: %w", userID, ErrEventNotFound)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("ошибка при получении настроек подписки: %w", err)
+	}
+	return int(days.Int64), days.Valid, nil
+}
+
+// GetSubscriptionsNotifyDays возвращает собственные сроки напоминаний
+// подписчика по ID событий. События, для которых срок не задан, в результат
+// не попадают.
+func (db *DB) GetSubscriptionsNotifyDays(userID int64) (map[int64]int, error) {
+	rows, err := db.Query(
+		"SELECT event_id, notify_days FROM event_subscriptions WHERE user_id = ? AND notify_days IS NOT NULL",
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении настроек подписок: %w", err)
+	}
+	defer rows.Close()
+
+	notifyDays := make(map[int64]int)
+	for rows.Next() {
+		var eventID int64
+		var days int
+		if err := rows.Scan(&eventID, &days); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании настроек подписки: %w", err)
+		}
+		notifyDays[eventID] = days
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по подпискам: %w", err)
+	}
+
+	return notifyDays, nil
+}
+
+// SetSubscriptionNotifyDays задает, за сколько дней напоминать подписчику
+// о событии. 0 возвращает срок, указанный автором.
+func (db *DB) SetSubscriptionNotifyDays(eventID, userID int64, days int) error {
+	var value interface{}
+	if days > 0 {
+		value = days
+	}
+
+	result, err := db.Exec(
+		"UPDATE event_subscriptions SET notify_days = ? WHERE event_id = ? AND user_id = ?",
+		value, eventID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении настроек подписки: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении настроек подписки: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("подписка пользователя %d: %w", userID, ErrEventNotFound)
+	}
+	return nil
+}
+
+// CreateEventShareInvite создает код приглашения подписаться на событие.
+// Делиться событием может тот, кто может его изменять.
+func (db *DB) CreateEventShareInvite(eventID, userID int64) (string, error) {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+		return "", err
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("ошибка при создании кода приглашения: %w", err)
+	}
+	code := ShareInvitePrefix + hex.EncodeToString(random)
+
+	_, err := db.Exec("INSERT INTO event_share_invites (code, event_id) VALUES (?, ?)", code, eventID)
+	if err != nil {
+		return "", fmt.Errorf("ошибка при сохранении приглашения: %w", err)
+	}
+	return code, nil
+}
+
+// SubscribeToEvent подписывает пользователя на событие по коду приглашения.
+// Второе значение равно false, если событие уже доступно пользователю: это
+// его собственное событие, событие его календаря или он уже подписан.
+func (db *DB) SubscribeToEvent(code string, userID int64) (*models.Event, bool, error) {
+	var eventID int64
+	err := db.QueryRow("SELECT event_id FROM event_share_invites WHERE code = ?", code).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		return nil, false, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при получении приглашения: %w", err)
+	}
+
+	event, err := db.GetEventByID(eventID)
+	if err != nil {
+		return nil, false, err
+	}
+	if event == nil {
+		// Событие удалено в корзину
+		return nil, false, ErrInviteNotFound
+	}
+
+	var visible bool
+	err = db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM events WHERE id = ? AND "+visibleEvents+")",
+		eventID, userID, userID, userID,
+	).Scan(&visible)
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при подписке на событие: %w", err)
+	}
+	if visible {
+		return event, false, nil
+	}
+
+	_, err = db.Exec("INSERT OR IGNORE INTO event_subscriptions (event_id, user_id) VALUES (?, ?)", eventID, userID)
+	if err != nil {
+		return nil, false, fmt.Errorf("ошибка при подписке на событие: %w", err)
+	}
+	return event, true, nil
+}
+
+// GetEventSubscribers получает подписчиков события. Список видит только тот,
+// кто может изменять событие.
+func (db *DB) GetEventSubscribers(eventID, userID int64) ([]*models.EventSubscriber, error) {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT s.event_id, s.user_id, COALESCE(u.first_name, ''), COALESCE(u.username, ''), s.subscribed_at
+		FROM event_subscriptions s JOIN users u ON u.id = s.user_id
+		WHERE s.event_id = ?
+		ORDER BY s.subscribed_at, s.user_id`,
+		eventID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении подписчиков события: %w", err)
+	}
+	defer rows.Close()
+
+	subscribers := []*models.EventSubscriber{}
+	for rows.Next() {
+		subscriber := &models.EventSubscriber{}
+		err := rows.Scan(&subscriber.EventID, &subscriber.UserID,
+			&subscriber.FirstName, &subscriber.Username, &subscriber.SubscribedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании подписчика события: %w", err)
+		}
+		subscribers = append(subscribers, subscriber)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по подписчикам события: %w", err)
+	}
+
+	return subscribers, nil
+}
+
+// RemoveEventSubscriber отменяет подписку на событие. Подписчик может
+// отписаться сам, а тот, кто может изменять событие, — отключить любого
+// подписчика. Вместе с подпиской удаляются его отложенные и повторные
+// напоминания о событии.
+func (db *DB) RemoveEventSubscriber(eventID, userID, subscriberID int64) error {
+	if userID != subscriberID {
+		if _, err := db.GetEventForUser(eventID, userID, PermissionEdit); err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при отмене подписки на событие: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM event_subscriptions WHERE event_id = ? AND user_id = ?", eventID, subscriberID)
+	if err != nil {
+		return fmt.Errorf("ошибка при отмене подписки на событие: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при отмене подписки на событие: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("подписка пользователя %d: %w", subscriberID, ErrEventNotFound)
+	}
+
+	for _, table := range []string{"snoozes", "nags", "task_snoozes"} {
+		_, err = tx.Exec("DELETE FROM "+table+" WHERE user_id = ? AND event_id = ?", subscriberID, eventID)
+		if err != nil {
+			return fmt.Errorf("ошибка при отмене подписки на событие: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при отмене подписки на событие: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package db
+
+import "testing"
+
+func TestSubscriptionNotifyDays(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	subscriber := newTestUser(t, db, 2)
+	eventID := newTestEvent(t, db, owner, 0, "Мама")
+
+	code, err := db.CreateEventShareInvite(eventID, owner)
+	if err != nil {
+		t.Fatalf("CreateEventShareInvite: %v", err)
+	}
+	if _, _, err := db.SubscribeToEvent(code, subscriber); err != nil {
+		t.Fatalf("SubscribeToEvent: %v", err)
+	}
+
+	// notifyDays возвращает срок напоминания каждого получателя события
+	notifyDays := func() map[int64]int {
+		t.Helper()
+		recipients, err := db.GetEventsForNotification("09:00")
+		if err != nil {
+			t.Fatalf("GetEventsForNotification: %v", err)
+		}
+		days := make(map[int64]int)
+		for _, recipient := range recipients {
+			days[recipient.UserID] = recipient.NotifyDays
+		}
+		return days
+	}
+
+	if days := notifyDays(); days[owner] != 3 || days[subscriber] != 3 {
+		t.Errorf("без своего срока получено %v, ожидалось 3 у обоих", days)
+	}
+
+	if err := db.SetSubscriptionNotifyDays(eventID, subscriber, 7); err != nil {
+		t.Fatalf("SetSubscriptionNotifyDays: %v", err)
+	}
+	if days := notifyDays(); days[owner] != 3 || days[subscriber] != 7 {
+		t.Errorf("со своим сроком получено %v, ожидалось 3 у автора и 7 у подписчика", days)
+	}
+
+	// Автор события не подписчик, свой срок ему не задается
+	if err := db.SetSubscriptionNotifyDays(eventID, owner, 5); !isDenied(err) {
+		t.Errorf("SetSubscriptionNotifyDays автором: ожидался отказ, получено: %v", err)
+	}
+
+	if err := db.SetSubscriptionNotifyDays(eventID, subscriber, 0); err != nil {
+		t.Fatalf("SetSubscriptionNotifyDays: %v", err)
+	}
+	if days, own, err := db.GetSubscriptionNotifyDays(eventID, subscriber); err != nil || own || days != 0 {
+		t.Errorf("после сброса получено %d, %v, %v", days, own, err)
+	}
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// MaxTasks наибольшее число задач у события
+const MaxTasks = 20
+
+// MaxTaskDueDays наибольший срок напоминания о задаче в днях до события
+const MaxTaskDueDays = 365
+
+// ErrTaskLimit у события уже максимальное число задач
+var ErrTaskLimit = errors.New("достигнуто максимальное число задач")
+
+// taskColumns список полей задачи для выборок
+const taskColumns = "id, event_id, title, due_days, done_for, created_at"
+
+// queryTasks выполняет выборку задач. Задачи с напоминанием идут первыми,
+// от самых ранних к самым поздним.
+func (db *DB) queryTasks(where string, args ...interface{}) ([]*models.Task, error) {
+	rows, err := db.Query(
+		"SELECT "+taskColumns+" FROM event_tasks WHERE "+where+" ORDER BY event_id, due_days < 0, due_days DESC, id",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении задач: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task := &models.Task{}
+		if err := rows.Scan(&task.ID, &task.EventID, &task.Title, &task.DueDays, &task.DoneFor, &task.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании задачи: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по задачам: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetEventTasks получает задачи подготовки к событию
+func (db *DB) GetEventTasks(eventID int64) ([]*models.Task, error) {
+	return db.queryTasks("event_id = ?", eventID)
+}
+
+// GetTasksForUser получает задачи события, доступного пользователю
+func (db *DB) GetTasksForUser(eventID, userID int64) ([]*models.Task, error) {
+	if _, err := db.GetEventForUser(eventID, userID, PermissionView); err != nil {
+		return nil, err
+	}
+	return db.GetEventTasks(eventID)
+}
+
+// GetTasksByUserID получает задачи всех событий, автором которых является пользователь
+func (db *DB) GetTasksByUserID(userID int64) ([]*models.Task, error) {
+	return db.queryTasks("event_id IN (SELECT id FROM events WHERE user_id = ?)", userID)
+}
+
+// GetTaskForUser получает задачу, если у пользователя есть нужные права на ее событие
+func (db *DB) GetTaskForUser(taskID, userID int64, perm Permission) (*models.Task, error) {
+	tasks, err := db.queryTasks("id = ?", taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("задача %d: %w", taskID, ErrEventNotFound)
+	}
+
+	if _, err := db.GetEventForUser(tasks[0].EventID, userID, perm); err != nil {
+		return nil, err
+	}
+	return tasks[0], nil
+}
+
+// CreateTaskForUser добавляет задачу к событию, которое пользователь может изменять
+func (db *DB) CreateTaskForUser(task *models.Task, userID int64) (int64, error) {
+	if task.DueDays < models.TaskNoDue || task.DueDays > MaxTaskDueDays {
+		return 0, fmt.Errorf("недопустимый срок задачи: %d", task.DueDays)
+	}
+	if _, err := db.GetEventForUser(task.EventID, userID, PermissionEdit); err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM event_tasks WHERE event_id = ?", task.EventID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете задач: %w", err)
+	}
+	if count >= MaxTasks {
+		return 0, ErrTaskLimit
+	}
+
+	result, err := db.Exec(
+		"INSERT INTO event_tasks (event_id, title, due_days) VALUES (?, ?, ?)",
+		task.EventID, task.Title, task.DueDays,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при добавлении задачи: %w", err)
+	}
+
+	taskID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID новой задачи: %w", err)
+	}
+	return taskID, nil
+}
+
+// SetTaskDoneForUser отмечает задачу выполненной к наступлению события
+// occurrence или снимает отметку при пустом occurrence. Отмечать задачи
+// могут все, кто видит событие, например подписчики.
+func (db *DB) SetTaskDoneForUser(taskID, userID int64, occurrence string) error {
+	if _, err := db.GetTaskForUser(taskID, userID, PermissionView); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec("UPDATE event_tasks SET done_for = ? WHERE id = ?", occurrence, taskID); err != nil {
+		return fmt.Errorf("ошибка при изменении задачи: %w", err)
+	}
+	return nil
+}
+
+// DeleteTaskForUser удаляет задачу события, которое пользователь может изменять
+func (db *DB) DeleteTaskForUser(taskID, userID int64) (*models.Task, error) {
+	task, err := db.GetTaskForUser(taskID, userID, PermissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec("DELETE FROM event_tasks WHERE id = ?", taskID); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении задачи: %w", err)
+	}
+	return task, nil
+}
+
+// SnoozeTaskReminder откладывает напоминание о задачах со сроком dueDays
+// к наступлению события occurrence до времени remindAt
+func (db *DB) SnoozeTaskReminder(userID, eventID int64, occurrence string, dueDays int, remindAt time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO task_snoozes (user_id, event_id, occurrence, due_days, remind_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, event_id, occurrence, due_days) DO UPDATE SET remind_at = excluded.remind_at`,
+		userID, eventID, occurrence, dueDays, remindAt.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при откладывании напоминания о задачах: %w", err)
+	}
+	return nil
+}
+
+// GetDueTaskSnoozes получает отложенные напоминания о задачах, время которых наступило к now
+func (db *DB) GetDueTaskSnoozes(now time.Time) ([]*models.TaskSnooze, error) {
+	rows, err := db.Query(
+		"SELECT id, user_id, event_id, occurrence, due_days, remind_at FROM task_snoozes WHERE remind_at <= ? ORDER BY remind_at",
+		now.UTC().Format(sqliteTimeLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении отложенных напоминаний о задачах: %w", err)
+	}
+	defer rows.Close()
+
+	snoozes := []*models.TaskSnooze{}
+	for rows.Next() {
+		snooze := &models.TaskSnooze{}
+		err := rows.Scan(&snooze.ID, &snooze.UserID, &snooze.EventID, &snooze.Occurrence, &snooze.DueDays, &snooze.RemindAt)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании отложенного напоминания о задачах: %w", err)
+		}
+		snoozes = append(snoozes, snooze)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по отложенным напоминаниям о задачах: %w", err)
+	}
+
+	return snoozes, nil
+}
+
+// DeleteTaskSnooze удаляет отложенное напоминание о задачах после отправки
+func (db *DB) DeleteTaskSnooze(snoozeID int64) error {
+	if _, err := db.Exec("DELETE FROM task_snoozes WHERE id = ?", snoozeID); err != nil {
+		return fmt.Errorf("ошибка при удалении отложенного напоминания о задачах: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskSnoozes(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	eventID := newTestEvent(t, db, owner, 0, "Мама")
+
+	now := time.Date(2026, time.May, 12, 3, 0, 0, 0, time.UTC)
+	morning := now.Add(5 * time.Hour)
+
+	// Повторный перенос того же напоминания сдвигает его, а не дублирует
+	if err := db.SnoozeTaskReminder(owner, eventID, "2026-05-15", 3, now.Add(time.Hour)); err != nil {
+		t.Fatalf("SnoozeTaskReminder: %v", err)
+	}
+	if err := db.SnoozeTaskReminder(owner, eventID, "2026-05-15", 3, morning); err != nil {
+		t.Fatalf("SnoozeTaskReminder: %v", err)
+	}
+
+	snoozes, err := db.GetDueTaskSnoozes(now.Add(2 * time.Hour))
+	if err != nil {
+		t.Fatalf("GetDueTaskSnoozes: %v", err)
+	}
+	if len(snoozes) != 0 {
+		t.Fatalf("до окончания тихих часов получено %d напоминаний", len(snoozes))
+	}
+
+	snoozes, err = db.GetDueTaskSnoozes(morning)
+	if err != nil {
+		t.Fatalf("GetDueTaskSnoozes: %v", err)
+	}
+	if len(snoozes) != 1 {
+		t.Fatalf("получено %d напоминаний, ожидалось одно", len(snoozes))
+	}
+	snooze := snoozes[0]
+	if snooze.EventID != eventID || snooze.Occurrence != "2026-05-15" || snooze.DueDays != 3 {
+		t.Errorf("получено напоминание %+v", snooze)
+	}
+
+	if err := db.DeleteTaskSnooze(snooze.ID); err != nil {
+		t.Fatalf("DeleteTaskSnooze: %v", err)
+	}
+	if snoozes, _ := db.GetDueTaskSnoozes(morning); len(snoozes) != 0 {
+		t.Errorf("после удаления осталось %d напоминаний", len(snoozes))
+	}
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// saveRevision сохраняет текущие значения события в истории изменений
+func saveRevision(tx *sql.Tx, eventID int64) (int64, error) {
+	result, err := tx.Exec(`
+		INSERT INTO event_revisions (event_id, title, type, type_id, event_date, notify_days, description,
+			recurrence, leap_policy, priority, nag_hours)
+		SELECT id, title, type, type_id, event_date, notify_days, description,
+			recurrence, leap_policy, priority, nag_hours
+		FROM events WHERE id = ?`,
+		eventID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении истории изменений: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении истории изменений: %w", err)
+	}
+	if affected == 0 {
+		return 0, fmt.Errorf("событие %d не найдено", eventID)
+	}
+
+	revisionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при получении ID ревизии: %w", err)
+	}
+	return revisionID, nil
+}
+
+// revisionColumns список полей ревизии для выборок
+const revisionColumns = "id, event_id, title, type, type_id, event_date, notify_days, description, created_at"
+
+// scanRevision считывает ревизию из строки выборки по revisionColumns
+func scanRevision(row rowScanner) (*models.EventRevision, error) {
+	revision := &models.EventRevision{}
+	var typeID sql.NullInt64
+
+	err := row.Scan(
+		&revision.ID, &revision.EventID, &revision.Title, &revision.Type, &typeID,
+		&revision.EventDate, &revision.NotifyDays, &revision.Description, &revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revision.TypeID = typeID.Int64
+	return revision, nil
+}
+
+// GetEventRevisions получает историю изменений события, начиная с последнего
+func (db *DB) GetEventRevisions(eventID int64) ([]*models.EventRevision, error) {
+	rows, err := db.Query(
+		"SELECT "+revisionColumns+" FROM event_revisions WHERE event_id = ? ORDER BY id DESC",
+		eventID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории изменений: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*models.EventRevision{}
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при сканировании истории изменений: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при итерации по истории изменений: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetEventRevision получает ревизию события по ее ID
+func (db *DB) GetEventRevision(revisionID int64) (*models.EventRevision, error) {
+	revision, err := scanRevision(db.QueryRow(
+		"SELECT "+revisionColumns+" FROM event_revisions WHERE id = ?",
+		revisionID,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении ревизии: %w", err)
+	}
+
+	return revision, nil
+}
+
+// RevertEvent возвращает событию значения из ревизии.
+// Текущие значения тоже попадают в историю, поэтому откат можно отменить.
+func (db *DB) RevertEvent(revisionID int64) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при откате изменений: %w", err)
+	}
+	defer tx.Rollback()
+
+	var eventID int64
+	err = tx.QueryRow("SELECT event_id FROM event_revisions WHERE id = ?", revisionID).Scan(&eventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("ревизия %d не найдена", revisionID)
+		}
+		return 0, fmt.Errorf("ошибка при получении ревизии: %w", err)
+	}
+
+	newRevisionID, err := saveRevision(tx, eventID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE events SET
+			title = r.title, type = r.type, event_date = r.event_date,
+			notify_days = r.notify_days, description = r.description,
+			recurrence = COALESCE(r.recurrence, events.recurrence),
+			leap_policy = COALESCE(r.leap_policy, events.leap_policy),
+			priority = COALESCE(r.priority, events.priority),
+			nag_hours = COALESCE(r.nag_hours, events.nag_hours),
+			type_id = (SELECT id FROM event_types WHERE id = r.type_id)
+		FROM (SELECT * FROM event_revisions WHERE id = ?) AS r
+		WHERE events.id = r.event_id`,
+		revisionID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при откате изменений: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при откате изменений: %w", err)
+	}
+	return newRevisionID, nil
+}
+
+// GetDeletedEventsByUserID получает события пользователя и его общих календарей
+// из корзины, удаленные не раньше retentionDays дней назад
+func (db *DB) GetDeletedEventsByUserID(userID int64, retentionDays int) ([]*models.Event, error) {
+	return db.queryEvents(
+		"SELECT "+eventColumns+" FROM events WHERE "+visibleEvents+" AND deleted_at IS NOT NULL AND deleted_at >= datetime('now', ?) ORDER BY deleted_at DESC",
+		userID, userID, userID, fmt.Sprintf("-%d days", retentionDays),
+	)
+}
+
+// GetDeletedEventByID получает событие из корзины по его ID
+func (db *DB) GetDeletedEventByID(eventID int64) (*models.Event, error) {
+	event, err := scanEvent(db.QueryRow(
+		"SELECT "+eventColumns+" FROM events WHERE id = ? AND deleted_at IS NOT NULL",
+		eventID,
+	))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении события: %w", err)
+	}
+
+	return event, nil
+}
+
+// RestoreEvent возвращает событие из корзины, если срок хранения не истек
+func (db *DB) RestoreEvent(eventID int64, retentionDays int) error {
+	result, err := db.Exec(
+		"UPDATE events SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL AND deleted_at >= datetime('now', ?)",
+		eventID, fmt.Sprintf("-%d days", retentionDays),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка при восстановлении события: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при восстановлении события: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("событие %d отсутствует в корзине", eventID)
+	}
+	return nil
+}
+
+// PurgeDeletedEvents окончательно удаляет события, пролежавшие в корзине дольше retentionDays дней
+func (db *DB) PurgeDeletedEvents(retentionDays int) (int64, error) {
+	result, err := db.Exec(
+		"DELETE FROM events WHERE deleted_at IS NOT NULL AND deleted_at < datetime('now', ?)",
+		fmt.Sprintf("-%d days", retentionDays),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке корзины: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке корзины: %w", err)
+	}
+	return purged, nil
+}
+
+// TrashPastOneOffEvents убирает в корзину разовые события, дата которых
+// (YYYY-MM-DD) раньше today: напоминать о них больше не о чем
+func (db *DB) TrashPastOneOffEvents(today string) (int64, error) {
+	result, err := db.Exec(
+		"UPDATE events SET deleted_at = CURRENT_TIMESTAMP WHERE recurrence = ? AND event_date < ? AND deleted_at IS NULL",
+		models.RecurrenceOnce, today,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при удалении прошедших разовых событий: %w", err)
+	}
+
+	trashed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при удалении прошедших разовых событий: %w", err)
+	}
+	return trashed, nil
+}
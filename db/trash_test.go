@@ -0,0 +1,109 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// updateTestEvent изменяет событие владельцем и возвращает ID ревизии с прежними значениями
+func updateTestEvent(t *testing.T, db *DB, eventID, userID int64, change func(*models.Event)) int64 {
+	t.Helper()
+
+	event, err := db.GetEventForUser(eventID, userID, PermissionEdit)
+	if err != nil {
+		t.Fatalf("GetEventForUser: %v", err)
+	}
+	change(event)
+
+	revisionID, err := db.UpdateEventForUser(event, userID)
+	if err != nil {
+		t.Fatalf("UpdateEventForUser: %v", err)
+	}
+	return revisionID
+}
+
+func TestRevertRestoresPriority(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	eventID := newTestEvent(t, db, owner, 0, "Мама")
+
+	revisionID := updateTestEvent(t, db, eventID, owner, func(event *models.Event) {
+		event.Priority = models.PriorityHigh
+		event.NagHours = 4
+	})
+
+	event, undoID, err := db.RevertEventForUser(revisionID, owner)
+	if err != nil {
+		t.Fatalf("RevertEventForUser: %v", err)
+	}
+	if event.Priority != models.PriorityNormal || event.NagHours != 0 {
+		t.Errorf("после отката priority=%d nag_hours=%d, ожидалось 0 и 0", event.Priority, event.NagHours)
+	}
+
+	// Отмена отката возвращает важность
+	event, _, err = db.RevertEventForUser(undoID, owner)
+	if err != nil {
+		t.Fatalf("RevertEventForUser: %v", err)
+	}
+	if event.Priority != models.PriorityHigh || event.NagHours != 4 {
+		t.Errorf("после отмены отката priority=%d nag_hours=%d, ожидалось 1 и 4", event.Priority, event.NagHours)
+	}
+}
+
+func TestRevertRestoresRule(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+	eventID := newTestEvent(t, db, owner, 0, "Мама")
+
+	revisionID := updateTestEvent(t, db, eventID, owner, func(event *models.Event) {
+		event.Recurrence = models.RecurrenceMonthly
+		event.LeapPolicy = models.LeapPolicyMar1
+	})
+
+	event, _, err := db.RevertEventForUser(revisionID, owner)
+	if err != nil {
+		t.Fatalf("RevertEventForUser: %v", err)
+	}
+	if event.Recurrence != models.RecurrenceYearly || event.LeapPolicy != models.LeapPolicyFeb28 {
+		t.Errorf("после отката recurrence=%q leap_policy=%q, ожидалось %q и %q",
+			event.Recurrence, event.LeapPolicy, models.RecurrenceYearly, models.LeapPolicyFeb28)
+	}
+}
+
+func TestTrashPastOneOffEvents(t *testing.T) {
+	db := newTestDB(t)
+	owner := newTestUser(t, db, 1)
+
+	create := func(date, recurrence string) int64 {
+		t.Helper()
+		eventID, err := db.CreateEventForUser(&models.Event{
+			UserID: owner, Title: "Позвонить маме", Type: models.EventTypeOther,
+			EventDate: date, NotifyDays: 1, Recurrence: recurrence,
+		})
+		if err != nil {
+			t.Fatalf("CreateEventForUser: %v", err)
+		}
+		return eventID
+	}
+	past := create("2026-05-11", models.RecurrenceOnce)
+	today := create("2026-05-12", models.RecurrenceOnce)
+	yearly := create("2000-05-11", models.RecurrenceYearly)
+
+	trashed, err := db.TrashPastOneOffEvents("2026-05-12")
+	if err != nil {
+		t.Fatalf("TrashPastOneOffEvents: %v", err)
+	}
+	if trashed != 1 {
+		t.Errorf("в корзину убрано %d событий, ожидалось одно", trashed)
+	}
+
+	if event, _ := db.GetEventByID(past); event != nil {
+		t.Error("прошедшее разовое событие не убрано в корзину")
+	}
+	for _, eventID := range []int64{today, yearly} {
+		if event, err := db.GetEventByID(eventID); err != nil || event == nil {
+			t.Errorf("событие %d убрано в корзину: %v", eventID, err)
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package i18n
+
+// en каталог сообщений на английском языке
+var en = map[string]string{
+	"language.name": "🇬🇧 English",
+
+	"plural.days":    "day|days",
+	"plural.years":   "year|years",
+	"plural.hours":   "hour|hours",
+	"plural.minutes": "minute|minutes",
+
+	"type.birthday":    "Birthday",
+	"type.meeting":     "Meeting",
+	"type.holiday":     "Holiday",
+	"type.anniversary": "Anniversary",
+	"type.other":       "Other",
+
+	"start.welcome": "👋 Hi, %s!\n\n" +
+		"I'm a bot that reminds you about birthdays and important events. " +
+		"With my help you won't forget to congratulate your friends and family.\n\n" +
+		"Use the menu to manage your events and reminders:",
+	"help.text": "🤖 *Commands:*\n\n" +
+		"/start - start the bot and show the main menu\n" +
+		"/help - show this help\n" +
+		"/add - add a new event\n" +
+		"/list - show your events\n" +
+		"/today - events happening today\n" +
+		"/week - events in the next 7 days\n" +
+		"/month - events in the next 30 days\n" +
+		"/next - the next upcoming event\n" +
+		"/search - find events by words, #tags and type:type\n" +
+		"/settings - notification and language settings\n" +
+		"/calendars - shared calendars for family and friends\n" +
+		"/trash - deleted events\n" +
+		"/mydata - export all your data\n" +
+		"/deleteme - delete your account and all events\n\n" +
+		"You can also use the menu buttons to navigate.",
+
+	"group.welcome": "👥 In a group, events and settings are shared by all members and reminders are posted here. " +
+		"Only group admins can add, edit and delete events; " +
+		"everyone can view the list and the agenda.",
+	"group.admin_only": "⛔ Only group admins can do this in a group.",
+
+	"menu.prompt":   "What would you like to do?",
+	"menu.add":      "Add event",
+	"menu.list":     "My events",
+	"menu.today":    "📅 Today",
+	"menu.agenda":   "🗓 Upcoming",
+	"menu.settings": "Settings",
+	"menu.help":     "Help",
+
+	"button.add_event":    "➕ Add event",
+	"button.trash":        "🗑 Trash",
+	"button.main_menu":    "🏠 Main menu",
+	"button.back":         "⬅️ Back",
+	"button.back_to_list": "⬅️ Back to list",
+	"button.undo":         "↩️ Undo",
+
+	"notify.today":           "🎉 Today: %s (%s)\n%s",
+	"notify.greeting_hint":   "\n\n💌 A ready-made greeting is in the next message, you can forward it.",
+	"notify.gift_ideas":      "\n\n🎁 Gift ideas not bought yet:\n%s",
+	"notify.leave_by":        "🚗 Time to leave for «%s»: the meeting is at %s, the trip takes %s.",
+	"notify.tasks":           "📋 Preparing for «%s» (%s):\n%s\n\nTick off what is done with the buttons below.",
+	"notify.in_days":         "🔔 In %s: %s (%s)\n%s",
+	"notify.nag":             "⚡ Reminding you again: %s (%s) — %s\n%s",
+	"notify.snoozed":         "⏰ Reminder: %s (%s) — %s\n%s",
+	"notify.snooze_1h":       "⏰ 1 h",
+	"notify.snooze_3h":       "⏰ 3 h",
+	"notify.snooze_tomorrow": "🌙 Tomorrow",
+	"notify.done":            "✅ Done",
+	"notify.snoozed_until":   "⏰ I'll remind you about \"%s\" on %s.",
+	"notify.acknowledged":    "✅ \"%s\" marked as done. No more reminders for this occurrence.",
+
+	"add.title_prompt":       "Enter the event title:",
+	"add.type_prompt":        "Choose the event type:",
+	"add.date_prompt":        "Enter the event date as DD.MM.YYYY (or DD.MM if the year is unknown):",
+	"add.notify_prompt":      "How many days before the event should I remind you? (enter a number from 1 to 30):",
+	"add.desc_prompt":        "Enter a description (or send /skip to leave it empty). You can also send a photo or file with a caption — the caption becomes the description:",
+	"add.desc_location_hint": "\n\n📍 Instead of the meeting address you can send a location or a venue via 📎 → «Location».",
+	"add.leave_by_hint":      "\n🚗 To get a reminder when to leave, set the meeting time and travel time when editing the event.",
+	"add.notify_default":     "🔔 Type default: %s before",
+	"add.success":            "✅ Event added!\n\n🔤 Title: %s\n",
+
+	"event.details":                    "🏷 Type: %s\n📅 Date: %s\n🔔 Reminder: %s before\n",
+	"event.description":                "📝 Description: %s\n",
+	"event.location":                   "📍 Place: %s\n",
+	"event.leave_by":                   "🚗 Leave at %s (meeting at %s, %s on the way)\n",
+	"event.calendar":                   "📒 Calendar: %s\n",
+	"event.greeting":                   "💌 Greeting from a template on the day\n",
+	"event.tags":                       "🔖 Tags: %s\n",
+	"event.milestone":                  "🎈 %s: %s\n",
+	"event.priority_high":              "⚡ High priority\n",
+	"event.nag":                        "🔁 Repeat every %s until marked done\n",
+	"event.monthly":                    "🔄 Repeats every month\n",
+	"event.once":                       "📌 One-time: the event moves to the trash after its date\n",
+	"event.leap_policy":                "📅 In non-leap years: %s\n",
+	"event.edit":                       "✏️ Edit",
+	"event.delete":                     "❌ Delete",
+	"event.share":                      "🔗 Share",
+	"event.unsubscribe":                "🔕 Unsubscribe",
+	"event.subscribed":                 "🔗 You are subscribed to this event: the author's changes appear here, and reminders follow your own settings\n",
+	"event.subscription_notify":        "🔔 When to remind",
+	"event.subscription_notify_author": "🔔 You will be reminded %s before, as set by the author\n",
+	"event.subscription_notify_own":    "🔔 You will be reminded %s before\n",
+	"event.history":                    "🕓 Change history",
+	"event.gifts":                      "🎁 Gifts",
+	"event.tasks":                      "📋 Tasks",
+	"event.attachments":                "📎 Attachments",
+	"event.attachments_count":          "\n📎 Attachments: %d",
+
+	"list.title":        "🗓 Your events:",
+	"list.empty":        "You don't have any events yet. Add your first one!",
+	"list.today":        " (today!)",
+	"list.in_days":      " (in %s)",
+	"list.nothing":      "🗓 No events match the filters.",
+	"list.filtered":     "🗓 Your events: %d of %d",
+	"list.sort":         "↕️ Sort: %s",
+	"list.sort_next":    "upcoming",
+	"list.sort_name":    "by name",
+	"list.sort_type":    "by type",
+	"list.sort_created": "newest",
+	"list.type":         "🏷 %s",
+	"list.window":       "📅 %s",
+	"list.filter_all":   "all",
+
+	"agenda.today_title":  "📅 Today's events:",
+	"agenda.week_title":   "🗓 Events in the next 7 days:",
+	"agenda.month_title":  "🗓 Events in the next 30 days:",
+	"agenda.next_title":   "⏭ Next event:",
+	"agenda.empty":        "No events.",
+	"agenda.line":         "📅 %s — %s (%s), %s",
+	"agenda.when_today":   "today!",
+	"agenda.when_in_days": "in %s",
+	"agenda.today":        "Today",
+	"agenda.week":         "Week",
+	"agenda.month":        "Month",
+	"agenda.next":         "Next",
+
+	"edit.prompt":             "What would you like to change?",
+	"edit.title":              "🔤 Title",
+	"edit.type":               "🏷 Type",
+	"edit.date":               "📅 Date",
+	"edit.notify_days":        "🔔 Reminder days",
+	"edit.description":        "📝 Description",
+	"edit.tags":               "🔖 Tags",
+	"edit.tags_prompt":        "Enter tags separated by spaces or commas, for example: family work (or \"-\" to remove all tags):",
+	"edit.tags_saved":         "✅ Tags saved.",
+	"edit.priority":           "⚡ Priority",
+	"edit.nag_hours":          "🔁 Repeat",
+	"edit.priority_prompt":    "Choose the event priority:",
+	"edit.nag_prompt":         "How many hours later should I repeat the reminder for an important event until you tap \"Done\"? The interval doubles with each repeat and there are no repeats at night. Enter a number from 1 to 24 (or 0 to turn repeats off):",
+	"edit.recurrence":         "🔄 Repeat",
+	"edit.leap_policy":        "📅 Feb 29",
+	"edit.recurrence_prompt":  "How often should the event repeat? A monthly event on a day the month does not have (e.g. the 31st) falls on the last day of the month:",
+	"edit.leap_policy_prompt": "When should a Feb 29 event be marked in non-leap years?",
+	"edit.calendar":           "📒 Calendar",
+	"edit.greeting":           "💌 Greeting",
+	"edit.attachments":        "📎 Attachments",
+	"edit.location":           "📍 Place",
+	"edit.leave_by":           "🚗 Leave by",
+	"edit.location_prompt":    "Send a location or a venue via 📎 → «Location» (or \"-\" to remove the meeting place):",
+	"edit.leave_by_prompt":    "Enter the meeting time and travel time in minutes (up to %d), e.g.: 15:00 40. The bot will remind you when to leave (or \"-\" to turn the reminder off):",
+	"edit.location_saved":     "✅ Meeting place saved.",
+	"edit.leave_by_saved":     "✅ Leave-by reminder saved.",
+	"edit.calendar_prompt":    "Where should the event go? Events in a shared calendar are visible to all its members, and each of them gets reminders:",
+
+	"priority.normal": "Normal",
+	"priority.high":   "⚡ High",
+
+	"recurrence.yearly":  "Every year",
+	"recurrence.monthly": "Every month",
+	"recurrence.once":    "Once",
+
+	"leap_policy.feb28":     "February 28",
+	"leap_policy.mar1":      "March 1",
+	"leap_policy.leap_only": "Leap years only",
+
+	"edit.title_prompt":  "Enter the new event title:",
+	"edit.type_prompt":   "Choose the new event type:",
+	"edit.date_prompt":   "Enter the new event date as DD.MM.YYYY (or DD.MM if the year is unknown):",
+	"edit.notify_prompt": "How many days before the event should I remind you? (enter a number from 1 to 30):",
+	"edit.desc_prompt":   "Enter the new event description:",
+	"edit.field_prompt":  "Choose a field to edit:",
+	"edit.success":       "✅ Event updated!\n\n🔤 Title: %s\n",
+
+	"delete.prompt": "❓ Are you sure you want to delete this event? " +
+		"It will be moved to the trash and can be restored within %s.",
+	"delete.confirm": "✅ Yes, delete",
+	"delete.cancel":  "❌ No, cancel",
+	"delete.done":    "✅ Event moved to the trash.",
+
+	"trash.empty": "🗑 The trash is empty.\n\nDeleted events are kept here for %s.",
+	"trash.title": "🗑 *Trash*\n\nDeleted events are kept here for %s. " +
+		"Tap an event to restore it:",
+	"trash.restored": "♻️ Event restored!",
+
+	"history.empty":    "🕓 This event has not been changed yet.",
+	"history.title":    "🕓 Previous versions of the event:\n",
+	"history.revision": "\n%d. Before the change on %s\n🔤 %s | 🏷 %s | 📅 %s | 🔔 %s before\n",
+	"history.revert":   "↩️ Restore version %d",
+
+	"settings.title":                "⚙️ *Settings:*\n\n",
+	"settings.time":                 "⏰ Notification time: *%s*\n",
+	"settings.language":             "🌐 Language: *%s*\n\n",
+	"settings.choose":               "Choose a setting to change:",
+	"settings.change_time":          "⏰ Change notification time",
+	"settings.change_language":      "🌐 Change language",
+	"settings.time_prompt":          "Enter the notification time as HH:MM (for example, 09:00):",
+	"settings.time_set":             "✅ Notification time set to %s",
+	"settings.language_prompt":      "Choose the interface language:",
+	"settings.language_set":         "✅ Interface language changed.",
+	"settings.event_types":          "🏷 Event types",
+	"settings.calendars":            "📒 Shared calendars",
+	"settings.greetings":            "💌 Greetings",
+	"settings.daily_digest":         "📬 Daily digest: *%s*\n",
+	"settings.weekly_digest":        "🗓 Monday weekly overview: *%s*\n",
+	"settings.toggle_daily_digest":  "📬 Daily digest",
+	"settings.toggle_weekly_digest": "🗓 Weekly overview",
+	"settings.on":                   "on",
+	"settings.off":                  "off",
+	"settings.quiet_hours":          "🌙 Quiet hours: *%s*\n",
+	"settings.dnd":                  "⏸ Reminders paused: *%s*\n",
+	"settings.dnd_until":            "until %s",
+	"settings.change_quiet_hours":   "🌙 Quiet hours",
+	"settings.change_dnd":           "⏸ Pause",
+	"settings.quiet_prompt":         "Enter your quiet hours as HH:MM-HH:MM, for example 23:00-08:00 (or \"-\" to turn them off). Regular reminders that fall into this time will arrive when quiet hours end.",
+	"settings.quiet_set":            "✅ Quiet hours: %s",
+	"settings.quiet_cleared":        "✅ Quiet hours turned off.",
+	"settings.dnd_prompt":           "How long should reminders be paused? Regular reminders will arrive when the pause ends; important events are still reminded as usual.",
+	"settings.dnd_resume":           "▶️ Resume reminders",
+	"settings.dnd_set":              "⏸ Reminders paused until %s.",
+	"settings.dnd_cleared":          "▶️ Reminders resumed.",
+
+	"digest.title":    "📬 Event digest",
+	"digest.today":    "🎉 Today:",
+	"digest.upcoming": "🔔 Coming up:",
+	"digest.week":     "🗓 This week:",
+
+	"types.title":              "🏷 *Event types*\n\nTap a type to change its icon, default reminder and notification time:",
+	"types.new":                "➕ New type",
+	"types.card":               "🏷 *%s*\n\n🔔 Default reminder: %s before\n⏰ Notification time: %s\n",
+	"types.greeting":           "💌 Greeting from a template on the day\n",
+	"types.time_default":       "from settings (%s)",
+	"types.edit_name":          "✏️ Name",
+	"types.edit_emoji":         "😀 Icon",
+	"types.edit_notify_days":   "🔔 Reminder days",
+	"types.edit_time":          "⏰ Time",
+	"types.edit_greeting":      "💌 Greeting",
+	"types.name_prompt":        "Enter a name for the new event type (for example, \"Work\" or \"Bills\"):",
+	"types.prompt_name":        "Enter a new name for the type (or \"-\" to restore the default):",
+	"types.prompt_emoji":       "Send an icon for the type (or \"-\" to remove it):",
+	"types.prompt_notify_days": "How many days before the event should I remind you by default? (enter a number from 1 to 30):",
+	"types.prompt_time":        "Enter the notification time for this type as HH:MM (or \"-\" to use the time from settings):",
+	"types.delete_prompt":      "❓ Delete the type \"%s\"? Events of this type will be kept.",
+	"types.delete_last":        "❌ You can't delete your last event type.",
+	"types.deleted":            "✅ Event type deleted.",
+
+	"calendars.title":          "📒 Shared calendars\n\nEvents in a shared calendar are visible to all its members, and everyone gets reminders according to their own settings.",
+	"calendars.empty":          "\n\nYou have no shared calendars yet. Create one and invite your family with a link.",
+	"calendars.item":           "📒 %s (%s)",
+	"calendars.new":            "➕ New calendar",
+	"calendars.name_prompt":    "Enter the calendar name (e.g. \"Family\"):",
+	"calendars.created":        "✅ Calendar \"%s\" created. Invite members with a link.",
+	"calendars.card":           "📒 %s\n\nYour role: %s\n\nMembers:\n%s",
+	"calendars.member":         "• %s — %s",
+	"calendars.role_owner":     "owner",
+	"calendars.role_editor":    "editor",
+	"calendars.role_viewer":    "viewer",
+	"calendars.add_event":      "➕ Add event",
+	"calendars.add_prompt":     "The event will be added to the \"%s\" calendar.",
+	"calendars.invite_editor":  "🔗 Invite an editor",
+	"calendars.invite_viewer":  "🔗 Invite a viewer",
+	"calendars.invite":         "🔗 Invitation to the \"%s\" calendar (role: %s). Send this link to the person you want to invite:\n\n%s",
+	"calendars.invite_invalid": "❌ The invitation is no longer valid: the calendar may have been deleted.",
+	"calendars.joined":         "✅ You joined the \"%s\" calendar (role: %s). Its events will appear in your list.",
+	"calendars.already_member": "ℹ️ You are already a member of the \"%s\" calendar (role: %s).",
+	"calendars.change_role":    "🔄 %s → %s",
+	"calendars.remove":         "✖️ Remove",
+	"calendars.leave":          "🚪 Leave calendar",
+	"calendars.left":           "✅ You left the \"%s\" calendar.",
+	"calendars.delete":         "🗑 Delete calendar",
+	"calendars.delete_prompt":  "❓ Delete the \"%s\" calendar? Its events will go back to their authors' personal lists.",
+	"calendars.deleted":        "✅ Calendar \"%s\" deleted.",
+	"calendars.not_found":      "❌ Calendar not found, or you are not allowed to do this.",
+	"calendars.personal":       "👤 My events",
+	"calendars.moved":          "✅ Event moved.",
+
+	"share.invite": "🔗 Send this link to someone who should also get reminders about \"%s\":\n\n%s\n\n" +
+		"Subscribers see the event with all your changes and get reminders by their own settings.\n\n" +
+		"Subscribers:\n%s",
+	"share.no_subscribers": "none yet",
+	"share.revoke":         "✖️ Remove %s",
+	"share.revoked":        "✅ Subscriber removed.",
+	"share.invite_invalid": "❌ This invite is no longer valid: the event may have been deleted.",
+	"share.subscribed":     "✅ You subscribed to \"%s\". It will appear in your list, and reminders will follow your own settings.",
+	"share.already":        "ℹ️ \"%s\" is already in your list.",
+	"share.unsubscribed":   "✅ You unsubscribed from \"%s\".",
+	"share.notify_prompt":  "🔔 How many days before \"%s\" should you be reminded? Enter a number from 1 to 30 or \"-\" to follow the author (%s):",
+	"share.notify_saved":   "✅ Reminder setting saved.",
+
+	"greetings.title":         "💌 Greeting templates\n\nAttach a template to an event or an event type, and on the day the bot will send a ready-made greeting for you to forward.",
+	"greetings.empty":         "\n\nNo templates yet. Write your own or pick one from the library.",
+	"greetings.new":           "➕ My template",
+	"greetings.library":       "📚 Library",
+	"greetings.library_title": "📚 Ready-made templates:\n\n%s",
+	"greetings.library_add":   "➕ Add #%d",
+	"greetings.library_1":     "Happy birthday, {name}! 🎉 Wishing you health, happiness and all your dreams come true!",
+	"greetings.library_2":     "Happy birthday, {name}! You are {age} old today 🎂 May this year bring you lots of joy!",
+	"greetings.library_3":     "Happy anniversary, {name}! 💐 {years_together} together — wishing you love and many more happy years!",
+	"greetings.library_4":     "Congratulations, {name}! 🎊 May everything you've planned come true!",
+	"greetings.prompt": "Enter the greeting text. You can use placeholders:\n" +
+		"{name} — the event title\n" +
+		"{age} — the age, e.g. \"30 years\"\n" +
+		"{years_together} — years together, e.g. \"5 years\"",
+	"greetings.created":      "✅ Greeting template saved.",
+	"greetings.card":         "💌 Greeting template:\n\n%s",
+	"greetings.deleted":      "✅ Greeting template deleted.",
+	"greetings.not_found":    "❌ Greeting template not found.",
+	"greetings.choose":       "Choose a greeting template:",
+	"greetings.choose_empty": "You have no greeting templates yet. Create them in the settings.",
+	"greetings.none":         "🚫 No greeting",
+	"greetings.set":          "✅ Greeting updated.",
+
+	"gifts.title":        "🎁 Gifts: %s\n\n",
+	"gifts.empty":        "No ideas yet.",
+	"gifts.history":      "\n\n📜 Already given:\n%s",
+	"gifts.history_year": "%d: %s",
+	"gifts.add":          "➕ Add an idea",
+	"gifts.prompt": "Enter a gift idea for «%s» in the format:\n" +
+		"Title; price; link\n\n" +
+		"Price and link are optional, e.g.: Book; $20; https://example.com",
+	"gifts.card":          "🎁 %s\n\nStatus: %s",
+	"gifts.price":         "\nPrice: %s",
+	"gifts.link":          "\nLink: %s",
+	"gifts.status_idea":   "Idea",
+	"gifts.status_bought": "Bought",
+	"gifts.status_given":  "Given",
+	"gifts.already_given": "⚠️ «%s» was already given in %d.",
+	"gifts.deleted":       "✅ Gift idea deleted.",
+	"gifts.not_found":     "❌ Gift not found.",
+
+	"attachments.title":     "📎 Attachments of «%s»: %d",
+	"attachments.empty":     "\n\nAttach a photo of the person, an invitation scan or a ticket — they will come with the reminder on the day of the event.",
+	"attachments.add":       "➕ Attach",
+	"attachments.prompt":    "Send a photo or a file for «%s». You can send several in a row, then press «Done».",
+	"attachments.done":      "✅ Done",
+	"attachments.added":     "✅ Attached (%d of %d). Send more or press «Done».",
+	"attachments.limit":     "ℹ️ An event can have at most %d attachments.",
+	"attachments.deleted":   "✅ Attachment deleted.",
+	"attachments.not_found": "❌ Attachment not found.",
+
+	"location.pin": "pin on the map",
+
+	"tasks.title":     "📋 Preparing for «%s» — %s\n\n",
+	"tasks.empty":     "No tasks yet. Add things to do in advance, like booking a table or ordering a cake.",
+	"tasks.progress":  "Done: %d of %d. Tap a task to tick it off.",
+	"tasks.add":       "➕ Add a task",
+	"tasks.prompt":    "Enter a task for «%s». To get a reminder in advance, put how many days before the event, e.g.:\n7: book a table",
+	"tasks.due":       "%s before",
+	"tasks.due_today": "on the day",
+	"tasks.limit":     "ℹ️ An event can have at most %d tasks.",
+	"tasks.not_found": "❌ Task not found.",
+
+	"search.prompt": "🔎 What are you looking for? Enter words from the title, description or tags. " +
+		"You can add filters: #family or type:Anniversary (quote names with spaces: type:\"Birthday party\").",
+	"search.title": "🔎 Search results for \"%s\": %d",
+	"search.page":  " (page %d of %d)",
+	"search.empty": "🔎 Nothing found for \"%s\".",
+
+	"inline.open_bot":        "➕ Open the bot",
+	"inline.date":            "📅 %s — %s\n",
+	"inline.new_title":       "➕ Remind: %s",
+	"inline.new_description": "%s — create a one-time reminder and send it to the chat",
+	"inline.new_message":     "⏰ Reminder \"%s\" — %s",
+
+	"milestone.birthday":    "turns %[2]d",
+	"milestone.anniversary": "%s anniversary",
+	"milestone.round":       "🎊 milestone: %s",
+
+	"mydata.caption": "📦 Your data: profile, settings, events and notification history.",
+
+	"deleteme.prompt": "⚠️ Are you sure you want to delete your account? " +
+		"All your events, settings and notification history will be deleted permanently.\n\n" +
+		"You can export your data with /mydata before deleting.",
+	"deleteme.confirm":   "🗑 Yes, delete everything",
+	"deleteme.cancelled": "👌 Account deletion cancelled.",
+	"deleteme.done":      "✅ Your account and all events have been deleted. Send /start to begin again.",
+
+	"error.date_format":      "invalid date format, use DD.MM.YYYY or DD.MM",
+	"error.day":              "invalid day",
+	"error.month":            "invalid month",
+	"error.year":             "invalid year",
+	"error.date_invalid":     "this date does not exist",
+	"error.time_format":      "invalid time format, use HH:MM",
+	"error.hour":             "invalid hour (0-23)",
+	"error.minute":           "invalid minute (0-59)",
+	"error.time_range":       "invalid range format, use HH:MM-HH:MM",
+	"error.time_range_empty": "the start and end of the range are the same",
+
+	"error.retry_date":           "❌ %s. Please enter the date as DD.MM.YYYY or DD.MM:",
+	"error.retry_time":           "❌ %s. Please enter the time as HH:MM:",
+	"error.retry_time_range":     "❌ %s. Please enter the range as HH:MM-HH:MM:",
+	"error.notify_days":          "❌ Please enter a number from 1 to 30:",
+	"error.nag_hours":            "❌ Please enter a number from 0 to 24:",
+	"error.type_name":            "❌ The type name must be 1 to 32 characters long.",
+	"error.type_emoji":           "❌ Please send a single icon (emoji).",
+	"error.calendar_name":        "❌ The calendar name must be 1 to 64 characters long.",
+	"error.greeting_text":        "❌ The greeting must be 1 to %d characters long.",
+	"error.gift_title":           "❌ The gift title must be 1 to %d characters long.",
+	"error.attachment":           "❌ Please send a photo or a file.",
+	"error.location":             "❌ Please send a location or a venue via 📎 → «Location».",
+	"error.leave_by":             "❌ Enter the meeting time and travel time in minutes from 1 to %d, e.g.: 15:00 40.",
+	"error.task":                 "❌ The task title must be 1 to %d characters long and the due offset 0 to %d days, e.g.: 7: book a table",
+	"error.event_type_not_found": "❌ Event type not found.",
+	"error.create_event":         "❌ Something went wrong while creating the event.",
+	"error.save_event":           "❌ Something went wrong while saving the event.",
+	"error.save_changes":         "❌ Something went wrong while saving your changes.",
+	"error.delete_event":         "❌ Something went wrong while deleting the event.",
+	"error.restore_event":        "❌ Couldn't restore the event: it may have expired from the trash.",
+	"error.revert_event":         "❌ Couldn't undo the changes: the event version was not found.",
+	"error.revision_not_found":   "❌ Event version not found.",
+	"error.event_not_found":      "❌ Event not found.",
+	"error.list_events":          "❌ Something went wrong while loading your events.",
+	"error.trash":                "❌ Something went wrong while opening the trash.",
+	"error.history":              "❌ Something went wrong while loading the change history.",
+	"error.settings":             "❌ Something went wrong while loading your settings.",
+	"error.update_settings":      "❌ Something went wrong while updating your settings.",
+	"error.save_settings":        "❌ Something went wrong while saving your settings.",
+	"error.export":               "❌ Something went wrong while exporting your data.",
+	"error.account_not_found":    "❌ Account not found.",
+	"error.register":             "❌ Could not register you. Please try again: /start",
+	"error.delete_account":       "❌ Something went wrong while deleting your account.",
+	"error.stale_button":         "⚠️ This button has expired. Please open the menu again.",
+	"error.search":               "❌ Something went wrong while searching.",
+	"error.unknown_command":      "Unknown command. Use /help to see the available commands.",
+}
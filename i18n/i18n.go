@@ -0,0 +1,183 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultLang язык по умолчанию
+const DefaultLang = "ru"
+
+// Languages поддерживаемые языки в порядке отображения
+var Languages = []string{"ru", "en"}
+
+// catalogs каталоги сообщений по языкам
+var catalogs = map[string]map[string]string{
+	"ru": ru,
+	"en": en,
+}
+
+// dateLayouts форматы отображения дат по языкам
+var dateLayouts = map[string]string{
+	"ru": "02.01.2006",
+	"en": "Jan 2, 2006",
+}
+
+// dayMonthLayouts форматы дат без года, если год события неизвестен
+var dayMonthLayouts = map[string]string{
+	"ru": "02.01",
+	"en": "Jan 2",
+}
+
+// Detect выбирает язык по коду языка из профиля Telegram
+func Detect(languageCode string) string {
+	code := strings.ToLower(languageCode)
+	if code == "" {
+		return DefaultLang
+	}
+
+	for _, lang := range Languages {
+		if code == lang || strings.HasPrefix(code, lang+"-") {
+			return lang
+		}
+	}
+
+	// Пользователям из стран бывшего СССР привычнее русский интерфейс
+	switch {
+	case strings.HasPrefix(code, "uk"), strings.HasPrefix(code, "be"), strings.HasPrefix(code, "kk"):
+		return "ru"
+	}
+	return "en"
+}
+
+// Normalize возвращает поддерживаемый язык или язык по умолчанию
+func Normalize(lang string) string {
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return DefaultLang
+}
+
+// Has проверяет, есть ли сообщение с ключом в каталоге языка
+func Has(lang, key string) bool {
+	_, ok := catalogs[Normalize(lang)][key]
+	return ok
+}
+
+// T возвращает сообщение по ключу, подставляя аргументы в стиле fmt.Sprintf.
+// Если перевода нет, используется каталог языка по умолчанию, а затем сам ключ.
+func T(lang, key string, args ...interface{}) string {
+	message, ok := catalogs[Normalize(lang)][key]
+	if !ok {
+		message, ok = catalogs[DefaultLang][key]
+	}
+	if !ok {
+		message = key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// N возвращает число вместе со словом в нужной форме: «1 день», «2 дня», «5 дней».
+// Формы слова хранятся в каталоге под ключом plural.<key> через «|».
+func N(lang string, n int, key string) string {
+	return fmt.Sprintf("%d %s", n, Plural(lang, n, key))
+}
+
+// Plural возвращает форму слова для числа n
+func Plural(lang string, n int, key string) string {
+	forms := strings.Split(T(lang, "plural."+key), "|")
+
+	index := pluralIndex(Normalize(lang), n)
+	if index >= len(forms) {
+		index = len(forms) - 1
+	}
+	return forms[index]
+}
+
+// pluralIndex выбирает номер формы множественного числа по правилам языка
+func pluralIndex(lang string, n int) int {
+	if n < 0 {
+		n = -n
+	}
+
+	switch lang {
+	case "ru":
+		// 1 день, 2 дня, 5 дней, 11 дней, 21 день
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+			return 1
+		default:
+			return 2
+		}
+	default:
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+}
+
+// FormatDate форматирует дату из БД (ГГГГ-ММ-ДД) для отображения
+func FormatDate(lang, dbDate string) string {
+	if dbDate == "" {
+		return ""
+	}
+
+	date, err := time.Parse("2006-01-02", dbDate)
+	if err != nil {
+		return dbDate
+	}
+
+	if date.Year() == 0 {
+		// Год события неизвестен
+		return date.Format(dayMonthLayouts[Normalize(lang)])
+	}
+	return date.Format(dateLayouts[Normalize(lang)])
+}
+
+// Ordinal возвращает порядковое числительное цифрами: «15-я» или «15th».
+// Русская форма согласуется со словом женского рода («годовщина»).
+func Ordinal(lang string, n int) string {
+	if Normalize(lang) != "en" {
+		return fmt.Sprintf("%d-я", n)
+	}
+
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// FormatDateTime форматирует дату и время для отображения
+func FormatDateTime(lang string, t time.Time) string {
+	return t.Format(dateLayouts[Normalize(lang)] + " 15:04")
+}
+
+// EventType возвращает название встроенного типа события.
+// Неизвестные ключи возвращаются как есть.
+func EventType(lang, key string) string {
+	if Has(lang, "type."+key) {
+		return T(lang, "type."+key)
+	}
+	return key
+}
+
+// LanguageName возвращает название языка на нем самом
+func LanguageName(lang string) string {
+	return T(lang, "language.name")
+}
@@ -0,0 +1,436 @@
+package i18n
+
+// ru каталог сообщений на русском языке
+var ru = map[string]string{
+	"language.name": "🇷🇺 Русский",
+
+	"plural.days":    "день|дня|дней",
+	"plural.years":   "год|года|лет",
+	"plural.hours":   "час|часа|часов",
+	"plural.minutes": "минута|минуты|минут",
+
+	"type.birthday":    "День рождения",
+	"type.meeting":     "Встреча",
+	"type.holiday":     "Праздник",
+	"type.anniversary": "Годовщина",
+	"type.other":       "Другое",
+
+	"start.welcome": "👋 Привет, %s!\n\n" +
+		"Я бот для напоминания о днях рождения и важных событиях. " +
+		"С моей помощью вы не забудете поздравить друзей и близких с праздниками.\n\n" +
+		"Используйте меню для управления вашими событиями и напоминаниями:",
+	"help.text": "🤖 *Справка по командам:*\n\n" +
+		"/start - запустить бота и показать главное меню\n" +
+		"/help - показать справку по командам\n" +
+		"/add - добавить новое событие\n" +
+		"/list - показать список ваших событий\n" +
+		"/today - события сегодня\n" +
+		"/week - события на ближайшие 7 дней\n" +
+		"/month - события на ближайшие 30 дней\n" +
+		"/next - ближайшее событие\n" +
+		"/search - найти события по словам, #тегам и type:типу\n" +
+		"/settings - настройки уведомлений и языка\n" +
+		"/calendars - общие календари для семьи и друзей\n" +
+		"/trash - корзина с удаленными событиями\n" +
+		"/mydata - выгрузить все ваши данные\n" +
+		"/deleteme - удалить аккаунт и все события\n\n" +
+		"Вы также можете использовать кнопки меню для более удобной навигации.",
+
+	"group.welcome": "👥 В группе события и настройки общие для всех участников, а напоминания приходят сюда. " +
+		"Добавлять, изменять и удалять события могут только администраторы группы, " +
+		"смотреть список и повестку — все участники.",
+	"group.admin_only": "⛔ Это действие в группе доступно только администраторам.",
+
+	"menu.prompt":   "Что вы хотите сделать?",
+	"menu.add":      "Добавить событие",
+	"menu.list":     "Мои события",
+	"menu.today":    "📅 Сегодня",
+	"menu.agenda":   "🗓 Ближайшие события",
+	"menu.settings": "Настройки",
+	"menu.help":     "Помощь",
+
+	"button.add_event":    "➕ Добавить событие",
+	"button.trash":        "🗑 Корзина",
+	"button.main_menu":    "🏠 Главное меню",
+	"button.back":         "⬅️ Назад",
+	"button.back_to_list": "⬅️ Назад к списку",
+	"button.undo":         "↩️ Отменить",
+
+	"notify.today":           "🎉 Сегодня: %s (%s)\n%s",
+	"notify.greeting_hint":   "\n\n💌 Готовое поздравление — в следующем сообщении, его можно переслать.",
+	"notify.gift_ideas":      "\n\n🎁 Идеи подарков, которые еще не куплены:\n%s",
+	"notify.leave_by":        "🚗 Пора выезжать на «%s»: встреча в %s, дорога займет %s.",
+	"notify.tasks":           "📋 Подготовка к «%s» (%s):\n%s\n\nОтметьте выполненное кнопками ниже.",
+	"notify.in_days":         "🔔 Через %s: %s (%s)\n%s",
+	"notify.nag":             "⚡ Напоминаю еще раз: %s (%s) — %s\n%s",
+	"notify.snoozed":         "⏰ Напоминаю: %s (%s) — %s\n%s",
+	"notify.snooze_1h":       "⏰ 1 ч",
+	"notify.snooze_3h":       "⏰ 3 ч",
+	"notify.snooze_tomorrow": "🌙 Завтра",
+	"notify.done":            "✅ Готово",
+	"notify.snoozed_until":   "⏰ Напомню о «%s» %s.",
+	"notify.acknowledged":    "✅ «%s» отмечено. Больше напоминаний об этом событии в этот раз не будет.",
+
+	"add.title_prompt":       "Введите название события:",
+	"add.type_prompt":        "Выберите тип события:",
+	"add.date_prompt":        "Введите дату события в формате ДД.ММ.ГГГГ (или ДД.ММ, если год неизвестен):",
+	"add.notify_prompt":      "За сколько дней до события отправить напоминание? (введите число от 1 до 30):",
+	"add.desc_prompt":        "Введите описание события (или отправьте /skip, чтобы пропустить). Можно прислать фото или файл с подписью — подпись станет описанием:",
+	"add.desc_location_hint": "\n\n📍 Вместо адреса встречи можно прислать геопозицию или место через 📎 → «Геопозиция».",
+	"add.leave_by_hint":      "\n🚗 Чтобы бот напомнил, когда выезжать, укажите время встречи и время в пути в редактировании события.",
+	"add.notify_default":     "🔔 Как в типе: за %s",
+	"add.success":            "✅ Событие успешно добавлено!\n\n🔤 Название: %s\n",
+
+	"event.details":                    "🏷 Тип: %s\n📅 Дата: %s\n🔔 Напоминание: за %s\n",
+	"event.description":                "📝 Описание: %s\n",
+	"event.location":                   "📍 Место: %s\n",
+	"event.leave_by":                   "🚗 Выезд в %s (встреча в %s, в пути %s)\n",
+	"event.calendar":                   "📒 Календарь: %s\n",
+	"event.greeting":                   "💌 Поздравление по шаблону в день события\n",
+	"event.tags":                       "🔖 Теги: %s\n",
+	"event.milestone":                  "🎈 %s: %s\n",
+	"event.priority_high":              "⚡ Высокий приоритет\n",
+	"event.nag":                        "🔁 Повторять каждые %s, пока не отмечу «Готово»\n",
+	"event.monthly":                    "🔄 Повторяется каждый месяц\n",
+	"event.once":                       "📌 Разовое: после даты событие уйдет в корзину\n",
+	"event.leap_policy":                "📅 В невисокосный год: %s\n",
+	"event.edit":                       "✏️ Редактировать",
+	"event.delete":                     "❌ Удалить",
+	"event.share":                      "🔗 Поделиться",
+	"event.unsubscribe":                "🔕 Отписаться",
+	"event.subscribed":                 "🔗 Вы подписаны на это событие: изменения автора появятся здесь, а напоминания приходят по вашим настройкам\n",
+	"event.subscription_notify":        "🔔 Когда напоминать",
+	"event.subscription_notify_author": "🔔 Вам напомнят за %s, как указал автор\n",
+	"event.subscription_notify_own":    "🔔 Вам напомнят за %s\n",
+	"event.history":                    "🕓 История изменений",
+	"event.gifts":                      "🎁 Подарки",
+	"event.tasks":                      "📋 Задачи",
+	"event.attachments":                "📎 Вложения",
+	"event.attachments_count":          "\n📎 Вложений: %d",
+
+	"list.title":        "🗓 Ваши события:",
+	"list.empty":        "У вас пока нет добавленных событий. Добавьте первое событие!",
+	"list.today":        " (сегодня!)",
+	"list.in_days":      " (через %s)",
+	"list.nothing":      "🗓 Нет событий, подходящих под фильтры.",
+	"list.filtered":     "🗓 Ваши события: %d из %d",
+	"list.sort":         "↕️ Сортировка: %s",
+	"list.sort_next":    "ближайшие",
+	"list.sort_name":    "по названию",
+	"list.sort_type":    "по типу",
+	"list.sort_created": "новые",
+	"list.type":         "🏷 %s",
+	"list.window":       "📅 %s",
+	"list.filter_all":   "все",
+
+	"agenda.today_title":  "📅 События сегодня:",
+	"agenda.week_title":   "🗓 События на ближайшие 7 дней:",
+	"agenda.month_title":  "🗓 События на ближайшие 30 дней:",
+	"agenda.next_title":   "⏭ Ближайшее событие:",
+	"agenda.empty":        "Событий нет.",
+	"agenda.line":         "📅 %s — %s (%s), %s",
+	"agenda.when_today":   "сегодня!",
+	"agenda.when_in_days": "через %s",
+	"agenda.today":        "Сегодня",
+	"agenda.week":         "Неделя",
+	"agenda.month":        "Месяц",
+	"agenda.next":         "Ближайшее",
+
+	"edit.prompt":             "Что вы хотите изменить?",
+	"edit.title":              "🔤 Название",
+	"edit.type":               "🏷 Тип",
+	"edit.date":               "📅 Дата",
+	"edit.notify_days":        "🔔 Дни напоминания",
+	"edit.description":        "📝 Описание",
+	"edit.tags":               "🔖 Теги",
+	"edit.tags_prompt":        "Введите теги через пробел или запятую, например: семья работа (или «-», чтобы убрать все теги):",
+	"edit.tags_saved":         "✅ Теги сохранены.",
+	"edit.priority":           "⚡ Приоритет",
+	"edit.nag_hours":          "🔁 Повтор",
+	"edit.priority_prompt":    "Выберите приоритет события:",
+	"edit.nag_prompt":         "Через сколько часов повторять напоминание о важном событии, пока вы не нажмете «Готово»? Интервал удваивается с каждым повтором, ночью повторов нет. Введите число от 1 до 24 (или 0, чтобы не повторять):",
+	"edit.recurrence":         "🔄 Повторение",
+	"edit.leap_policy":        "📅 29 февраля",
+	"edit.recurrence_prompt":  "Как часто повторять событие? Ежемесячное событие с числом, которого нет в месяце (например, 31-е), отмечается в последний день месяца:",
+	"edit.leap_policy_prompt": "Когда отмечать событие 29 февраля в невисокосный год?",
+	"edit.calendar":           "📒 Календарь",
+	"edit.greeting":           "💌 Поздравление",
+	"edit.attachments":        "📎 Вложения",
+	"edit.location":           "📍 Место",
+	"edit.leave_by":           "🚗 Выезд",
+	"edit.location_prompt":    "Пришлите геопозицию или место через 📎 → «Геопозиция» (или «-», чтобы убрать место встречи):",
+	"edit.leave_by_prompt":    "Введите время встречи и время в пути в минутах (до %d), например: 15:00 40. Бот напомнит, когда выезжать (или «-», чтобы отключить напоминание):",
+	"edit.location_saved":     "✅ Место встречи сохранено.",
+	"edit.leave_by_saved":     "✅ Напоминание о выезде сохранено.",
+	"edit.calendar_prompt":    "Куда перенести событие? События общего календаря видят и получают в напоминаниях все его участники:",
+
+	"priority.normal": "Обычный",
+	"priority.high":   "⚡ Высокий",
+
+	"recurrence.yearly":  "Каждый год",
+	"recurrence.monthly": "Каждый месяц",
+	"recurrence.once":    "Один раз",
+
+	"leap_policy.feb28":     "28 февраля",
+	"leap_policy.mar1":      "1 марта",
+	"leap_policy.leap_only": "Только в високосный год",
+
+	"edit.title_prompt":  "Введите новое название события:",
+	"edit.type_prompt":   "Выберите новый тип события:",
+	"edit.date_prompt":   "Введите новую дату события в формате ДД.ММ.ГГГГ (или ДД.ММ, если год неизвестен):",
+	"edit.notify_prompt": "За сколько дней до события отправлять напоминание? (введите число от 1 до 30):",
+	"edit.desc_prompt":   "Введите новое описание события:",
+	"edit.field_prompt":  "Выберите поле для редактирования:",
+	"edit.success":       "✅ Событие успешно обновлено!\n\n🔤 Название: %s\n",
+
+	"delete.prompt": "❓ Вы уверены, что хотите удалить это событие? " +
+		"Оно попадет в корзину, и его можно будет восстановить в течение %s.",
+	"delete.confirm": "✅ Да, удалить",
+	"delete.cancel":  "❌ Нет, отменить",
+	"delete.done":    "✅ Событие перемещено в корзину.",
+
+	"trash.empty": "🗑 Корзина пуста.\n\nУдаленные события хранятся здесь %s.",
+	"trash.title": "🗑 *Корзина*\n\nУдаленные события хранятся здесь %s. " +
+		"Нажмите на событие, чтобы восстановить его:",
+	"trash.restored": "♻️ Событие восстановлено!",
+
+	"history.empty":    "🕓 Событие еще не изменялось.",
+	"history.title":    "🕓 Прежние версии события:\n",
+	"history.revision": "\n%d. До изменения %s\n🔤 %s | 🏷 %s | 📅 %s | 🔔 за %s\n",
+	"history.revert":   "↩️ Вернуть версию %d",
+
+	"settings.title":                "⚙️ *Настройки:*\n\n",
+	"settings.time":                 "⏰ Время уведомлений: *%s*\n",
+	"settings.language":             "🌐 Язык: *%s*\n\n",
+	"settings.choose":               "Выберите настройку, которую хотите изменить:",
+	"settings.change_time":          "⏰ Изменить время уведомлений",
+	"settings.change_language":      "🌐 Изменить язык",
+	"settings.time_prompt":          "Введите время для получения уведомлений в формате ЧЧ:ММ (например, 09:00):",
+	"settings.time_set":             "✅ Время уведомлений установлено на %s",
+	"settings.language_prompt":      "Выберите язык интерфейса:",
+	"settings.language_set":         "✅ Язык интерфейса изменен.",
+	"settings.event_types":          "🏷 Типы событий",
+	"settings.calendars":            "📒 Общие календари",
+	"settings.greetings":            "💌 Поздравления",
+	"settings.daily_digest":         "📬 Ежедневная сводка: *%s*\n",
+	"settings.weekly_digest":        "🗓 Обзор недели по понедельникам: *%s*\n",
+	"settings.toggle_daily_digest":  "📬 Ежедневная сводка",
+	"settings.toggle_weekly_digest": "🗓 Обзор недели",
+	"settings.on":                   "вкл",
+	"settings.off":                  "выкл",
+	"settings.quiet_hours":          "🌙 Тихие часы: *%s*\n",
+	"settings.dnd":                  "⏸ Пауза напоминаний: *%s*\n",
+	"settings.dnd_until":            "до %s",
+	"settings.change_quiet_hours":   "🌙 Тихие часы",
+	"settings.change_dnd":           "⏸ Пауза",
+	"settings.quiet_prompt":         "Введите тихие часы в формате ЧЧ:ММ-ЧЧ:ММ, например 23:00-08:00 (или «-», чтобы отключить). Обычные напоминания, выпавшие на это время, придут после окончания тихих часов.",
+	"settings.quiet_set":            "✅ Тихие часы: %s",
+	"settings.quiet_cleared":        "✅ Тихие часы отключены.",
+	"settings.dnd_prompt":           "На сколько приостановить напоминания? Обычные напоминания придут после окончания паузы, о важных событиях бот напомнит как обычно.",
+	"settings.dnd_resume":           "▶️ Возобновить напоминания",
+	"settings.dnd_set":              "⏸ Напоминания приостановлены до %s.",
+	"settings.dnd_cleared":          "▶️ Напоминания возобновлены.",
+
+	"digest.title":    "📬 Сводка событий",
+	"digest.today":    "🎉 Сегодня:",
+	"digest.upcoming": "🔔 Скоро:",
+	"digest.week":     "🗓 На этой неделе:",
+
+	"types.title":              "🏷 *Типы событий*\n\nНажмите на тип, чтобы изменить его значок, напоминание по умолчанию и время уведомлений:",
+	"types.new":                "➕ Новый тип",
+	"types.card":               "🏷 *%s*\n\n🔔 Напоминание по умолчанию: за %s\n⏰ Время уведомлений: %s\n",
+	"types.greeting":           "💌 Поздравление по шаблону в день события\n",
+	"types.time_default":       "как в настройках (%s)",
+	"types.edit_name":          "✏️ Название",
+	"types.edit_emoji":         "😀 Значок",
+	"types.edit_notify_days":   "🔔 Дни напоминания",
+	"types.edit_time":          "⏰ Время",
+	"types.edit_greeting":      "💌 Поздравление",
+	"types.name_prompt":        "Введите название нового типа событий (например, «Работа» или «Платежи»):",
+	"types.prompt_name":        "Введите новое название типа (или «-», чтобы вернуть стандартное):",
+	"types.prompt_emoji":       "Отправьте значок для типа (или «-», чтобы убрать его):",
+	"types.prompt_notify_days": "За сколько дней до события напоминать по умолчанию? (введите число от 1 до 30):",
+	"types.prompt_time":        "Введите время уведомлений для этого типа в формате ЧЧ:ММ (или «-», чтобы использовать время из настроек):",
+	"types.delete_prompt":      "❓ Удалить тип «%s»? События этого типа сохранятся.",
+	"types.delete_last":        "❌ Нельзя удалить последний тип событий.",
+	"types.deleted":            "✅ Тип событий удален.",
+
+	"calendars.title":          "📒 Общие календари\n\nСобытия общего календаря видят все его участники, а напоминания каждый получает по своим настройкам.",
+	"calendars.empty":          "\n\nУ вас пока нет общих календарей. Создайте календарь и пригласите близких по ссылке.",
+	"calendars.item":           "📒 %s (%s)",
+	"calendars.new":            "➕ Новый календарь",
+	"calendars.name_prompt":    "Введите название календаря (например, «Семья»):",
+	"calendars.created":        "✅ Календарь «%s» создан. Пригласите участников по ссылке.",
+	"calendars.card":           "📒 %s\n\nВаша роль: %s\n\nУчастники:\n%s",
+	"calendars.member":         "• %s — %s",
+	"calendars.role_owner":     "владелец",
+	"calendars.role_editor":    "редактор",
+	"calendars.role_viewer":    "зритель",
+	"calendars.add_event":      "➕ Добавить событие",
+	"calendars.add_prompt":     "Событие будет добавлено в календарь «%s».",
+	"calendars.invite_editor":  "🔗 Пригласить редактора",
+	"calendars.invite_viewer":  "🔗 Пригласить зрителя",
+	"calendars.invite":         "🔗 Приглашение в календарь «%s» (роль: %s). Отправьте эту ссылку тому, кого хотите пригласить:\n\n%s",
+	"calendars.invite_invalid": "❌ Приглашение недействительно: возможно, календарь удален.",
+	"calendars.joined":         "✅ Вы присоединились к календарю «%s» (роль: %s). Его события появятся в вашем списке.",
+	"calendars.already_member": "ℹ️ Вы уже участвуете в календаре «%s» (роль: %s).",
+	"calendars.change_role":    "🔄 %s → %s",
+	"calendars.remove":         "✖️ Исключить",
+	"calendars.leave":          "🚪 Выйти из календаря",
+	"calendars.left":           "✅ Вы вышли из календаря «%s».",
+	"calendars.delete":         "🗑 Удалить календарь",
+	"calendars.delete_prompt":  "❓ Удалить календарь «%s»? Его события вернутся в личные списки их авторов.",
+	"calendars.deleted":        "✅ Календарь «%s» удален.",
+	"calendars.not_found":      "❌ Календарь не найден или у вас нет прав на это действие.",
+	"calendars.personal":       "👤 Мои события",
+	"calendars.moved":          "✅ Событие перенесено.",
+
+	"share.invite": "🔗 Отправьте эту ссылку тому, кто тоже должен получать напоминания о событии «%s»:\n\n%s\n\n" +
+		"Подписчик увидит событие со всеми вашими изменениями, а напоминания будет получать по своим настройкам.\n\n" +
+		"Подписчики:\n%s",
+	"share.no_subscribers": "пока никого",
+	"share.revoke":         "✖️ Отключить %s",
+	"share.revoked":        "✅ Подписчик отключен.",
+	"share.invite_invalid": "❌ Приглашение недействительно: возможно, событие удалено.",
+	"share.subscribed":     "✅ Вы подписались на событие «%s». Оно появится в вашем списке, а напоминания будут приходить по вашим настройкам.",
+	"share.already":        "ℹ️ Событие «%s» уже есть в вашем списке.",
+	"share.unsubscribed":   "✅ Вы отписались от события «%s».",
+	"share.notify_prompt":  "🔔 За сколько дней напоминать вам о событии «%s»? Введите число от 1 до 30 или «-», чтобы напоминать как у автора (за %s):",
+	"share.notify_saved":   "✅ Срок напоминаний сохранен.",
+
+	"greetings.title":         "💌 Шаблоны поздравлений\n\nПривяжите шаблон к событию или типу события, и в день события бот пришлет готовое поздравление, которое останется переслать.",
+	"greetings.empty":         "\n\nШаблонов пока нет. Напишите свой или возьмите готовый из библиотеки.",
+	"greetings.new":           "➕ Свой шаблон",
+	"greetings.library":       "📚 Библиотека",
+	"greetings.library_title": "📚 Готовые шаблоны:\n\n%s",
+	"greetings.library_add":   "➕ Добавить №%d",
+	"greetings.library_1":     "{name}, с днём рождения! 🎉 Здоровья, счастья и исполнения всех желаний!",
+	"greetings.library_2":     "{name}, поздравляю! Сегодня тебе {age} 🎂 Пусть этот год принесёт много радости и новых открытий!",
+	"greetings.library_3":     "{name}, с годовщиной! 💐 Уже {years_together} вместе — желаю любви, тепла и ещё многих счастливых лет!",
+	"greetings.library_4":     "{name}, с праздником! 🎊 Пусть всё задуманное сбудется!",
+	"greetings.prompt": "Введите текст поздравления. Можно использовать подстановки:\n" +
+		"{name} — название события\n" +
+		"{age} — возраст, например «30 лет»\n" +
+		"{years_together} — сколько лет вместе, например «5 лет»",
+	"greetings.created":      "✅ Шаблон поздравления сохранен.",
+	"greetings.card":         "💌 Шаблон поздравления:\n\n%s",
+	"greetings.deleted":      "✅ Шаблон поздравления удален.",
+	"greetings.not_found":    "❌ Шаблон поздравления не найден.",
+	"greetings.choose":       "Выберите шаблон поздравления:",
+	"greetings.choose_empty": "У вас пока нет шаблонов поздравлений. Создайте их в настройках.",
+	"greetings.none":         "🚫 Без поздравления",
+	"greetings.set":          "✅ Поздравление изменено.",
+
+	"gifts.title":        "🎁 Подарки: %s\n\n",
+	"gifts.empty":        "Идей пока нет.",
+	"gifts.history":      "\n\n📜 Уже дарили:\n%s",
+	"gifts.history_year": "%d: %s",
+	"gifts.add":          "➕ Добавить идею",
+	"gifts.prompt": "Введите идею подарка к событию «%s» в формате:\n" +
+		"Название; цена; ссылка\n\n" +
+		"Цена и ссылка необязательны, например: Книга; 1500 ₽; https://example.com",
+	"gifts.card":          "🎁 %s\n\nСтатус: %s",
+	"gifts.price":         "\nЦена: %s",
+	"gifts.link":          "\nСсылка: %s",
+	"gifts.status_idea":   "Идея",
+	"gifts.status_bought": "Куплен",
+	"gifts.status_given":  "Подарен",
+	"gifts.already_given": "⚠️ «%s» уже дарили в %d году.",
+	"gifts.deleted":       "✅ Идея подарка удалена.",
+	"gifts.not_found":     "❌ Подарок не найден.",
+
+	"attachments.title":     "📎 Вложения события «%s»: %d",
+	"attachments.empty":     "\n\nПрикрепите фото человека, скан приглашения или билет — они придут вместе с напоминанием в день события.",
+	"attachments.add":       "➕ Прикрепить",
+	"attachments.prompt":    "Пришлите фото или файл для события «%s». Можно несколько подряд, затем нажмите «Готово».",
+	"attachments.done":      "✅ Готово",
+	"attachments.added":     "✅ Прикреплено (%d из %d). Пришлите еще или нажмите «Готово».",
+	"attachments.limit":     "ℹ️ К событию можно прикрепить не больше %d вложений.",
+	"attachments.deleted":   "✅ Вложение удалено.",
+	"attachments.not_found": "❌ Вложение не найдено.",
+
+	"location.pin": "точка на карте",
+
+	"tasks.title":     "📋 Подготовка к «%s» — %s\n\n",
+	"tasks.empty":     "Задач пока нет. Добавьте дела, которые нужно сделать заранее, например забронировать столик или заказать торт.",
+	"tasks.progress":  "Выполнено: %d из %d. Нажмите на задачу, чтобы отметить ее.",
+	"tasks.add":       "➕ Добавить задачу",
+	"tasks.prompt":    "Введите задачу к событию «%s». Чтобы бот напомнил о ней заранее, укажите, за сколько дней до события, например:\n7: забронировать столик",
+	"tasks.due":       "за %s",
+	"tasks.due_today": "в день события",
+	"tasks.limit":     "ℹ️ У события может быть не больше %d задач.",
+	"tasks.not_found": "❌ Задача не найдена.",
+
+	"search.prompt": "🔎 Что найти? Введите слова из названия, описания или тегов. " +
+		"Можно добавить фильтры: #семья или type:Годовщина (название с пробелами — в кавычках: type:\"День рождения\").",
+	"search.title": "🔎 Результаты поиска «%s»: %d",
+	"search.page":  " (стр. %d из %d)",
+	"search.empty": "🔎 По запросу «%s» ничего не найдено.",
+
+	"inline.open_bot":        "➕ Открыть бота",
+	"inline.date":            "📅 %s — %s\n",
+	"inline.new_title":       "➕ Напомнить: %s",
+	"inline.new_description": "%s — создать разовое напоминание и отправить его в чат",
+	"inline.new_message":     "⏰ Напоминание «%s» — %s",
+
+	"milestone.birthday":    "исполняется %[1]s",
+	"milestone.anniversary": "%s годовщина",
+	"milestone.round":       "🎊 юбилей: %s",
+
+	"mydata.caption": "📦 Ваши данные: профиль, настройки, события и история уведомлений.",
+
+	"deleteme.prompt": "⚠️ Вы уверены, что хотите удалить свой аккаунт? " +
+		"Все ваши события, настройки и история уведомлений будут удалены без возможности восстановления.\n\n" +
+		"Перед удалением вы можете выгрузить свои данные командой /mydata.",
+	"deleteme.confirm":   "🗑 Да, удалить всё",
+	"deleteme.cancelled": "👌 Удаление аккаунта отменено.",
+	"deleteme.done":      "✅ Ваш аккаунт и все события удалены. Чтобы начать заново, отправьте /start.",
+
+	"error.date_format":      "неверный формат даты, используйте ДД.ММ.ГГГГ или ДД.ММ",
+	"error.day":              "неверный день",
+	"error.month":            "неверный месяц",
+	"error.year":             "неверный год",
+	"error.date_invalid":     "несуществующая дата",
+	"error.time_format":      "неверный формат времени, используйте ЧЧ:ММ",
+	"error.hour":             "неверный час (0-23)",
+	"error.minute":           "неверная минута (0-59)",
+	"error.time_range":       "неверный формат интервала, используйте ЧЧ:ММ-ЧЧ:ММ",
+	"error.time_range_empty": "начало и конец интервала совпадают",
+
+	"error.retry_date":           "❌ %s. Пожалуйста, введите дату в формате ДД.ММ.ГГГГ или ДД.ММ:",
+	"error.retry_time":           "❌ %s. Пожалуйста, введите время в формате ЧЧ:ММ:",
+	"error.retry_time_range":     "❌ %s. Пожалуйста, введите интервал в формате ЧЧ:ММ-ЧЧ:ММ:",
+	"error.notify_days":          "❌ Пожалуйста, введите число от 1 до 30:",
+	"error.nag_hours":            "❌ Пожалуйста, введите число от 0 до 24:",
+	"error.type_name":            "❌ Название типа должно содержать от 1 до 32 символов.",
+	"error.type_emoji":           "❌ Отправьте один значок (эмодзи).",
+	"error.calendar_name":        "❌ Название календаря должно содержать от 1 до 64 символов.",
+	"error.greeting_text":        "❌ Текст поздравления должен содержать от 1 до %d символов.",
+	"error.gift_title":           "❌ Название подарка должно содержать от 1 до %d символов.",
+	"error.attachment":           "❌ Пришлите фото или файл.",
+	"error.location":             "❌ Пришлите геопозицию или место через 📎 → «Геопозиция».",
+	"error.leave_by":             "❌ Введите время встречи и время в пути в минутах от 1 до %d, например: 15:00 40.",
+	"error.task":                 "❌ Название задачи должно содержать от 1 до %d символов, а срок — от 0 до %d дней, например: 7: забронировать столик",
+	"error.event_type_not_found": "❌ Тип событий не найден.",
+	"error.create_event":         "❌ Произошла ошибка при создании события.",
+	"error.save_event":           "❌ Произошла ошибка при сохранении события.",
+	"error.save_changes":         "❌ Произошла ошибка при сохранении изменений.",
+	"error.delete_event":         "❌ Произошла ошибка при удалении события.",
+	"error.restore_event":        "❌ Не удалось восстановить событие: возможно, срок хранения в корзине истек.",
+	"error.revert_event":         "❌ Не удалось отменить изменения: версия события не найдена.",
+	"error.revision_not_found":   "❌ Версия события не найдена.",
+	"error.event_not_found":      "❌ Событие не найдено.",
+	"error.list_events":          "❌ Произошла ошибка при получении списка событий.",
+	"error.trash":                "❌ Произошла ошибка при открытии корзины.",
+	"error.history":              "❌ Произошла ошибка при получении истории изменений.",
+	"error.settings":             "❌ Произошла ошибка при получении настроек.",
+	"error.update_settings":      "❌ Произошла ошибка при обновлении настроек.",
+	"error.save_settings":        "❌ Произошла ошибка при сохранении настроек.",
+	"error.export":               "❌ Произошла ошибка при выгрузке данных.",
+	"error.account_not_found":    "❌ Аккаунт не найден.",
+	"error.register":             "❌ Не удалось зарегистрироваться. Попробуйте еще раз: /start",
+	"error.delete_account":       "❌ Произошла ошибка при удалении аккаунта.",
+	"error.stale_button":         "⚠️ Эта кнопка устарела. Откройте меню заново.",
+	"error.search":               "❌ Произошла ошибка при поиске событий.",
+	"error.unknown_command":      "Неизвестная команда. Используйте /help для списка доступных команд.",
+}
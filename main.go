@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"time"
@@ -9,13 +10,30 @@ import (
 	"github.com/awhatson15/reminder-bot/bot"
 	"github.com/awhatson15/reminder-bot/config"
 	"github.com/awhatson15/reminder-bot/db"
-	"github.com/awhatson15/reminder-bot/utils"
 )
 
 func main() {
+	restorePath := flag.String("restore", "", "восстановить базу данных из указанной резервной копии и завершить работу")
+	backupOnly := flag.Bool("backup", false, "создать резервную копию базы данных и завершить работу")
+	flag.Parse()
+
 	// Загружаем конфигурацию
 	cfg := config.LoadConfig()
 
+	retention := db.RetentionPolicy{
+		KeepDaily:   cfg.BackupKeepDaily,
+		KeepWeekly:  cfg.BackupKeepWeekly,
+		KeepMonthly: cfg.BackupKeepMonthly,
+	}
+
+	// Восстановление выполняется до открытия базы, пока бот не запущен
+	if *restorePath != "" {
+		if err := db.RestoreBackup(cfg.DatabasePath, *restorePath); err != nil {
+			log.Fatalf("Ошибка при восстановлении базы данных: %v", err)
+		}
+		return
+	}
+
 	// Выводим значение токена для отладки
 	if cfg.BotToken == "" {
 		log.Println("ВНИМАНИЕ: Токен бота не установлен (пустая строка)")
@@ -39,6 +57,15 @@ func main() {
 	}
 	defer database.Close()
 
+	if *backupOnly {
+		path, err := database.Backup(cfg.BackupDir, retention)
+		if err != nil {
+			log.Fatalf("Ошибка при создании резервной копии: %v", err)
+		}
+		log.Printf("Резервная копия создана: %s", path)
+		return
+	}
+
 	// Создаем схему базы данных
 	err = database.InitSchema()
 	if err != nil {
@@ -46,7 +73,7 @@ func main() {
 	}
 
 	// Создаем экземпляр бота
-	telegramBot, err := bot.NewBot(cfg.BotToken, database)
+	telegramBot, err := bot.NewBot(cfg, database)
 	if err != nil {
 		log.Fatalf("Ошибка при создании бота: %v", err)
 	}
@@ -56,46 +83,72 @@ func main() {
 	
 	// Запускаем проверку каждую минуту для уведомлений
 	_, err = scheduler.AddFunc("* * * * *", func() {
-		currentTime := time.Now().Format("15:04")
-		log.Printf("Проверка уведомлений для времени %s", currentTime)
-		
-		// Получаем всех пользователей с установленным временем уведомлений
-		users, err := database.GetUsersForNotification(currentTime)
+		now := time.Now()
+		log.Printf("Проверка уведомлений для времени %s", now.Format("15:04"))
+
+		// Время уведомления задается в типе события или в настройках пользователя
+		if err := telegramBot.CheckAndSendNotifications(now); err != nil {
+			log.Printf("Ошибка при проверке уведомлений: %v", err)
+		}
+	})
+	
+	if err != nil {
+		log.Printf("Ошибка при настройке планировщика: %v", err)
+	}
+
+	// Ежедневная очистка корзины от событий с истекшим сроком хранения
+	_, err = scheduler.AddFunc("30 3 * * *", func() {
+		purged, err := database.PurgeDeletedEvents(cfg.TrashRetentionDays)
 		if err != nil {
-			log.Printf("Ошибка при получении пользователей для уведомлений: %v", err)
+			log.Printf("Ошибка при очистке корзины: %v", err)
 			return
 		}
-		
-		for _, user := range users {
-			// Проверяем события для каждого пользователя и отправляем уведомления
-			events, err := database.GetEventsByUserID(user.ID)
-			if err != nil {
-				log.Printf("Ошибка при получении событий пользователя %d: %v", user.ID, err)
-				continue
-			}
-			
-			for _, event := range events {
-				// Проверяем, нужно ли отправлять уведомление сегодня
-				daysLeft, err := utils.DaysUntilEvent(event.EventDate)
-				if err != nil {
-					log.Printf("Ошибка при расчете дней до события %d: %v", event.ID, err)
-					continue
-				}
-				
-				// Отправляем уведомление, если осталось столько дней, сколько указано в настройках
-				// или если событие сегодня
-				if daysLeft == event.NotifyDays || daysLeft == 0 {
-					err = telegramBot.SendNotification(user, event, daysLeft)
-					if err != nil {
-						log.Printf("Ошибка при отправке уведомления для события %d: %v", event.ID, err)
-					}
-				}
-			}
+		if purged > 0 {
+			log.Printf("Из корзины окончательно удалено событий: %d", purged)
 		}
 	})
-	
 	if err != nil {
-		log.Printf("Ошибка при настройке планировщика: %v", err)
+		log.Printf("Ошибка при настройке очистки корзины: %v", err)
+	}
+
+	// Прошедшие разовые события убираются в корзину сразу после полуночи
+	_, err = scheduler.AddFunc("5 0 * * *", func() {
+		trashed, err := database.TrashPastOneOffEvents(time.Now().Format("2006-01-02"))
+		if err != nil {
+			log.Printf("Ошибка при удалении прошедших разовых событий: %v", err)
+			return
+		}
+		if trashed > 0 {
+			log.Printf("В корзину убрано прошедших разовых событий: %d", trashed)
+		}
+	})
+	if err != nil {
+		log.Printf("Ошибка при настройке удаления прошедших разовых событий: %v", err)
+	}
+
+	// Очистка данных inline-кнопок с истекшим сроком жизни
+	_, err = scheduler.AddFunc("45 3 * * *", func() {
+		if _, err := database.PurgeCallbackPayloads(cfg.CallbackTTLHours); err != nil {
+			log.Printf("Ошибка при очистке данных кнопок: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("Ошибка при настройке очистки данных кнопок: %v", err)
+	}
+
+	// Резервное копирование по расписанию
+	if cfg.BackupSchedule != "" {
+		_, err = scheduler.AddFunc(cfg.BackupSchedule, func() {
+			path, err := database.Backup(cfg.BackupDir, retention)
+			if err != nil {
+				log.Printf("Ошибка при создании резервной копии: %v", err)
+				return
+			}
+			log.Printf("Резервная копия создана: %s", path)
+		})
+		if err != nil {
+			log.Printf("Ошибка при настройке резервного копирования: %v", err)
+		}
 	}
 	
 	// Запускаем планировщик
@@ -6,34 +6,298 @@ import (
 
 // User представляет информацию о пользователе
 type User struct {
-	ID              int64
-	TelegramID      int64
-	Username        string
-	FirstName       string
-	LastName        string
-	NotificationTime string
-	CreatedAt       time.Time
+	ID               int64      `json:"id"`
+	TelegramID       int64      `json:"telegram_id"`
+	Username         string     `json:"username"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	NotificationTime string     `json:"notification_time"`
+	Language         string     `json:"language"`
+	DailyDigest      bool       `json:"daily_digest"`
+	WeeklyDigest     bool       `json:"weekly_digest"`
+	QuietStart       string     `json:"quiet_start,omitempty"`
+	QuietEnd         string     `json:"quiet_end,omitempty"`
+	DNDUntil         *time.Time `json:"dnd_until,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
 }
 
 // Event представляет информацию о событии
 type Event struct {
-	ID          int64
-	UserID      int64
-	Title       string
-	Type        string
-	EventDate   string
-	NotifyDays  int
-	Description string
-	CreatedAt   time.Time
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	Title         string     `json:"title"`
+	Type          string     `json:"type"`
+	TypeID        int64      `json:"type_id,omitempty"`
+	CalendarID    int64      `json:"calendar_id,omitempty"`
+	EventDate     string     `json:"event_date"`
+	NotifyDays    int        `json:"notify_days"`
+	Description   string     `json:"description"`
+	Tags          []string   `json:"tags,omitempty"`
+	Recurrence    string     `json:"recurrence"`
+	LeapPolicy    string     `json:"leap_policy"`
+	Priority      int        `json:"priority"`
+	NagHours      int        `json:"nag_hours"`
+	GreetingID    int64      `json:"greeting_id,omitempty"`
+	Latitude      float64    `json:"latitude,omitempty"`
+	Longitude     float64    `json:"longitude,omitempty"`
+	Venue         string     `json:"venue,omitempty"`
+	VenueAddress  string     `json:"venue_address,omitempty"`
+	MeetingTime   string     `json:"meeting_time,omitempty"`
+	TravelMinutes int        `json:"travel_minutes,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
 }
 
+// EventRecipient событие и пользователь, которому о нем нужно напомнить.
+// NotifyDays за сколько дней напомнить этому пользователю: подписчик может
+// задать свое значение вместо значения автора.
+type EventRecipient struct {
+	UserID     int64
+	Event      *Event
+	NotifyDays int
+}
+
+// Calendar общий календарь: его события видят и получают в напоминаниях
+// все участники. Role роль пользователя, для которого получен календарь.
+type Calendar struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CalendarMember участник общего календаря
+type CalendarMember struct {
+	CalendarID int64     `json:"calendar_id"`
+	UserID     int64     `json:"user_id"`
+	Role       string    `json:"role"`
+	FirstName  string    `json:"first_name"`
+	Username   string    `json:"username"`
+	JoinedAt   time.Time `json:"joined_at"`
+}
+
+// EventSubscriber пользователь, подписанный на чужое событие: он видит
+// событие и получает о нем напоминания по своим настройкам
+type EventSubscriber struct {
+	EventID      int64     `json:"event_id"`
+	UserID       int64     `json:"user_id"`
+	FirstName    string    `json:"first_name"`
+	Username     string    `json:"username"`
+	SubscribedAt time.Time `json:"subscribed_at"`
+}
+
+// Роли участников общего календаря
+const (
+	CalendarRoleOwner  = "owner"
+	CalendarRoleEditor = "editor"
+	CalendarRoleViewer = "viewer"
+)
+
+// Повторение событий
+const (
+	RecurrenceYearly  = "yearly"
+	RecurrenceMonthly = "monthly"
+	// RecurrenceOnce разовое событие: после даты оно убирается в корзину
+	RecurrenceOnce = "once"
+)
+
+// Когда отмечать событие 29 февраля в невисокосный год
+const (
+	LeapPolicyFeb28    = "feb28"
+	LeapPolicyMar1     = "mar1"
+	LeapPolicyLeapOnly = "leap_only"
+)
+
+// Приоритеты событий
+const (
+	PriorityNormal = 0
+	PriorityHigh   = 1
+)
+
+// Gift идея подарка к событию. Year год, в котором подарок подарен,
+// по нему ведется история, чтобы не дарить одно и то же дважды.
+type Gift struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"event_id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	Price     string    `json:"price,omitempty"`
+	Link      string    `json:"link,omitempty"`
+	Year      int       `json:"year,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Статусы идей подарков
+const (
+	GiftStatusIdea   = "idea"
+	GiftStatusBought = "bought"
+	GiftStatusGiven  = "given"
+)
+
+// Attachment фото или файл, прикрепленный к событию. FileID идентификатор
+// файла в Telegram, по нему вложение отправляется повторно.
+type Attachment struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"event_id"`
+	Kind      string    `json:"kind"`
+	FileID    string    `json:"file_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Виды вложений
+const (
+	AttachmentPhoto    = "photo"
+	AttachmentDocument = "document"
+)
+
+// Task задача подготовки к событию. DueDays за сколько дней до события
+// напомнить о задаче, TaskNoDue — без напоминания. DoneFor дата наступления
+// события (YYYY-MM-DD), к которому задача выполнена: к следующему
+// наступлению повторяющегося события задача снова становится открытой.
+type Task struct {
+	ID        int64     `json:"id"`
+	EventID   int64     `json:"event_id"`
+	Title     string    `json:"title"`
+	DueDays   int       `json:"due_days"`
+	DoneFor   string    `json:"done_for,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskNoDue задача без напоминания
+const TaskNoDue = -1
+
+// TaskSnooze напоминание о задачах подготовки, отложенное на конец тихих
+// часов или паузы. DueDays срок задач в днях до наступления Occurrence.
+type TaskSnooze struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	EventID    int64     `json:"event_id"`
+	Occurrence string    `json:"occurrence"`
+	DueDays    int       `json:"due_days"`
+	RemindAt   time.Time `json:"remind_at"`
+}
+
+// Nag повторное напоминание о важном событии, которое отправляется,
+// пока пользователь не отметит наступление события
+type Nag struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	EventID    int64     `json:"event_id"`
+	Occurrence string    `json:"occurrence"`
+	Attempt    int       `json:"attempt"`
+	NextAt     time.Time `json:"next_at"`
+}
+
+// EventRevision прежние значения полей события до изменения
+type EventRevision struct {
+	ID          int64     `json:"id"`
+	EventID     int64     `json:"event_id"`
+	Title       string    `json:"title"`
+	Type        string    `json:"type"`
+	TypeID      int64     `json:"type_id,omitempty"`
+	EventDate   string    `json:"event_date"`
+	NotifyDays  int       `json:"notify_days"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// EventType пользовательский тип (категория) событий.
+// Kind указывает встроенный тип, на котором основана категория; пустое
+// Name означает, что название берется из каталога i18n по Kind.
+type EventType struct {
+	ID               int64     `json:"id"`
+	UserID           int64     `json:"user_id"`
+	Kind             string    `json:"kind"`
+	Name             string    `json:"name"`
+	Emoji            string    `json:"emoji"`
+	NotifyDays       int       `json:"notify_days"`
+	NotificationTime string    `json:"notification_time"`
+	GreetingID       int64     `json:"greeting_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// GreetingTemplate шаблон поздравления пользователя. В тексте можно
+// использовать подстановки {name}, {age} и {years_together}.
+type GreetingTemplate struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notification запись истории отправленных уведомлений
+type Notification struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	EventID    *int64    `json:"event_id"`
+	EventTitle string    `json:"event_title"`
+	DaysLeft   int       `json:"days_left"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+// Snooze отложенное напоминание о наступлении события
+type Snooze struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	EventID    int64     `json:"event_id"`
+	Occurrence string    `json:"occurrence"`
+	RemindAt   time.Time `json:"remind_at"`
+}
+
+// UserSettings настройки пользователя
+type UserSettings struct {
+	NotificationTime string `json:"notification_time"`
+	Language         string `json:"language"`
+}
+
+// UserExport архив всех данных пользователя для выгрузки
+type UserExport struct {
+	ExportedAt        time.Time           `json:"exported_at"`
+	User              *User               `json:"user"`
+	Settings          UserSettings        `json:"settings"`
+	EventTypes        []*EventType        `json:"event_types"`
+	GreetingTemplates []*GreetingTemplate `json:"greeting_templates"`
+	Events            []*Event            `json:"events"`
+	Gifts             []*Gift             `json:"gifts"`
+	Attachments       []*Attachment       `json:"attachments"`
+	Tasks             []*Task             `json:"tasks"`
+	Notifications     []*Notification     `json:"notifications"`
+}
+
+// Встроенные типы событий. В БД хранится ключ, название берется из каталога i18n.
+const (
+	EventTypeBirthday    = "birthday"
+	EventTypeMeeting     = "meeting"
+	EventTypeHoliday     = "holiday"
+	EventTypeAnniversary = "anniversary"
+	EventTypeOther       = "other"
+)
+
 // EventTypes возможные типы событий
 var EventTypes = []string{
-	"День рождения",
-	"Встреча",
-	"Праздник",
-	"Годовщина",
-	"Другое",
+	EventTypeBirthday,
+	EventTypeMeeting,
+	EventTypeHoliday,
+	EventTypeAnniversary,
+	EventTypeOther,
+}
+
+// EventTypeEmoji значки встроенных типов событий
+var EventTypeEmoji = map[string]string{
+	EventTypeBirthday:    "🎂",
+	EventTypeMeeting:     "🤝",
+	EventTypeHoliday:     "🎉",
+	EventTypeAnniversary: "💍",
+	EventTypeOther:       "📌",
+}
+
+// LegacyEventTypes русские названия типов, которые хранились в БД до перехода на ключи
+var LegacyEventTypes = map[string]string{
+	"День рождения": EventTypeBirthday,
+	"Встреча":       EventTypeMeeting,
+	"Праздник":      EventTypeHoliday,
+	"Годовщина":     EventTypeAnniversary,
+	"Другое":        EventTypeOther,
 }
 
 // UserState хранит состояние диалога с пользователем
@@ -44,16 +308,26 @@ type UserState struct {
 
 // Возможные состояния диалога
 const (
-	StateDefault         = "default"
-	StateAddEvent        = "add_event"
-	StateAddEventTitle   = "add_event_title"
-	StateAddEventType    = "add_event_type"
-	StateAddEventDate    = "add_event_date"
-	StateAddEventNotify  = "add_event_notify"
-	StateAddEventDesc    = "add_event_desc"
-	StateEditEvent       = "edit_event"
-	StateEditEventField  = "edit_event_field"
-	StateEditEventValue  = "edit_event_value"
-	StateSettings        = "settings"
-	StateSetNotifyTime   = "set_notify_time"
+	StateDefault            = "default"
+	StateAddEvent           = "add_event"
+	StateAddEventTitle      = "add_event_title"
+	StateAddEventType       = "add_event_type"
+	StateAddEventDate       = "add_event_date"
+	StateAddEventNotify     = "add_event_notify"
+	StateAddEventDesc       = "add_event_desc"
+	StateEditEvent          = "edit_event"
+	StateEditEventField     = "edit_event_field"
+	StateEditEventValue     = "edit_event_value"
+	StateSettings           = "settings"
+	StateSetNotifyTime      = "set_notify_time"
+	StateNewEventType       = "new_event_type"
+	StateEditEventType      = "edit_event_type"
+	StateSearch             = "search"
+	StateSetQuietHours      = "set_quiet_hours"
+	StateNewCalendar        = "new_calendar"
+	StateNewGreeting        = "new_greeting"
+	StateNewGift            = "new_gift"
+	StateAddAttachment      = "add_attachment"
+	StateNewTask            = "new_task"
+	StateSubscriptionNotify = "subscription_notify"
 )
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// maxOccurrenceYears сколько лет вперед ищется наступление события.
+// 29 февраля с правилом «только в високосные годы» встречается раз в 4 года,
+// а на рубеже веков — раз в 8 лет.
+const maxOccurrenceYears = 8
+
+// OccurrenceRule правило повторения события
+type OccurrenceRule struct {
+	// Recurrence ежегодное, ежемесячное или разовое событие
+	Recurrence string
+	// LeapPolicy когда отмечать 29 февраля в невисокосный год
+	LeapPolicy string
+}
+
+// NextOccurrence возвращает ближайшую дату наступления события, начиная
+// с дня from включительно.
+//
+// Ежегодное событие 29 февраля в невисокосный год отмечается по LeapPolicy:
+// 28 февраля, 1 марта или пропускается. Ежемесячное событие в месяце,
+// где нет нужного числа, отмечается в последний день месяца. Разовое
+// событие наступает только в свою дату.
+func NextOccurrence(eventDate string, rule OccurrenceRule, from time.Time) (time.Time, error) {
+	// Преобразуем из YYYY-MM-DD
+	date, err := time.Parse("2006-01-02", eventDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	today := StartOfDay(from)
+
+	if rule.Recurrence == models.RecurrenceOnce {
+		if date.Year() == UnknownYear || date.Before(today) {
+			return time.Time{}, newValidationError("date_invalid", "событие больше не наступит")
+		}
+		return date, nil
+	}
+
+	if rule.Recurrence == models.RecurrenceMonthly {
+		// В течение 12 месяцев каждое число встречается хотя бы в последний день месяца
+		for i := 0; i <= 12; i++ {
+			month := time.Date(today.Year(), today.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+			next := monthDay(month.Year(), month.Month(), date.Day())
+			if !next.Before(today) {
+				return next, nil
+			}
+		}
+	}
+
+	for year := today.Year(); year <= today.Year()+maxOccurrenceYears; year++ {
+		next, ok := anniversary(date, year, rule.LeapPolicy)
+		if ok && !next.Before(today) {
+			return next, nil
+		}
+	}
+	return time.Time{}, newValidationError("date_invalid", "событие больше не наступит")
+}
+
+// OnceDate возвращает дату разового события (YYYY-MM-DD): саму дату, если
+// она указана с годом и еще не прошла, иначе ближайшую такую дату начиная
+// с дня from. 29 февраля переносится на ближайший високосный год.
+func OnceDate(eventDate string, from time.Time) (string, error) {
+	next, err := NextOccurrence(eventDate, OccurrenceRule{Recurrence: models.RecurrenceOnce}, from)
+	if err != nil {
+		next, err = NextOccurrence(eventDate, OccurrenceRule{
+			Recurrence: models.RecurrenceYearly,
+			LeapPolicy: models.LeapPolicyLeapOnly,
+		}, from)
+		if err != nil {
+			return "", err
+		}
+	}
+	return next.Format("2006-01-02"), nil
+}
+
+// anniversary возвращает дату ежегодного события в указанном году.
+// Второе значение равно false, если в этом году событие не наступает.
+func anniversary(date time.Time, year int, leapPolicy string) (time.Time, bool) {
+	if date.Month() == time.February && date.Day() == 29 && !IsLeapYear(year) {
+		switch leapPolicy {
+		case models.LeapPolicyMar1:
+			return time.Date(year, time.March, 1, 0, 0, 0, 0, time.UTC), true
+		case models.LeapPolicyLeapOnly:
+			return time.Time{}, false
+		default:
+			return time.Date(year, time.February, 28, 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	return time.Date(year, date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), true
+}
+
+// monthDay возвращает день месяца, ограниченный последним днем месяца:
+// 31-е число в апреле становится 30 апреля, в феврале — 28 или 29 февраля
+func monthDay(year int, month time.Month, day int) time.Time {
+	if last := DaysInMonth(year, month); day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// DaysInMonth возвращает число дней в месяце
+func DaysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// IsLeapYear проверяет, является ли год високосным
+func IsLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// DaysUntilEvent возвращает количество дней до события; в день события — 0
+func DaysUntilEvent(eventDate string, rule OccurrenceRule) (int, error) {
+	now := time.Now()
+	next, err := NextOccurrence(eventDate, rule, now)
+	if err != nil {
+		return 0, err
+	}
+
+	// Вычисляем разницу в днях
+	days := int(next.Sub(StartOfDay(now)).Hours() / 24)
+
+	return days, nil
+}
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/awhatson15/reminder-bot/models"
+)
+
+// date возвращает полночь указанного дня в UTC
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNextOccurrenceLeapDay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		from   time.Time
+		want   time.Time
+	}{
+		// Невисокосный год
+		{"feb28 с 28 февраля", models.LeapPolicyFeb28, date(2025, time.February, 28), date(2025, time.February, 28)},
+		{"feb28 с 1 марта", models.LeapPolicyFeb28, date(2025, time.March, 1), date(2026, time.February, 28)},
+		{"mar1 с 28 февраля", models.LeapPolicyMar1, date(2025, time.February, 28), date(2025, time.March, 1)},
+		{"mar1 с 1 марта", models.LeapPolicyMar1, date(2025, time.March, 1), date(2025, time.March, 1)},
+		{"leap_only с 28 февраля", models.LeapPolicyLeapOnly, date(2025, time.February, 28), date(2028, time.February, 29)},
+		{"leap_only с 1 марта", models.LeapPolicyLeapOnly, date(2025, time.March, 1), date(2028, time.February, 29)},
+
+		// Високосный год
+		{"feb28 с 28 февраля високосного", models.LeapPolicyFeb28, date(2028, time.February, 28), date(2028, time.February, 29)},
+		{"feb28 с 29 февраля", models.LeapPolicyFeb28, date(2028, time.February, 29), date(2028, time.February, 29)},
+		{"feb28 с 1 марта високосного", models.LeapPolicyFeb28, date(2028, time.March, 1), date(2029, time.February, 28)},
+		{"mar1 с 28 февраля високосного", models.LeapPolicyMar1, date(2028, time.February, 28), date(2028, time.February, 29)},
+		{"mar1 с 29 февраля", models.LeapPolicyMar1, date(2028, time.February, 29), date(2028, time.February, 29)},
+		{"mar1 с 1 марта високосного", models.LeapPolicyMar1, date(2028, time.March, 1), date(2029, time.March, 1)},
+		{"leap_only с 28 февраля високосного", models.LeapPolicyLeapOnly, date(2028, time.February, 28), date(2028, time.February, 29)},
+		{"leap_only с 29 февраля", models.LeapPolicyLeapOnly, date(2028, time.February, 29), date(2028, time.February, 29)},
+		{"leap_only с 1 марта високосного", models.LeapPolicyLeapOnly, date(2028, time.March, 1), date(2032, time.February, 29)},
+
+		// 2100 год не високосный: следующее 29 февраля только в 2104
+		{"leap_only через 2100 год", models.LeapPolicyLeapOnly, date(2097, time.January, 1), date(2104, time.February, 29)},
+		{"feb28 в 2100 году", models.LeapPolicyFeb28, date(2100, time.January, 1), date(2100, time.February, 28)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := OccurrenceRule{Recurrence: models.RecurrenceYearly, LeapPolicy: tt.policy}
+			got, err := NextOccurrence("2000-02-29", rule, tt.from)
+			if err != nil {
+				t.Fatalf("NextOccurrence: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("получено %s, ожидалось %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestNextOccurrenceMonthly(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		want time.Time
+	}{
+		{"февраль", date(2025, time.February, 1), date(2025, time.February, 28)},
+		{"февраль високосного года", date(2028, time.February, 1), date(2028, time.February, 29)},
+		{"апрель", date(2025, time.April, 1), date(2025, time.April, 30)},
+		{"после 30 апреля", date(2025, time.May, 1), date(2025, time.May, 31)},
+		{"декабрь", date(2025, time.December, 31), date(2025, time.December, 31)},
+		{"из декабря в январь", date(2026, time.January, 1), date(2026, time.January, 31)},
+	}
+
+	rule := OccurrenceRule{Recurrence: models.RecurrenceMonthly}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextOccurrence("2024-01-31", rule, tt.from)
+			if err != nil {
+				t.Fatalf("NextOccurrence: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("получено %s, ожидалось %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestNextOccurrenceUnknownYear(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		from   time.Time
+		want   time.Time
+	}{
+		{"feb28", models.LeapPolicyFeb28, date(2025, time.January, 10), date(2025, time.February, 28)},
+		{"mar1", models.LeapPolicyMar1, date(2025, time.January, 10), date(2025, time.March, 1)},
+		{"leap_only", models.LeapPolicyLeapOnly, date(2025, time.January, 10), date(2028, time.February, 29)},
+		{"високосный год", models.LeapPolicyFeb28, date(2028, time.January, 10), date(2028, time.February, 29)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := OccurrenceRule{Recurrence: models.RecurrenceYearly, LeapPolicy: tt.policy}
+			got, err := NextOccurrence("0000-02-29", rule, tt.from)
+			if err != nil {
+				t.Fatalf("NextOccurrence: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("получено %s, ожидалось %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestAnniversary(t *testing.T) {
+	leapDay := date(2000, time.February, 29)
+	tests := []struct {
+		name   string
+		policy string
+		year   int
+		want   time.Time
+		ok     bool
+	}{
+		{"високосный год", models.LeapPolicyLeapOnly, 2024, date(2024, time.February, 29), true},
+		{"feb28", models.LeapPolicyFeb28, 2025, date(2025, time.February, 28), true},
+		{"mar1", models.LeapPolicyMar1, 2025, date(2025, time.March, 1), true},
+		{"leap_only", models.LeapPolicyLeapOnly, 2025, time.Time{}, false},
+		{"leap_only в 2100", models.LeapPolicyLeapOnly, 2100, time.Time{}, false},
+		{"правило по умолчанию", "", 2025, date(2025, time.February, 28), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := anniversary(leapDay, tt.year, tt.policy)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("получено %s, %v; ожидалось %s, %v",
+					got.Format("2006-01-02"), ok, tt.want.Format("2006-01-02"), tt.ok)
+			}
+		})
+	}
+}
+
+func TestMonthDay(t *testing.T) {
+	tests := []struct {
+		year  int
+		month time.Month
+		day   int
+		want  time.Time
+	}{
+		{2025, time.January, 31, date(2025, time.January, 31)},
+		{2025, time.February, 31, date(2025, time.February, 28)},
+		{2024, time.February, 31, date(2024, time.February, 29)},
+		{2100, time.February, 29, date(2100, time.February, 28)},
+		{2025, time.April, 31, date(2025, time.April, 30)},
+		{2025, time.December, 31, date(2025, time.December, 31)},
+	}
+
+	for _, tt := range tests {
+		if got := monthDay(tt.year, tt.month, tt.day); !got.Equal(tt.want) {
+			t.Errorf("monthDay(%d, %s, %d) = %s, ожидалось %s",
+				tt.year, tt.month, tt.day, got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestNextOccurrenceOnce(t *testing.T) {
+	rule := OccurrenceRule{Recurrence: models.RecurrenceOnce}
+	from := date(2026, time.May, 12)
+
+	if got, err := NextOccurrence("2026-05-12", rule, from); err != nil || !got.Equal(from) {
+		t.Errorf("в день события получено %s, %v", got.Format("2006-01-02"), err)
+	}
+	for _, eventDate := range []string{"2026-05-11", "2025-12-25", "0000-12-25"} {
+		if _, err := NextOccurrence(eventDate, rule, from); err == nil {
+			t.Errorf("%s: ожидалась ошибка", eventDate)
+		}
+	}
+}
+
+func TestOnceDate(t *testing.T) {
+	from := date(2026, time.May, 12)
+	tests := []struct {
+		eventDate string
+		want      string
+	}{
+		{"0000-12-25", "2026-12-25"},
+		{"0000-05-12", "2026-05-12"},
+		{"0000-01-10", "2027-01-10"},
+		{"0000-02-29", "2028-02-29"},
+		{"2027-03-01", "2027-03-01"},
+		{"2020-12-25", "2026-12-25"},
+	}
+
+	for _, tt := range tests {
+		got, err := OnceDate(tt.eventDate, from)
+		if err != nil || got != tt.want {
+			t.Errorf("OnceDate(%s) = %s, %v; ожидалось %s", tt.eventDate, got, err, tt.want)
+		}
+	}
+}
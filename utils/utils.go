@@ -7,33 +7,63 @@ import (
 	"time"
 )
 
-// FormatDate форматирует дату события
+// ValidationError ошибка проверки ввода.
+// Key используется для перевода сообщения на язык пользователя.
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// newValidationError создает ошибку проверки ввода
+func newValidationError(key, message string) error {
+	return &ValidationError{Key: key, Message: message}
+}
+
+// UnknownYear год, который хранится в дате события, если год неизвестен
+const UnknownYear = 0
+
+// FormatDate форматирует дату события. Год можно не указывать (ДД.ММ),
+// тогда вместо него сохраняется UnknownYear.
 func FormatDate(dateStr string) (string, error) {
-	// Ожидаем дату в формате ДД.ММ.ГГГГ
-	parts := strings.Split(dateStr, ".")
+	// Ожидаем дату в формате ДД.ММ.ГГГГ или ДД.ММ
+	parts := strings.Split(strings.TrimSpace(dateStr), ".")
+	if len(parts) == 2 {
+		parts = append(parts, "")
+	}
 	if len(parts) != 3 {
-		return "", fmt.Errorf("неверный формат даты, используйте ДД.ММ.ГГГГ")
+		return "", newValidationError("date_format", "неверный формат даты, используйте ДД.ММ.ГГГГ или ДД.ММ")
 	}
 
 	day, err := strconv.Atoi(parts[0])
 	if err != nil || day < 1 || day > 31 {
-		return "", fmt.Errorf("неверный день")
+		return "", newValidationError("day", "неверный день")
 	}
 
 	month, err := strconv.Atoi(parts[1])
 	if err != nil || month < 1 || month > 12 {
-		return "", fmt.Errorf("неверный месяц")
+		return "", newValidationError("month", "неверный месяц")
 	}
 
-	year, err := strconv.Atoi(parts[2])
-	if err != nil || year < 1900 || year > 2100 {
-		return "", fmt.Errorf("неверный год")
+	year := UnknownYear
+	if parts[2] != "" {
+		year, err = strconv.Atoi(parts[2])
+		if err != nil || year < 1900 || year > 2100 {
+			return "", newValidationError("year", "неверный год")
+		}
 	}
 
-	// Проверка на валидность даты
-	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
-	if date.Day() != day || date.Month() != time.Month(month) || date.Year() != year {
-		return "", fmt.Errorf("несуществующая дата")
+	// Проверка на валидность даты; без года допускается 29 февраля
+	checkYear := year
+	if checkYear == UnknownYear {
+		checkYear = 2000
+	}
+	date := time.Date(checkYear, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || date.Month() != time.Month(month) || date.Year() != checkYear {
+		return "", newValidationError("date_invalid", "несуществующая дата")
 	}
 
 	// Возвращаем дату в формате YYYY-MM-DD для хранения в БД
@@ -60,45 +90,42 @@ func ValidateTime(timeStr string) (string, error) {
 	// Ожидаем время в формате ЧЧ:ММ
 	parts := strings.Split(timeStr, ":")
 	if len(parts) != 2 {
-		return "", fmt.Errorf("неверный формат времени, используйте ЧЧ:ММ")
+		return "", newValidationError("time_format", "неверный формат времени, используйте ЧЧ:ММ")
 	}
 
 	hour, err := strconv.Atoi(parts[0])
 	if err != nil || hour < 0 || hour > 23 {
-		return "", fmt.Errorf("неверный час (0-23)")
+		return "", newValidationError("hour", "неверный час (0-23)")
 	}
 
 	minute, err := strconv.Atoi(parts[1])
 	if err != nil || minute < 0 || minute > 59 {
-		return "", fmt.Errorf("неверная минута (0-59)")
+		return "", newValidationError("minute", "неверная минута (0-59)")
 	}
 
 	// Форматируем время в стандартный формат ЧЧ:ММ
 	return fmt.Sprintf("%02d:%02d", hour, minute), nil
 }
 
-// DaysUntilEvent возвращает количество дней до события
-func DaysUntilEvent(eventDate string) (int, error) {
-	// Преобразуем из YYYY-MM-DD
+// YearsAt возвращает, сколько лет исполняется событию в день occurrence.
+// Если год события неизвестен, второе значение равно false.
+func YearsAt(eventDate string, occurrence time.Time) (int, bool) {
 	date, err := time.Parse("2006-01-02", eventDate)
-	if err != nil {
-		return 0, err
+	if err != nil || date.Year() == UnknownYear {
+		return 0, false
 	}
 
-	now := time.Now()
-	
-	// Устанавливаем тот же год для ежегодных событий
-	eventThisYear := time.Date(now.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-	
-	// Если дата уже прошла в этом году, берем дату на следующий год
-	if eventThisYear.Before(now) {
-		eventThisYear = time.Date(now.Year()+1, date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-	}
-	
-	// Вычисляем разницу в днях
-	days := int(eventThisYear.Sub(now).Hours() / 24)
-	
-	return days, nil
+	years := occurrence.Year() - date.Year()
+	if years <= 0 {
+		return 0, false
+	}
+	return years, true
+}
+
+// StartOfDay возвращает полночь календарного дня t в виде даты UTC,
+// чтобы разница между датами считалась в целых днях
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
 
 // GetCurrentTimeForCron возвращает текущее время в формате для cron-задач
@@ -106,3 +133,62 @@ func GetCurrentTimeForCron() string {
 	now := time.Now()
 	return fmt.Sprintf("%d %d * * *", now.Minute(), now.Hour())
 }
+
+// ParseTimeRange разбирает интервал времени вида ЧЧ:ММ-ЧЧ:ММ. Интервал
+// может переходить через полночь, например 23:00-08:00.
+func ParseTimeRange(rangeStr string) (string, string, error) {
+	parts := strings.FieldsFunc(rangeStr, func(r rune) bool {
+		return r == '-' || r == '–' || r == '—' || r == ' '
+	})
+	if len(parts) != 2 {
+		return "", "", newValidationError("time_range", "неверный формат интервала, используйте ЧЧ:ММ-ЧЧ:ММ")
+	}
+
+	start, err := ValidateTime(parts[0])
+	if err != nil {
+		return "", "", err
+	}
+	end, err := ValidateTime(parts[1])
+	if err != nil {
+		return "", "", err
+	}
+	if start == end {
+		return "", "", newValidationError("time_range_empty", "начало и конец интервала совпадают")
+	}
+
+	return start, end, nil
+}
+
+// TimeRangeEnd проверяет, попадает ли t в ежедневный интервал start-end
+// (ЧЧ:ММ), и возвращает момент его окончания
+func TimeRangeEnd(start, end string, t time.Time) (time.Time, bool) {
+	startAt, err := time.Parse("15:04", start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	endAt, err := time.Parse("15:04", end)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	minutes := t.Hour()*60 + t.Minute()
+	from := startAt.Hour()*60 + startAt.Minute()
+	to := endAt.Hour()*60 + endAt.Minute()
+
+	var inside bool
+	if from < to {
+		inside = minutes >= from && minutes < to
+	} else {
+		// Интервал переходит через полночь
+		inside = minutes >= from || minutes < to
+	}
+	if !inside {
+		return time.Time{}, false
+	}
+
+	until := time.Date(t.Year(), t.Month(), t.Day(), endAt.Hour(), endAt.Minute(), 0, 0, t.Location())
+	if minutes >= to {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}